@@ -6,21 +6,23 @@ import (
 	"github.com/consensys/gnark/frontend"
 	"github.com/elliottech/gnark-plonky2-verifier/fri"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/hasher"
 	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
 	"github.com/elliottech/gnark-plonky2-verifier/types"
 	"github.com/elliottech/gnark-plonky2-verifier/variables"
 )
 
 type Chip struct {
-	api               frontend.API `gnark:"-"`
-	poseidonChip      *poseidon.GoldilocksChip
-	poseidonBN254Chip *poseidon.BN254Chip
-	spongeState       poseidon.GoldilocksState
-	inputBuffer       []gl.Variable
-	outputBuffer      []gl.Variable
+	api          frontend.API `gnark:"-"`
+	poseidonChip *poseidon.GoldilocksChip
+	hasher       hasher.Hasher
+	spongeState  poseidon.GoldilocksState
+	inputBuffer  []gl.Variable
+	outputBuffer []gl.Variable
 }
 
-func NewChip(api frontend.API) *Chip {
+// Creates a challenger whose sponge and observed Merkle digests follow the given hasher.
+func NewChip(api frontend.API, hasher hasher.Hasher) *Chip {
 	var spongeState poseidon.GoldilocksState
 	var inputBuffer []gl.Variable
 	var outputBuffer []gl.Variable
@@ -28,14 +30,13 @@ func NewChip(api frontend.API) *Chip {
 		spongeState[i] = gl.Zero()
 	}
 	poseidonChip := poseidon.NewGoldilocksChip(api)
-	poseidonBN254Chip := poseidon.NewBN254Chip(api)
 	return &Chip{
-		api:               api,
-		poseidonChip:      poseidonChip,
-		poseidonBN254Chip: poseidonBN254Chip,
-		spongeState:       spongeState,
-		inputBuffer:       inputBuffer,
-		outputBuffer:      outputBuffer,
+		api:          api,
+		poseidonChip: poseidonChip,
+		hasher:       hasher,
+		spongeState:  spongeState,
+		inputBuffer:  inputBuffer,
+		outputBuffer: outputBuffer,
 	}
 }
 
@@ -59,14 +60,24 @@ func (c *Chip) ObserveHash(hash poseidon.GoldilocksHashOut) {
 	c.ObserveElements(elements)
 }
 
-func (c *Chip) ObserveBN254Hash(hash poseidon.BN254HashOut) {
-	elements := c.poseidonBN254Chip.ToVec(hash)
+// Observes a Merkle digest (or the circuit digest) produced by the challenger's hasher.
+func (c *Chip) ObserveDigest(hash variables.HashOut) {
+	elements := c.hasher.ToVec(hash)
 	c.ObserveElements(elements)
 }
 
-func (c *Chip) ObserveCap(cap []poseidon.BN254HashOut) {
+func (c *Chip) ObserveCap(cap variables.FriMerkleCap) {
 	for i := 0; i < len(cap); i++ {
-		c.ObserveBN254Hash(cap[i])
+		c.ObserveDigest(cap[i])
+	}
+}
+
+// Observes the FRI reduction strategy the way plonky2 does: a tag identifying the strategy (0 for
+// Fixed, 1 for ConstantArityBits and 2 for MinSize) followed by the strategy's parameters.
+func (c *Chip) ObserveFriReductionStrategy(strategy types.FriReductionStrategy) {
+	c.ObserveElement(gl.NewVariable(uint64(strategy.Kind)))
+	for _, parameter := range strategy.Parameters() {
+		c.ObserveElement(gl.NewVariable(parameter))
 	}
 }
 
@@ -143,6 +154,15 @@ func (c *Chip) GetFriChallenges(
 	}
 }
 
+// Absorbs the pending observations and discards the unused challenges, like plonky2's
+// Challenger::compact.
+func (c *Chip) Compact() {
+	if len(c.inputBuffer) != 0 {
+		c.duplexing()
+	}
+	c.outputBuffer = make([]gl.Variable, 0)
+}
+
 func (c *Chip) duplexing() {
 	if len(c.inputBuffer) > poseidon.SPONGE_RATE {
 		fmt.Println(len(c.inputBuffer))
@@ -156,7 +176,7 @@ func (c *Chip) duplexing() {
 	}
 	// Clear the input buffer
 	c.inputBuffer = make([]gl.Variable, 0)
-	c.spongeState = c.poseidonChip.Poseidon(c.spongeState)
+	c.spongeState = c.hasher.Permute(c.spongeState)
 
 	// Clear the output buffer
 	c.outputBuffer = make([]gl.Variable, 0)
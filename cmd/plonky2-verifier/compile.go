@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+	"github.com/elliottech/gnark-plonky2-verifier/verifier"
+)
+
+// Returns verifier.UniversalVerifierCircuit if universal, and otherwise verifier.VerifierCircuit
+// bound to the verifier only circuit data at verifierOnlyPath, which is then required.
+func newVerifierCircuit(commonCircuitData types.CommonCircuitData, verifierOnlyPath string, universal bool) (frontend.Circuit, error) {
+	if universal {
+		log.Println("warning: the universal verifier circuit accepts proofs of any plonky2 circuit with this common circuit data, its verifier must check the circuit digest public inputs")
+		return verifier.NewUniversalVerifierCircuit(commonCircuitData), nil
+	}
+	if verifierOnlyPath == "" {
+		return nil, errors.New("-verifier-only is required, the verifier circuit only accepts proofs of the plonky2 circuit of its verifier only circuit data unless it's -universal")
+	}
+	verifierOnlyRaw, err := readVerifierOnlyCircuitData(verifierOnlyPath, &commonCircuitData)
+	if err != nil {
+		return nil, err
+	}
+	return verifier.NewVerifierCircuit(commonCircuitData, variables.DeserializeVerifierOnlyCircuitData(verifierOnlyRaw)), nil
+}
+
+func compileVerifierCircuit(id backend.ID, circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	if id == backend.PLONK {
+		return frontend.Compile(curve.ScalarField(), scs.NewBuilder, circuit)
+	}
+	return frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, circuit)
+}
+
+func compileCmd(args []string) error {
+	flags := flag.NewFlagSet("compile", flag.ExitOnError)
+	commonPath := flags.String("common", "common_circuit_data.json", "plonky2 common circuit data, as JSON or plonky2 bytes")
+	hasher := hasherFlag(flags)
+	legacyTranscript := legacyTranscriptFlag(flags)
+	system := flags.String("system", "groth16", "proving system, groth16 or plonk")
+	csPath := flags.String("out", "circuit.cs", "output constraint system")
+	verifierOnlyPath := flags.String("verifier-only", "", "plonky2 verifier only circuit data, as JSON or plonky2 bytes, of the only plonky2 circuit whose proofs the verifier circuit accepts; required unless -universal")
+	universal := flags.Bool("universal", false, "compile the universal verifier circuit, which accepts proofs of any plonky2 circuit with the common circuit data and exposes their circuit digest as public inputs")
+	flags.Parse(args)
+
+	id, err := parseSystem(*system)
+	if err != nil {
+		return err
+	}
+
+	commonCircuitData, err := readCommonCircuitData(*commonPath, *hasher)
+	if err != nil {
+		return err
+	}
+	commonCircuitData.LegacyTranscript = *legacyTranscript
+
+	circuit, err := newVerifierCircuit(commonCircuitData, *verifierOnlyPath, *universal)
+	if err != nil {
+		return err
+	}
+	ccs, err := compileVerifierCircuit(id, circuit)
+	if err != nil {
+		return err
+	}
+	log.Printf("compiled the verifier circuit: %d constraints, %d public inputs", ccs.GetNbConstraints(), ccs.GetNbPublicVariables())
+
+	return writeFile(*csPath, ccs)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// The curve the verifier circuit is proven over.
+const curve = ecc.BN254
+
+func parseSystem(system string) (backend.ID, error) {
+	id := backend.IDFromString(system)
+	if id == backend.UNKNOWN {
+		return id, fmt.Errorf("unknown proving system %q, expected groth16 or plonk", system)
+	}
+	return id, nil
+}
+
+func newCS(id backend.ID) constraint.ConstraintSystem {
+	if id == backend.GROTH16 {
+		return groth16.NewCS(curve)
+	}
+	return plonk.NewCS(curve)
+}
+
+// The hashers of plonky2's GenericConfigs, by their -hasher names.
+var hashers = map[string]types.HasherType{
+	"poseidon-bn254":      types.PoseidonBN254Hash,
+	"poseidon-goldilocks": types.PoseidonGoldilocksHash,
+	"keccak":              types.KeccakHash,
+}
+
+// Adds the -hasher flag of the commands reading plonky2 data, whose serializations don't say which
+// hasher the plonky2 circuit's config uses.
+func hasherFlag(flags *flag.FlagSet) *string {
+	return flags.String("hasher", "poseidon-bn254", "hasher of the plonky2 circuit's config, poseidon-bn254, poseidon-goldilocks or keccak")
+}
+
+// Adds the -legacy-transcript flag of the commands running the plonky2 verifier, which the
+// serializations don't say either.
+func legacyTranscriptFlag(flags *flag.FlagSet) *bool {
+	return flags.Bool("legacy-transcript", false, "the plonky2 proof's transcript doesn't observe the FRI config and parameters, like plonky2's before they were added to it, e.g. for the proofs of this repo's testdata")
+}
+
+func parseHasher(hasher string) (types.HasherType, error) {
+	hasherType, ok := hashers[hasher]
+	if !ok {
+		return hasherType, fmt.Errorf("unknown hasher %q, expected poseidon-bn254, poseidon-goldilocks or keccak", hasher)
+	}
+	return hasherType, nil
+}
+
+// Checks that a circuit digest is serialized like the digests of hasher, which catches a -hasher
+// that isn't the plonky2 circuit's before it fails deep in the verifier circuit.
+func checkCircuitDigest(digest types.HashOutRaw, hasher types.HasherType) error {
+	if uint64(len(digest.Elements)) != hasher.HashOutLen() {
+		return fmt.Errorf(
+			"the circuit digest has %d elements but the digests of the hasher have %d, check -hasher",
+			len(digest.Elements), hasher.HashOutLen(),
+		)
+	}
+	return nil
+}
+
+// Reads common circuit data serialized as JSON, or as plonky2's binary CommonCircuitData::to_bytes
+// with the DefaultGateSerializer for any other extension, of a plonky2 circuit using hasher.
+func readCommonCircuitData(path string, hasher string) (types.CommonCircuitData, error) {
+	hasherType, err := parseHasher(hasher)
+	if err != nil {
+		return types.CommonCircuitData{}, err
+	}
+
+	var commonCircuitData types.CommonCircuitData
+	if filepath.Ext(path) == ".json" {
+		commonCircuitData = types.ReadCommonCircuitData(path)
+	} else if commonCircuitData, err = types.ReadCommonCircuitDataBinary(path, types.DefaultGateSerializer()); err != nil {
+		return types.CommonCircuitData{}, err
+	}
+	commonCircuitData.Hasher = hasherType
+	return commonCircuitData, nil
+}
+
+// Reads a JSON proof, or the binary serialization of plonky2's ProofWithPublicInputs::to_bytes for
+// any other extension, whose shapes are given by the common circuit data at commonPath. A
+// compressed proof, serialized by CompressedProofWithPublicInputs::to_bytes, is returned without
+// its query rounds, which only native.DecompressProofWithPublicInputs restores.
+func readProofWithPublicInputs(path string, commonPath string, hasher string, compressed bool) (types.ProofWithPublicInputsRaw, error) {
+	if filepath.Ext(path) == ".json" && !compressed {
+		return types.ReadProofWithPublicInputs(path), nil
+	}
+
+	commonCircuitData, err := readCommonCircuitData(commonPath, hasher)
+	if err != nil {
+		return types.ProofWithPublicInputsRaw{}, err
+	}
+	if compressed {
+		compressedProofWithPis, err := types.ReadCompressedProofWithPublicInputsBinary(path, &commonCircuitData)
+		return compressedProofWithPis.ProofWithPublicInputsRaw, err
+	}
+	return types.ReadProofWithPublicInputsBinary(path, &commonCircuitData)
+}
+
+// Reads JSON verifier only circuit data, or the binary serialization of plonky2's
+// VerifierOnlyCircuitData::to_bytes for any other extension.
+func readVerifierOnlyCircuitData(path string, commonCircuitData *types.CommonCircuitData) (types.VerifierOnlyCircuitDataRaw, error) {
+	var verifierOnlyRaw types.VerifierOnlyCircuitDataRaw
+	if filepath.Ext(path) == ".json" {
+		verifierOnlyRaw = types.ReadVerifierOnlyCircuitData(path)
+	} else {
+		var err error
+		if verifierOnlyRaw, err = types.ReadVerifierOnlyCircuitDataBinary(path, commonCircuitData); err != nil {
+			return types.VerifierOnlyCircuitDataRaw{}, err
+		}
+	}
+	return verifierOnlyRaw, checkCircuitDigest(verifierOnlyRaw.CircuitDigest, commonCircuitData.Hasher)
+}
+
+func writeFile(path string, object io.WriterTo) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if _, err := object.WriteTo(writer); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+func readFile(path string, object io.ReaderFrom) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := object.ReadFrom(bufio.NewReader(file)); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+func readCS(id backend.ID, path string) (constraint.ConstraintSystem, error) {
+	ccs := newCS(id)
+	if err := readFile(path, ccs); err != nil {
+		return nil, err
+	}
+	return ccs, nil
+}
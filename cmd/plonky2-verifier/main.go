@@ -0,0 +1,73 @@
+// Command plonky2-verifier compiles the plonky2 verifier circuit, runs its setup, proves and
+// verifies plonky2 proofs with Groth16 or PLONK over BN254, and exports the verifier to Solidity.
+//
+// Usage:
+//
+//	plonky2-verifier compile -common common_circuit_data.json -verifier-only verifier_only_circuit_data.json \
+//		-system groth16 -out circuit.cs
+//	plonky2-verifier setup -system groth16 -cs circuit.cs -pk circuit.pk -vk circuit.vk
+//	plonky2-verifier prove -system groth16 -cs circuit.cs -pk circuit.pk -common common_circuit_data.json \
+//		-proof-with-pis proof_with_public_inputs.json -verifier-only verifier_only_circuit_data.json -out proof.bin
+//	plonky2-verifier verify -system groth16 -vk circuit.vk -proof proof.bin -proof-with-pis proof_with_public_inputs.json
+//	plonky2-verifier export-solidity -system groth16 -vk circuit.vk -out Verifier.sol
+//	plonky2-verifier calldata -system groth16 -proof proof.bin -proof-with-pis proof_with_public_inputs.json
+//	plonky2-verifier profile -common common_circuit_data.json -verifier-only verifier_only_circuit_data.json \
+//		-system groth16 -pprof gnark.pprof
+//
+// The verifier circuit only accepts proofs of the plonky2 circuit whose verifier only circuit data
+// it's compiled with. Compiled with -universal, it accepts proofs of any plonky2 circuit with the
+// same common circuit data and exposes their circuit digest as public inputs, which whoever
+// verifies its proofs must check.
+//
+// Plonky2 doesn't serialize the hasher of the circuit's config, which -hasher gives (poseidon-bn254
+// by default), checked against the format of the circuit digest, nor whether the proof's transcript
+// predates the observation of the FRI config and parameters, which -legacy-transcript gives to
+// compile, prove and profile.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+var commands = []struct {
+	name        string
+	description string
+	run         func(args []string) error
+}{
+	{"compile", "compile the verifier circuit of a plonky2 circuit into a constraint system", compileCmd},
+	{"setup", "generate the proving and verifying keys of a constraint system", setupCmd},
+	{"prove", "prove a plonky2 proof with the verifier circuit", proveCmd},
+	{"verify", "verify a proof of the verifier circuit against the plonky2 public inputs", verifyCmd},
+	{"export-solidity", "export a verifying key as a Solidity verifier contract", exportSolidityCmd},
+	{"profile", "count the constraints of the verifier circuit per subsystem", profileCmd},
+	{"calldata", "encode a proof and the plonky2 public inputs into calldata for the Solidity verifier", calldataCmd},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags]\n\ncommands:\n", os.Args[0])
+	for _, command := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", command.name, command.description)
+	}
+	fmt.Fprintf(os.Stderr, "\nrun %s <command> -h for the flags of a command\n", os.Args[0])
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, command := range commands {
+		if command.name == os.Args[1] {
+			if err := command.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", command.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
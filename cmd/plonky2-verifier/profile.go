@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/elliottech/gnark-plonky2-verifier/profiler"
+)
+
+func profileCmd(args []string) error {
+	flags := flag.NewFlagSet("profile", flag.ExitOnError)
+	commonPath := flags.String("common", "common_circuit_data.json", "plonky2 common circuit data, as JSON or plonky2 bytes")
+	hasher := hasherFlag(flags)
+	legacyTranscript := legacyTranscriptFlag(flags)
+	system := flags.String("system", "groth16", "proving system, groth16 or plonk")
+	verifierOnlyPath := flags.String("verifier-only", "", "plonky2 verifier only circuit data, as JSON or plonky2 bytes; required unless -universal")
+	universal := flags.Bool("universal", false, "profile the universal verifier circuit")
+	pprofPath := flags.String("pprof", "gnark.pprof", "output gnark pprof profile, none if empty")
+	flags.Parse(args)
+
+	id, err := parseSystem(*system)
+	if err != nil {
+		return err
+	}
+
+	commonCircuitData, err := readCommonCircuitData(*commonPath, *hasher)
+	if err != nil {
+		return err
+	}
+	commonCircuitData.LegacyTranscript = *legacyTranscript
+
+	circuit, err := newVerifierCircuit(commonCircuitData, *verifierOnlyPath, *universal)
+	if err != nil {
+		return err
+	}
+	var profile *profiler.Profile
+	if id == backend.PLONK {
+		profile, _, err = profiler.Record(curve.ScalarField(), scs.NewBuilder, circuit, *pprofPath)
+	} else {
+		profile, _, err = profiler.Record(curve.ScalarField(), r1cs.NewBuilder, circuit, *pprofPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *pprofPath != "" {
+		log.Printf("wrote the gnark profile to %s", *pprofPath)
+	}
+	return profile.WriteTable(os.Stdout)
+}
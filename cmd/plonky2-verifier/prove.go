@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/frontend"
+	"github.com/elliottech/gnark-plonky2-verifier/native"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+	"github.com/elliottech/gnark-plonky2-verifier/verifier"
+)
+
+func proveCmd(args []string) error {
+	flags := flag.NewFlagSet("prove", flag.ExitOnError)
+	system := flags.String("system", "groth16", "proving system, groth16 or plonk")
+	csPath := flags.String("cs", "circuit.cs", "constraint system written by compile")
+	pkPath := flags.String("pk", "circuit.pk", "proving key written by setup")
+	commonPath := flags.String("common", "common_circuit_data.json", "plonky2 common circuit data, as JSON or plonky2 bytes")
+	hasher := hasherFlag(flags)
+	legacyTranscript := legacyTranscriptFlag(flags)
+	proofWithPisPath := flags.String("proof-with-pis", "proof_with_public_inputs.json", "plonky2 proof with public inputs, as JSON or plonky2 bytes")
+	verifierOnlyPath := flags.String("verifier-only", "verifier_only_circuit_data.json", "plonky2 verifier only circuit data, as JSON or plonky2 bytes")
+	proofPath := flags.String("out", "proof.bin", "output proof")
+	compressed := flags.Bool("compressed", false, "the plonky2 proof is a compressed proof's bytes, decompressed natively")
+	skipNativeCheck := flags.Bool("skip-native-check", false, "don't verify the plonky2 proof natively before proving")
+	universal := flags.Bool("universal", false, "prove the universal verifier circuit compiled with compile -universal")
+	flags.Parse(args)
+
+	id, err := parseSystem(*system)
+	if err != nil {
+		return err
+	}
+
+	commonCircuitData, err := readCommonCircuitData(*commonPath, *hasher)
+	if err != nil {
+		return err
+	}
+	commonCircuitData.LegacyTranscript = *legacyTranscript
+	verifierOnlyRaw, err := readVerifierOnlyCircuitData(*verifierOnlyPath, &commonCircuitData)
+	if err != nil {
+		return err
+	}
+	var proofWithPisRaw types.ProofWithPublicInputsRaw
+	if *compressed {
+		compressedProofWithPis, err := types.ReadCompressedProofWithPublicInputsBinary(*proofWithPisPath, &commonCircuitData)
+		if err != nil {
+			return err
+		}
+		proofWithPisRaw, err = native.DecompressProofWithPublicInputs(compressedProofWithPis, verifierOnlyRaw, commonCircuitData)
+		if err != nil {
+			return err
+		}
+	} else {
+		proofWithPisRaw, err = readProofWithPublicInputs(*proofWithPisPath, *commonPath, *hasher, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Proving an invalid plonky2 proof only fails once the witness is solved, after loading the
+	// constraint system and the proving key, and without saying which check failed.
+	if !*skipNativeCheck {
+		if err := native.Verify(proofWithPisRaw, verifierOnlyRaw, commonCircuitData); err != nil {
+			return fmt.Errorf("the plonky2 proof is invalid: %w", err)
+		}
+		if *universal {
+			if err := native.VerifyCircuitDigest(verifierOnlyRaw, commonCircuitData); err != nil {
+				return err
+			}
+		}
+	}
+
+	proofWithPis := variables.DeserializeProofWithPublicInputs(proofWithPisRaw)
+	var assignment frontend.Circuit = verifier.NewVerifierCircuitAssignment(proofWithPis)
+	if *universal {
+		assignment = verifier.NewUniversalVerifierCircuitAssignment(proofWithPis, variables.DeserializeVerifierOnlyCircuitData(verifierOnlyRaw))
+	}
+	witness, err := frontend.NewWitness(assignment, curve.ScalarField())
+	if err != nil {
+		return err
+	}
+
+	ccs, err := readCS(id, *csPath)
+	if err != nil {
+		return err
+	}
+
+	// Prove for the exported Solidity verifier, which hashes the commitments with keccak256.
+	proverOption := solidity.WithProverTargetSolidityVerifier(id)
+
+	start := time.Now()
+	var proof io.WriterTo
+	switch id {
+	case backend.GROTH16:
+		pk := groth16.NewProvingKey(curve)
+		if err := readFile(*pkPath, pk); err != nil {
+			return err
+		}
+		proof, err = groth16.Prove(ccs, pk, witness, proverOption)
+	case backend.PLONK:
+		pk := plonk.NewProvingKey(curve)
+		if err := readFile(*pkPath, pk); err != nil {
+			return err
+		}
+		proof, err = plonk.Prove(ccs, pk, witness, proverOption)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("proved in %s", time.Since(start))
+
+	return writeFile(*proofPath, proof)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"log"
+
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/test/unsafekzg"
+	"github.com/elliottech/gnark-plonky2-verifier/trusted_setup"
+)
+
+func setupCmd(args []string) error {
+	flags := flag.NewFlagSet("setup", flag.ExitOnError)
+	system := flags.String("system", "groth16", "proving system, groth16 or plonk")
+	csPath := flags.String("cs", "circuit.cs", "constraint system written by compile")
+	pkPath := flags.String("pk", "circuit.pk", "output proving key")
+	vkPath := flags.String("vk", "circuit.vk", "output verifying key")
+	srsPath := flags.String("srs", "", "KZG SRS file, required by plonk")
+	srsCacheDir := flags.String("srs-cache", "", "directory caching the SRS truncated to the constraint system and its Lagrange form")
+	srsCacheModTime := flags.Bool("srs-cache-mtime", false, "key the SRS cache on the path, size and modification time of the SRS rather than its SHA-256, which skips hashing it but doesn't notice an SRS replaced with the same size and modification time")
+	unsafeSRS := flags.Bool("unsafe-srs", false, "generate an insecure KZG SRS for plonk, for testing only")
+	flags.Parse(args)
+
+	id, err := parseSystem(*system)
+	if err != nil {
+		return err
+	}
+
+	ccs, err := readCS(id, *csPath)
+	if err != nil {
+		return err
+	}
+
+	var pk, vk io.WriterTo
+	switch id {
+	case backend.GROTH16:
+		pk, vk, err = groth16.Setup(ccs)
+	case backend.PLONK:
+		var srs, srsLagrange kzg.SRS
+		switch {
+		case *srsPath != "":
+			srs, srsLagrange, err = trusted_setup.ReadPlonkSrs(ccs, *srsPath, *srsCacheDir, *srsCacheModTime)
+		case *unsafeSRS:
+			log.Println("WARNING: generating an insecure SRS, the keys must not be used in production")
+			srs, srsLagrange, err = unsafekzg.NewSRS(ccs)
+		default:
+			err = errors.New("plonk needs an SRS, pass -srs or -unsafe-srs")
+		}
+		if err != nil {
+			return err
+		}
+		pk, vk, err = plonk.Setup(ccs, srs, srsLagrange)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := writeFile(*pkPath, pk); err != nil {
+		return err
+	}
+	return writeFile(*vkPath, vk)
+}
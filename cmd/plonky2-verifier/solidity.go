@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	gnarkSolidity "github.com/consensys/gnark/backend/solidity"
+	"github.com/elliottech/gnark-plonky2-verifier/solidity"
+)
+
+// Calls write with stdout if path is empty, and with the created file otherwise.
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+func exportSolidityCmd(args []string) error {
+	flags := flag.NewFlagSet("export-solidity", flag.ExitOnError)
+	system := flags.String("system", "groth16", "proving system, groth16 or plonk")
+	vkPath := flags.String("vk", "circuit.vk", "verifying key written by setup")
+	contractPath := flags.String("out", "Verifier.sol", "output Solidity contract, stdout if empty")
+	flags.Parse(args)
+
+	id, err := parseSystem(*system)
+	if err != nil {
+		return err
+	}
+
+	var vk interface {
+		io.ReaderFrom
+		gnarkSolidity.VerifyingKey
+	}
+	switch id {
+	case backend.GROTH16:
+		vk = groth16.NewVerifyingKey(curve)
+	case backend.PLONK:
+		vk = plonk.NewVerifyingKey(curve)
+	}
+	if err := readFile(*vkPath, vk); err != nil {
+		return err
+	}
+
+	return writeOutput(*contractPath, func(w io.Writer) error {
+		return solidity.ExportVerifyingKey(w, vk)
+	})
+}
+
+func calldataCmd(args []string) error {
+	flags := flag.NewFlagSet("calldata", flag.ExitOnError)
+	system := flags.String("system", "groth16", "proving system, groth16 or plonk")
+	proofPath := flags.String("proof", "proof.bin", "proof written by prove")
+	proofWithPisPath := flags.String("proof-with-pis", "proof_with_public_inputs.json", "plonky2 proof with the public inputs, as JSON or plonky2 bytes")
+	commonPath := flags.String("common", "common_circuit_data.json", "plonky2 common circuit data, read for plonky2 bytes proofs")
+	hasher := hasherFlag(flags)
+	compressed := flags.Bool("compressed", false, "the plonky2 proof is a compressed proof's bytes")
+	calldataPath := flags.String("out", "", "output hex encoded calldata, stdout if empty")
+	universal := flags.Bool("universal", false, "encode a proof of the universal verifier circuit, with the circuit digest of -verifier-only")
+	verifierOnlyPath := flags.String("verifier-only", "", "plonky2 verifier only circuit data of the proof's circuit digest, required with -universal")
+	flags.Parse(args)
+
+	id, err := parseSystem(*system)
+	if err != nil {
+		return err
+	}
+
+	var proof io.ReaderFrom
+	switch id {
+	case backend.GROTH16:
+		proof = groth16.NewProof(curve)
+	case backend.PLONK:
+		proof = plonk.NewProof(curve)
+	}
+	if err := readFile(*proofPath, proof); err != nil {
+		return err
+	}
+
+	proofWithPisRaw, err := readProofWithPublicInputs(*proofWithPisPath, *commonPath, *hasher, *compressed)
+	if err != nil {
+		return err
+	}
+	var calldata []byte
+	if *universal {
+		circuitDigest, err := readCircuitDigest(*verifierOnlyPath, *commonPath, *hasher)
+		if err != nil {
+			return err
+		}
+		calldata, err = solidity.UniversalCalldata(proof, proofWithPisRaw.PublicInputs, circuitDigest.Elements)
+	} else {
+		calldata, err = solidity.Calldata(proof, proofWithPisRaw.PublicInputs)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*calldataPath, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "0x%s\n", hex.EncodeToString(calldata))
+		return err
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"path/filepath"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+	"github.com/elliottech/gnark-plonky2-verifier/verifier"
+)
+
+// Returns the public witness of the verifier circuit, i.e. the public inputs of the plonky2 proof.
+func publicWitness(proofWithPisRaw types.ProofWithPublicInputsRaw) (witness.Witness, error) {
+	assignment := verifier.VerifierCircuit{
+		PublicInputs: variables.DeserializeProofWithPublicInputs(proofWithPisRaw).PublicInputs,
+	}
+	return frontend.NewWitness(&assignment, curve.ScalarField(), frontend.PublicOnly())
+}
+
+// Returns the public witness of the universal verifier circuit, i.e. the public inputs of the
+// plonky2 proof followed by the circuit digest.
+func universalPublicWitness(proofWithPisRaw types.ProofWithPublicInputsRaw, circuitDigest types.HashOutRaw) (witness.Witness, error) {
+	assignment := verifier.UniversalVerifierCircuit{
+		PublicInputs:  variables.DeserializeProofWithPublicInputs(proofWithPisRaw).PublicInputs,
+		CircuitDigest: variables.DeserializeHashOut(circuitDigest),
+	}
+	return frontend.NewWitness(&assignment, curve.ScalarField(), frontend.PublicOnly())
+}
+
+// Reads the circuit digest of the verifier only circuit data at path, of a plonky2 circuit using
+// hasher, whose binary serialization is read with the common circuit data at commonPath.
+func readCircuitDigest(path string, commonPath string, hasher string) (types.HashOutRaw, error) {
+	if path == "" {
+		return types.HashOutRaw{}, errors.New("-verifier-only is required with -universal, to read the circuit digest of the proof")
+	}
+	var commonCircuitData types.CommonCircuitData
+	var err error
+	if filepath.Ext(path) != ".json" {
+		commonCircuitData, err = readCommonCircuitData(commonPath, hasher)
+	} else {
+		commonCircuitData.Hasher, err = parseHasher(hasher)
+	}
+	if err != nil {
+		return types.HashOutRaw{}, err
+	}
+	verifierOnlyRaw, err := readVerifierOnlyCircuitData(path, &commonCircuitData)
+	return verifierOnlyRaw.CircuitDigest, err
+}
+
+func verifyCmd(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	system := flags.String("system", "groth16", "proving system, groth16 or plonk")
+	vkPath := flags.String("vk", "circuit.vk", "verifying key written by setup")
+	proofPath := flags.String("proof", "proof.bin", "proof written by prove")
+	proofWithPisPath := flags.String("proof-with-pis", "proof_with_public_inputs.json", "plonky2 proof with the public inputs, as JSON or plonky2 bytes")
+	commonPath := flags.String("common", "common_circuit_data.json", "plonky2 common circuit data, read for plonky2 bytes proofs")
+	hasher := hasherFlag(flags)
+	compressed := flags.Bool("compressed", false, "the plonky2 proof is a compressed proof's bytes")
+	universal := flags.Bool("universal", false, "verify a proof of the universal verifier circuit, against the circuit digest of -verifier-only")
+	verifierOnlyPath := flags.String("verifier-only", "", "plonky2 verifier only circuit data of the proof's circuit digest, required with -universal")
+	flags.Parse(args)
+
+	id, err := parseSystem(*system)
+	if err != nil {
+		return err
+	}
+
+	proofWithPisRaw, err := readProofWithPublicInputs(*proofWithPisPath, *commonPath, *hasher, *compressed)
+	if err != nil {
+		return err
+	}
+	var public witness.Witness
+	if *universal {
+		circuitDigest, err := readCircuitDigest(*verifierOnlyPath, *commonPath, *hasher)
+		if err != nil {
+			return err
+		}
+		public, err = universalPublicWitness(proofWithPisRaw, circuitDigest)
+	} else {
+		public, err = publicWitness(proofWithPisRaw)
+	}
+	if err != nil {
+		return err
+	}
+
+	verifierOption := solidity.WithVerifierTargetSolidityVerifier(id)
+	switch id {
+	case backend.GROTH16:
+		vk := groth16.NewVerifyingKey(curve)
+		proof := groth16.NewProof(curve)
+		if err := readFile(*vkPath, vk); err != nil {
+			return err
+		}
+		if err := readFile(*proofPath, proof); err != nil {
+			return err
+		}
+		err = groth16.Verify(proof, vk, public, verifierOption)
+	case backend.PLONK:
+		vk := plonk.NewVerifyingKey(curve)
+		proof := plonk.NewProof(curve)
+		if err := readFile(*vkPath, vk); err != nil {
+			return err
+		}
+		if err := readFile(*proofPath, proof); err != nil {
+			return err
+		}
+		err = plonk.Verify(proof, vk, public, verifierOption)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Println("the proof is valid")
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+	"github.com/elliottech/gnark-plonky2-verifier/verifier"
+)
+
+// The public witness built from the public inputs alone must match the public part of the full
+// witness the prover uses.
+func TestPublicWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	proofWithPisRaw := types.ReadProofWithPublicInputs("../../testdata/decode_block/proof_with_public_inputs.json")
+
+	assignment := verifier.NewVerifierCircuitAssignment(variables.DeserializeProofWithPublicInputs(proofWithPisRaw))
+	fullWitness, err := frontend.NewWitness(assignment, curve.ScalarField())
+	assert.NoError(err)
+	expected, err := fullWitness.Public()
+	assert.NoError(err)
+
+	actual, err := publicWitness(proofWithPisRaw)
+	assert.NoError(err)
+
+	expectedBytes, err := expected.MarshalBinary()
+	assert.NoError(err)
+	actualBytes, err := actual.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(expectedBytes, actualBytes)
+}
+
+// Likewise for the universal verifier circuit, whose public witness ends with the circuit digest.
+func TestUniversalPublicWitness(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	proofWithPisRaw := types.ReadProofWithPublicInputs("../../testdata/decode_block/proof_with_public_inputs.json")
+	verifierOnlyRaw := types.ReadVerifierOnlyCircuitData("../../testdata/decode_block/verifier_only_circuit_data.json")
+
+	assignment := verifier.NewUniversalVerifierCircuitAssignment(
+		variables.DeserializeProofWithPublicInputs(proofWithPisRaw),
+		variables.DeserializeVerifierOnlyCircuitData(verifierOnlyRaw),
+	)
+	fullWitness, err := frontend.NewWitness(assignment, curve.ScalarField())
+	assert.NoError(err)
+	expected, err := fullWitness.Public()
+	assert.NoError(err)
+
+	_, err = readCircuitDigest("", "", "poseidon-bn254")
+	assert.ErrorContains(err, "-verifier-only is required")
+	circuitDigest, err := readCircuitDigest("../../testdata/decode_block/verifier_only_circuit_data.json", "", "poseidon-bn254")
+	assert.NoError(err)
+	actual, err := universalPublicWitness(proofWithPisRaw, circuitDigest)
+	assert.NoError(err)
+
+	expectedBytes, err := expected.MarshalBinary()
+	assert.NoError(err)
+	actualBytes, err := actual.MarshalBinary()
+	assert.NoError(err)
+	assert.Equal(expectedBytes, actualBytes)
+	assert.Equal(len(proofWithPisRaw.PublicInputs)+len(circuitDigest.Elements), len(actual.Vector().(fr.Vector)))
+}
+
+// The verifier circuit is bound to its verifier only circuit data, which compile requires unless
+// the circuit is universal.
+func TestNewVerifierCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData("../../testdata/decode_block/common_circuit_data.json")
+	_, err := newVerifierCircuit(commonCircuitData, "", false)
+	assert.ErrorContains(err, "-verifier-only is required")
+
+	circuit, err := newVerifierCircuit(commonCircuitData, "../../testdata/decode_block/verifier_only_circuit_data.json", false)
+	assert.NoError(err)
+	expected := variables.DeserializeVerifierOnlyCircuitData(types.ReadVerifierOnlyCircuitData("../../testdata/decode_block/verifier_only_circuit_data.json"))
+	assert.Equal(expected, circuit.(*verifier.VerifierCircuit).VerifierOnlyCircuitData)
+
+	circuit, err = newVerifierCircuit(commonCircuitData, "", true)
+	assert.NoError(err)
+	assert.IsType(&verifier.UniversalVerifierCircuit{}, circuit)
+}
+
+// The serializations don't hold the plonky2 circuit's hasher, which -hasher gives and the circuit
+// digest's format checks.
+func TestReadHasher(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonPath := "../../testdata/decode_block/common_circuit_data.json"
+	verifierOnlyPath := "../../testdata/decode_block/verifier_only_circuit_data.json"
+
+	_, err := readCommonCircuitData(commonPath, "poseidon")
+	assert.ErrorContains(err, "unknown hasher")
+
+	commonCircuitData, err := readCommonCircuitData(commonPath, "poseidon-goldilocks")
+	assert.NoError(err)
+	assert.Equal(types.PoseidonGoldilocksHash, commonCircuitData.Hasher)
+	// decode_block's circuit digest is a PoseidonBN254 digest.
+	_, err = readVerifierOnlyCircuitData(verifierOnlyPath, &commonCircuitData)
+	assert.ErrorContains(err, "check -hasher")
+	_, err = readCircuitDigest(verifierOnlyPath, commonPath, "keccak")
+	assert.ErrorContains(err, "check -hasher")
+
+	commonCircuitData, err = readCommonCircuitData(commonPath, "poseidon-bn254")
+	assert.NoError(err)
+	_, err = readVerifierOnlyCircuitData(verifierOnlyPath, &commonCircuitData)
+	assert.NoError(err)
+}
+
+// The proofs of testdata need -legacy-transcript, without which prove rejects them natively.
+func TestProveLegacyTranscript(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	dir := t.TempDir()
+	args := []string{
+		"-cs", filepath.Join(dir, "circuit.cs"),
+		"-pk", filepath.Join(dir, "circuit.pk"),
+		"-common", "../../testdata/decode_block/common_circuit_data.json",
+		"-proof-with-pis", "../../testdata/decode_block/proof_with_public_inputs.json",
+		"-verifier-only", "../../testdata/decode_block/verifier_only_circuit_data.json",
+		"-out", filepath.Join(dir, "proof.bin"),
+	}
+	assert.ErrorContains(proveCmd(args), "the plonky2 proof is invalid")
+	// The proof passes the native check, and prove goes on to read the constraint system.
+	assert.ErrorIs(proveCmd(append(args, "-legacy-transcript")), os.ErrNotExist)
+
+	// The verifier circuit compiled with -legacy-transcript is solved by the witness prove assigns.
+	commonCircuitData, err := readCommonCircuitData("../../testdata/decode_block/common_circuit_data.json", "poseidon-bn254")
+	assert.NoError(err)
+	commonCircuitData.LegacyTranscript = true
+	circuit, err := newVerifierCircuit(commonCircuitData, "../../testdata/decode_block/verifier_only_circuit_data.json", false)
+	assert.NoError(err)
+	proofWithPis := variables.DeserializeProofWithPublicInputs(types.ReadProofWithPublicInputs("../../testdata/decode_block/proof_with_public_inputs.json"))
+	assert.NoError(test.IsSolved(circuit, verifier.NewVerifierCircuitAssignment(proofWithPis), curve.ScalarField()))
+}
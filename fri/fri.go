@@ -8,18 +8,20 @@ import (
 
 	"github.com/consensys/gnark-crypto/field/goldilocks"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/selector"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
-	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/hasher"
+	"github.com/elliottech/gnark-plonky2-verifier/profiler"
 	"github.com/elliottech/gnark-plonky2-verifier/types"
 	"github.com/elliottech/gnark-plonky2-verifier/variables"
 )
 
 type Chip struct {
-	api               frontend.API             `gnark:"-"`
-	gl                *gl.Chip                 `gnark:"-"`
-	poseidonBN254Chip *poseidon.BN254Chip      `gnark:"-"`
-	commonData        *types.CommonCircuitData `gnark:"-"`
-	FriParams         *types.FriParams         `gnark:"-"`
+	api        frontend.API             `gnark:"-"`
+	gl         *gl.Chip                 `gnark:"-"`
+	hasher     hasher.Hasher            `gnark:"-"`
+	commonData *types.CommonCircuitData `gnark:"-"`
+	FriParams  *types.FriParams         `gnark:"-"`
 }
 
 func NewChip(
@@ -27,20 +29,35 @@ func NewChip(
 	commonData *types.CommonCircuitData,
 	friParams *types.FriParams,
 ) *Chip {
-	poseidonBN254Chip := poseidon.NewBN254Chip(api)
 	return &Chip{
-		api:               api,
-		poseidonBN254Chip: poseidonBN254Chip,
-		commonData:        commonData,
-		FriParams:         friParams,
-		gl:                gl.New(api),
+		api:        api,
+		hasher:     hasher.New(api, commonData.Hasher),
+		commonData: commonData,
+		FriParams:  friParams,
+		gl:         gl.New(api),
+	}
+}
+
+// Creates a chip verifying FRI proofs with friParams outside of a plonky2 circuit, e.g. the opening
+// proofs of Starky proofs. GetInstance and ToOpenings describe plonky2's oracles, so they can't be
+// used with this chip.
+func NewChipWithParams(
+	api frontend.API,
+	hasherType types.HasherType,
+	friParams *types.FriParams,
+) *Chip {
+	return &Chip{
+		api:       api,
+		hasher:    hasher.New(api, hasherType),
+		FriParams: friParams,
+		gl:        gl.New(api),
 	}
 }
 
 func (f *Chip) GetInstance(zeta gl.QuadraticExtensionVariable) InstanceInfo {
 	zetaBatch := BatchInfo{
 		Point:       zeta,
-		Polynomials: friAllPolys(f.commonData),
+		Polynomials: FriAllPolys(f.commonData),
 	}
 
 	g := gl.PrimitiveRootOfUnity(f.commonData.DegreeBits)
@@ -51,11 +68,11 @@ func (f *Chip) GetInstance(zeta gl.QuadraticExtensionVariable) InstanceInfo {
 
 	zetaNextBatch := BatchInfo{
 		Point:       zetaNext,
-		Polynomials: friZSPolys(f.commonData),
+		Polynomials: FriNextBatchPolys(f.commonData),
 	}
 
 	return InstanceInfo{
-		Oracles: friOracles(f.commonData),
+		Oracles: FriOracles(f.commonData),
 		Batches: []BatchInfo{zetaBatch, zetaNextBatch},
 	}
 }
@@ -67,11 +84,46 @@ func (f *Chip) ToOpenings(c variables.OpeningSet) Openings {
 	values = append(values, c.PlonkZs...)         // num_challenges
 	values = append(values, c.PartialProducts...) // num_challenges * num_partial_products
 	values = append(values, c.QuotientPolys...)   // num_challenges * quotient_degree_factor
+	values = append(values, c.LookupZs...)        // num_challenges * num_lookup_polys
 	zetaBatch := OpeningBatch{Values: values}
-	zetaNextBatch := OpeningBatch{Values: c.PlonkZsNext}
+	nextValues := c.PlonkZsNext                        // num_challenges
+	nextValues = append(nextValues, c.LookupZsNext...) // num_challenges * num_lookup_polys
+	zetaNextBatch := OpeningBatch{Values: nextValues}
 	return Openings{Batches: []OpeningBatch{zetaBatch, zetaNextBatch}}
 }
 
+// Range checks the elements of a FRI proof: its query rounds' evaluations and Merkle siblings, the
+// final polynomial and the proof of work witness.
+func (f *Chip) RangeCheckFriProof(proof *variables.FriProof) {
+	for _, queryRound := range proof.QueryRoundProofs {
+		for _, evalsProof := range queryRound.InitialTreesProof.EvalsProofs {
+			for _, evalsProofElement := range evalsProof.Elements {
+				f.gl.RangeCheck(evalsProofElement)
+			}
+
+			for _, sibling := range evalsProof.MerkleProof.Siblings {
+				f.hasher.RangeCheck(sibling)
+			}
+		}
+
+		for _, queryStep := range queryRound.Steps {
+			for _, eval := range queryStep.Evals {
+				f.gl.RangeCheckQE(eval)
+			}
+
+			for _, sibling := range queryStep.MerkleProof.Siblings {
+				f.hasher.RangeCheck(sibling)
+			}
+		}
+	}
+
+	for _, coeff := range proof.FinalPoly.Coeffs {
+		f.gl.RangeCheckQE(coeff)
+	}
+
+	f.gl.RangeCheck(proof.PowWitness)
+}
+
 func (f *Chip) assertLeadingZeros(powWitness gl.Variable, friConfig types.FriConfig) {
 	// Asserts that powWitness'es big-endian bit representation has at least friConfig.ProofOfWorkBits leading zeros.
 	// Note that this is assuming that the Goldilocks field is being used.  Specfically that the
@@ -84,7 +136,7 @@ func (f *Chip) fromOpeningsAndAlpha(
 	alpha gl.QuadraticExtensionVariable,
 ) []gl.QuadraticExtensionVariable {
 	// One reduced opening for all openings evaluated at point Zeta.
-	// Another one for all openings evaluated at point Zeta * Omega (which is only PlonkZsNext polynomial)
+	// Another one for all openings evaluated at point Zeta * Omega (which are the PlonkZsNext and LookupZsNext polynomials)
 
 	reducedOpenings := make([]gl.QuadraticExtensionVariable, 0, 2)
 	for _, batch := range openings.Batches {
@@ -101,55 +153,46 @@ func (f *Chip) verifyMerkleProofToCapWithCapIndex(
 	merkleCap variables.FriMerkleCap,
 	proof *variables.FriMerkleProof,
 ) {
-	currentDigest := f.poseidonBN254Chip.HashOrNoop(leafData)
+	currentDigest := f.hasher.HashOrNoop(leafData)
 	for i, sibling := range proof.Siblings {
 		bit := leafIndexBits[i]
 
-		var inputs poseidon.BN254State
-		inputs[0] = frontend.Variable(0)
-		inputs[1] = frontend.Variable(0)
-		inputs[2] = f.api.Select(bit, sibling, currentDigest)
-		inputs[3] = f.api.Select(bit, currentDigest, sibling)
-		state := f.poseidonBN254Chip.Poseidon(inputs)
-
-		currentDigest = state[0]
-	}
+		left := make(variables.HashOut, len(sibling))
+		right := make(variables.HashOut, len(sibling))
+		for j := range sibling {
+			left[j] = f.api.Select(bit, sibling[j], currentDigest[j])
+			right[j] = f.api.Select(bit, currentDigest[j], sibling[j])
+		}
 
-	// We assume that the cap_height is 4.  Create two levels of the Lookup2 circuit
-	if len(capIndexBits) != 4 || len(merkleCap) != 16 {
-		errorMsg, _ := fmt.Printf(
-			"capIndexBits length should be 4 and the merkleCap length should be 16.  Actual values (capIndexBits: %d, merkleCap: %d)\n",
-			len(capIndexBits),
-			len(merkleCap),
-		)
-		panic(errorMsg)
+		currentDigest = f.hasher.TwoToOne(left, right)
 	}
 
-	const NUM_LEAF_LOOKUPS = 4
-	// Each lookup gadget will connect to 4 merkleCap entries
-	const STRIDE_LENGTH = 4
-	var leafLookups [NUM_LEAF_LOOKUPS]poseidon.BN254HashOut
-	// First create the "leaf" lookup2 circuits
-	// This will use the least significant bits of the capIndexBits array
-	for i := 0; i < NUM_LEAF_LOOKUPS; i++ {
-		leafLookups[i] = f.api.Lookup2(
-			capIndexBits[0], capIndexBits[1],
-			merkleCap[i*STRIDE_LENGTH], merkleCap[i*STRIDE_LENGTH+1], merkleCap[i*STRIDE_LENGTH+2], merkleCap[i*STRIDE_LENGTH+3],
-		)
+	// Select the cap entry at capIndex, which works for any cap height (including a height of 0,
+	// where the cap is the single merkle root).
+	for j := range currentDigest {
+		capElements := make([]frontend.Variable, len(merkleCap))
+		for k := range merkleCap {
+			capElements[k] = merkleCap[k][j]
+		}
+		merkleCapElement := selector.BinaryMux(f.api, capIndexBits, capElements)
+		f.api.AssertIsEqual(currentDigest[j], merkleCapElement)
 	}
-
-	// Use the most 2 significant bits of the capIndexBits array for the "root" lookup
-	merkleCapEntry := f.api.Lookup2(capIndexBits[2], capIndexBits[3], leafLookups[0], leafLookups[1], leafLookups[2], leafLookups[3])
-	f.api.AssertIsEqual(currentDigest, merkleCapEntry)
 }
 
-func (f *Chip) verifyInitialProof(xIndexBits []frontend.Variable, proof *variables.FriInitialTreeProof, initialMerkleCaps []variables.FriMerkleCap, capIndexBits []frontend.Variable) {
+func (f *Chip) verifyInitialProof(instance InstanceInfo, xIndexBits []frontend.Variable, proof *variables.FriInitialTreeProof, initialMerkleCaps []variables.FriMerkleCap, capIndexBits []frontend.Variable) {
 	if len(proof.EvalsProofs) != len(initialMerkleCaps) {
 		panic("length of eval proofs in fri proof should equal length of initial merkle caps")
 	}
 
 	for i := 0; i < len(initialMerkleCaps); i++ {
+		// The leaf holds the oracle's polynomial evaluations followed by its salt (if any), and the
+		// salt is hashed along with the evaluations.
 		evals := proof.EvalsProofs[i].Elements
+		oracle := instance.Oracles[i]
+		if len(evals) != int(oracle.NumPolys+f.FriParams.SaltSize(oracle.Blinding)) {
+			panic("length of eval proof leaf doesn't match its oracle")
+		}
+
 		merkleProof := proof.EvalsProofs[i].MerkleProof
 		cap := initialMerkleCaps[i]
 		f.verifyMerkleProofToCapWithCapIndex(evals, xIndexBits, capIndexBits, cap, &merkleProof)
@@ -225,10 +268,11 @@ func (f *Chip) friCombineInitial(
 		point := batch.Point
 		evals := make([]gl.QuadraticExtensionVariable, 0)
 		for _, polynomial := range batch.Polynomials {
+			unsaltedEvals := f.unsaltedEvals(instance, proof, polynomial.OracleIndex)
 			evals = append(
 				evals,
 				gl.QuadraticExtensionVariable{
-					proof.EvalsProofs[polynomial.OracleIndex].Elements[polynomial.PolynomialInfo],
+					unsaltedEvals[polynomial.PolynomialInfo],
 					gl.Zero(),
 				},
 			)
@@ -250,6 +294,13 @@ func (f *Chip) friCombineInitial(
 	return sum
 }
 
+// Returns the polynomial evaluations of an oracle's leaf, without the salt of hiding proofs.
+func (f *Chip) unsaltedEvals(instance InstanceInfo, proof variables.FriInitialTreeProof, oracleIndex uint64) []gl.Variable {
+	evals := proof.EvalsProofs[oracleIndex].Elements
+	saltSize := f.FriParams.SaltSize(instance.Oracles[oracleIndex].Blinding)
+	return evals[:uint64(len(evals))-saltSize]
+}
+
 func (f *Chip) finalPolyEval(finalPoly variables.PolynomialCoeffs, point gl.QuadraticExtensionVariable) gl.QuadraticExtensionVariable {
 	ret := gl.ZeroExtension()
 	for i := len(finalPoly.Coeffs) - 1; i >= 0; i-- {
@@ -322,9 +373,6 @@ func (f *Chip) computeEvaluation(
 	if (len(evals)) != arity {
 		panic("len(evals) != arity")
 	}
-	if arityBits > 8 {
-		panic("currently assuming that arityBits is <= 8")
-	}
 
 	g := gl.PrimitiveRootOfUnity(arityBits)
 	gInv := goldilocks.NewElement(0)
@@ -335,8 +383,8 @@ func (f *Chip) computeEvaluation(
 	// OPTIMIZE - Since the size of the evals array should be constant (e.g. 2^arityBits),
 	//        we can just hard code the permutation.
 	permutedEvals := make([]gl.QuadraticExtensionVariable, len(evals))
-	for i := uint8(0); i <= uint8(len(evals)-1); i++ {
-		newIndex := bits.Reverse8(i) >> (8 - arityBits)
+	for i := 0; i < len(evals); i++ {
+		newIndex := bits.Reverse64(uint64(i)) >> (64 - arityBits)
 		permutedEvals[newIndex] = evals[i]
 	}
 
@@ -401,7 +449,9 @@ func (f *Chip) verifyQueryRound(
 	xIndexBits := f.api.ToBinary(xIndex.Limb, 64)[0 : f.FriParams.DegreeBits+f.FriParams.Config.RateBits]
 	capIndexBits := xIndexBits[len(xIndexBits)-int(f.FriParams.Config.CapHeight):]
 
-	f.verifyInitialProof(xIndexBits, &roundProof.InitialTreesProof, initialMerkleCaps, capIndexBits)
+	stop := profiler.Start("initial merkle proofs")
+	f.verifyInitialProof(instance, xIndexBits, &roundProof.InitialTreesProof, initialMerkleCaps, capIndexBits)
+	stop()
 
 	subgroupX := f.calculateSubgroupX(
 		xIndexBits,
@@ -410,6 +460,7 @@ func (f *Chip) verifyQueryRound(
 
 	subgroupX_QE := subgroupX.ToQuadraticExtension()
 
+	stop = profiler.Start("combine initial")
 	oldEval := f.friCombineInitial(
 		instance,
 		roundProof.InitialTreesProof,
@@ -417,6 +468,7 @@ func (f *Chip) verifyQueryRound(
 		subgroupX_QE,
 		precomputedReducedEval,
 	)
+	stop()
 
 	for i, arityBits := range f.FriParams.ReductionArityBits {
 		evals := roundProof.Steps[i].Evals
@@ -424,42 +476,13 @@ func (f *Chip) verifyQueryRound(
 		cosetIndexBits := xIndexBits[arityBits:]
 		xIndexWithinCosetBits := xIndexBits[:arityBits]
 
-		// Assumes that the arity bits will be 4.  That means that the range of
-		// xIndexWithCoset is [0,2^4-1].  This is based on plonky2's circuit recursive
-		// config:  https://github.com/mir-protocol/plonky2/blob/main/plonky2/src/plonk/circuit_data.rs#L63
-		// Will use a two levels tree of 4-selector gadgets.
-		if arityBits != 4 {
-			panic("assuming arity bits is 4")
-		}
-
-		const NUM_LEAF_LOOKUPS = 4
-		var leafLookups [NUM_LEAF_LOOKUPS]gl.QuadraticExtensionVariable
-		// First create the "leaf" lookup2 circuits
-		// The will use the least significant bits of the xIndexWithCosetBits array
-		for i := 0; i < NUM_LEAF_LOOKUPS; i++ {
-			leafLookups[i] = f.gl.Lookup2(
-				xIndexWithinCosetBits[0],
-				xIndexWithinCosetBits[1],
-				evals[i*NUM_LEAF_LOOKUPS],
-				evals[i*NUM_LEAF_LOOKUPS+1],
-				evals[i*NUM_LEAF_LOOKUPS+2],
-				evals[i*NUM_LEAF_LOOKUPS+3],
-			)
-		}
-
-		// Use the most 2 significant bits of the xIndexWithCosetBits array for the "root" lookup
-		newEval := f.gl.Lookup2(
-			xIndexWithinCosetBits[2],
-			xIndexWithinCosetBits[3],
-			leafLookups[0],
-			leafLookups[1],
-			leafLookups[2],
-			leafLookups[3],
-		)
+		// Select the eval at xIndexWithinCoset, which must match the previous step's evaluation.
+		newEval := f.gl.LookupN(xIndexWithinCosetBits, evals)
 
 		f.gl.AssertIsEqual(newEval[0], oldEval[0])
 		f.gl.AssertIsEqual(newEval[1], oldEval[1])
 
+		stop = profiler.Start(fmt.Sprintf("step %d interpolation", i))
 		oldEval = f.computeEvaluation(
 			subgroupX,
 			xIndexWithinCosetBits,
@@ -467,6 +490,7 @@ func (f *Chip) verifyQueryRound(
 			evals,
 			challenges.FriBetas[i],
 		)
+		stop()
 
 		// Convert evals (array of QE) to fields by taking their 0th degree coefficients
 		fieldEvals := make([]gl.Variable, 0, 2*len(evals))
@@ -474,6 +498,7 @@ func (f *Chip) verifyQueryRound(
 			fieldEvals = append(fieldEvals, evals[j][0])
 			fieldEvals = append(fieldEvals, evals[j][1])
 		}
+		stop = profiler.Start(fmt.Sprintf("step %d merkle proof", i))
 		f.verifyMerkleProofToCapWithCapIndex(
 			fieldEvals,
 			cosetIndexBits,
@@ -481,6 +506,7 @@ func (f *Chip) verifyQueryRound(
 			proof.CommitPhaseMerkleCaps[i],
 			&roundProof.Steps[i].MerkleProof,
 		)
+		stop()
 
 		// Update the point x to x^arity.
 		for j := uint64(0); j < arityBits; j++ {
@@ -533,6 +559,7 @@ func (f *Chip) VerifyFriProof(
 	for idx, xIndex := range friChallenges.FriQueryIndices {
 		roundProof := friProof.QueryRoundProofs[idx]
 
+		stop := profiler.Start(fmt.Sprintf("query %d", idx))
 		f.verifyQueryRound(
 			instance,
 			friChallenges,
@@ -544,5 +571,6 @@ func (f *Chip) VerifyFriProof(
 			nLog,
 			&roundProof,
 		)
+		stop()
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/elliottech/gnark-plonky2-verifier/challenger"
 	"github.com/elliottech/gnark-plonky2-verifier/fri"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/hasher"
 	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
 	"github.com/elliottech/gnark-plonky2-verifier/types"
 	"github.com/elliottech/gnark-plonky2-verifier/variables"
@@ -28,9 +29,9 @@ func (circuit *TestFriCircuit) Define(api frontend.API) error {
 	glApi := gl.New(api)
 	poseidonChip := poseidon.NewGoldilocksChip(api)
 	friChip := fri.NewChip(api, &commonCircuitData, &commonCircuitData.FriParams)
-	challengerChip := challenger.NewChip(api)
+	challengerChip := challenger.NewChip(api, hasher.New(api, commonCircuitData.Hasher))
 
-	challengerChip.ObserveBN254Hash(verifierOnlyCircuitData.CircuitDigest)
+	challengerChip.ObserveDigest(verifierOnlyCircuitData.CircuitDigest)
 	challengerChip.ObserveHash(poseidonChip.HashNoPad(proofWithPis.PublicInputs))
 	challengerChip.ObserveCap(proofWithPis.Proof.WiresCap)
 	plonkBetas := challengerChip.GetNChallenges(commonCircuitData.Config.NumChallenges) // For plonk betas
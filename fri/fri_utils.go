@@ -75,6 +75,12 @@ func NumZSPartialProductsPolys(c *types.CommonCircuitData) uint64 {
 	return c.Config.NumChallenges * (1 + c.NumPartialProducts)
 }
 
+// Returns the number of lookup polynomials, which are committed to in the ZS_PARTIAL_PRODUCTS
+// oracle after the Z and partial products polynomials.
+func NumAllLookupPolys(c *types.CommonCircuitData) uint64 {
+	return c.Config.NumChallenges * c.NumLookupPolys
+}
+
 func NumQuotientPolys(c *types.CommonCircuitData) uint64 {
 	return c.Config.NumChallenges * c.QuotientDegreeFactor
 }
@@ -111,6 +117,15 @@ func friQuotientPolys(c *types.CommonCircuitData) []PolynomialInfo {
 	)
 }
 
+func friLookupPolys(c *types.CommonCircuitData) []PolynomialInfo {
+	return polynomialInfoFromRange(
+		c,
+		ZS_PARTIAL_PRODUCTS.index,
+		NumZSPartialProductsPolys(c),
+		NumZSPartialProductsPolys(c)+NumAllLookupPolys(c),
+	)
+}
+
 func friZSPolys(c *types.CommonCircuitData) []PolynomialInfo {
 	return polynomialInfoFromRange(
 		c,
@@ -120,7 +135,8 @@ func friZSPolys(c *types.CommonCircuitData) []PolynomialInfo {
 	)
 }
 
-func friOracles(c *types.CommonCircuitData) []OracleInfo {
+// Returns the oracles committed to by a proof, in the order of the initial Merkle trees.
+func FriOracles(c *types.CommonCircuitData) []OracleInfo {
 	return []OracleInfo{
 		{
 			NumPolys: NumPreprocessedPolys(c),
@@ -131,7 +147,7 @@ func friOracles(c *types.CommonCircuitData) []OracleInfo {
 			Blinding: WIRES.blinding,
 		},
 		{
-			NumPolys: NumZSPartialProductsPolys(c),
+			NumPolys: NumZSPartialProductsPolys(c) + NumAllLookupPolys(c),
 			Blinding: ZS_PARTIAL_PRODUCTS.blinding,
 		},
 		{
@@ -141,16 +157,26 @@ func friOracles(c *types.CommonCircuitData) []OracleInfo {
 	}
 }
 
-func friAllPolys(c *types.CommonCircuitData) []PolynomialInfo {
+// Returns the polynomials opened at `zeta`.
+func FriAllPolys(c *types.CommonCircuitData) []PolynomialInfo {
 	returnArr := make([]PolynomialInfo, 0)
 	returnArr = append(returnArr, friPreprocessedPolys(c)...)
 	returnArr = append(returnArr, friWirePolys(c)...)
 	returnArr = append(returnArr, friZSPartialProductsPolys(c)...)
 	returnArr = append(returnArr, friQuotientPolys(c)...)
+	returnArr = append(returnArr, friLookupPolys(c)...)
 
 	return returnArr
 }
 
+// Returns the polynomials opened at `g * zeta`.
+func FriNextBatchPolys(c *types.CommonCircuitData) []PolynomialInfo {
+	returnArr := make([]PolynomialInfo, 0)
+	returnArr = append(returnArr, friZSPolys(c)...)
+	returnArr = append(returnArr, friLookupPolys(c)...)
+	return returnArr
+}
+
 // This does not add any constraints, it's just a sanity check on the friParams
 // It's a 1-1 port of assert_noncanonical_indices_ok from fri::recursive_verifier in plonky2
 func assertNoncanonicalIndicesOK(friParams types.FriParams) {
@@ -165,8 +191,6 @@ func assertNoncanonicalIndicesOK(friParams types.FriParams) {
 // This does not add any constraints, it is just a sanity check on the shapes of the proof variable
 // and given FriParams. It's a 1-1 port of validate_fri_proof_shape from fri::validate_shape in plonky2
 func validateFriProofShape(proof *variables.FriProof, instance InstanceInfo, params *types.FriParams) {
-	const SALT_SIZE = 4
-
 	commitPhaseMerkleCaps := proof.CommitPhaseMerkleCaps
 	queryRoundProofs := proof.QueryRoundProofs
 	finalPoly := proof.FinalPoly
@@ -188,11 +212,7 @@ func validateFriProofShape(proof *variables.FriProof, instance InstanceInfo, par
 			leaf := evalProof.Elements
 			merkleProof := evalProof.MerkleProof
 			oracle := instance.Oracles[i]
-			salt_size := 0
-			if oracle.Blinding && params.Hiding {
-				salt_size = SALT_SIZE
-			}
-			if len(leaf) != (int(oracle.NumPolys) + salt_size) {
+			if len(leaf) != int(oracle.NumPolys+params.SaltSize(oracle.Blinding)) {
 				panic("eval proof leaf length doesn't match oracle info")
 			}
 			if len(merkleProof.Siblings)+int(capHeight) != params.LdeBits() {
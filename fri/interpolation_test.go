@@ -0,0 +1,103 @@
+package fri
+
+import (
+	"math/big"
+	"math/bits"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+type TestComputeEvaluationCircuit struct {
+	X                 gl.Variable
+	XIndexWithinCoset frontend.Variable
+	Evals             []gl.QuadraticExtensionVariable
+	Beta              gl.QuadraticExtensionVariable
+	ExpectedEval      gl.QuadraticExtensionVariable
+
+	arityBits uint64
+}
+
+func (c *TestComputeEvaluationCircuit) Define(api frontend.API) error {
+	friChip := NewChip(api, &types.CommonCircuitData{}, &types.FriParams{})
+	xIndexWithinCosetBits := api.ToBinary(c.XIndexWithinCoset, int(c.arityBits))
+	eval := friChip.computeEvaluation(c.X, xIndexWithinCosetBits, c.arityBits, c.Evals, c.Beta)
+	friChip.gl.AssertIsEqualExtension(eval, c.ExpectedEval)
+	return nil
+}
+
+// Evaluates a polynomial with base field coefficients at an extension field point.
+func evalPolyExtension(coeffs []goldilocks.Element, x [2]goldilocks.Element) [2]goldilocks.Element {
+	w := goldilocks.NewElement(gl.W)
+	var res [2]goldilocks.Element
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		var t0, t1, tmp goldilocks.Element
+		t0.Mul(&res[0], &x[0])
+		tmp.Mul(&res[1], &x[1]).Mul(&tmp, &w)
+		t0.Add(&t0, &tmp).Add(&t0, &coeffs[i])
+		t1.Mul(&res[0], &x[1])
+		tmp.Mul(&res[1], &x[0])
+		t1.Add(&t1, &tmp)
+		res = [2]goldilocks.Element{t0, t1}
+	}
+	return res
+}
+
+func TestComputeEvaluation(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	beta := [2]goldilocks.Element{goldilocks.NewElement(1234567), goldilocks.NewElement(7654321)}
+	cosetStart := goldilocks.NewElement(987654321)
+
+	for _, arityBits := range []uint64{1, 2, 3, 4, 5} {
+		arity := 1 << arityBits
+		g := gl.PrimitiveRootOfUnity(arityBits)
+
+		// A polynomial of degree < arity, which is recovered exactly from its evaluations on the coset.
+		coeffs := make([]goldilocks.Element, arity)
+		for i := range coeffs {
+			coeffs[i].SetUint64(uint64(31*i + 17))
+		}
+
+		// The evals are the evaluations over cosetStart * g^i, in bit-reversed order.
+		evals := make([]gl.QuadraticExtensionVariable, arity)
+		for i := 0; i < arity; i++ {
+			var point goldilocks.Element
+			point.Exp(g, big.NewInt(int64(i))).Mul(&point, &cosetStart)
+			value := evalPolyExtension(coeffs, [2]goldilocks.Element{point, goldilocks.NewElement(0)})
+			revIndex := bits.Reverse64(uint64(i)) >> (64 - arityBits)
+			evals[revIndex] = gl.NewQuadraticExtensionVariable(
+				gl.NewVariableUint64(value[0].Uint64()),
+				gl.NewVariableUint64(value[1].Uint64()),
+			)
+		}
+
+		expected := evalPolyExtension(coeffs, beta)
+
+		for _, xIndexWithinCoset := range []uint64{0, uint64(arity - 1)} {
+			// x is the point of the coset that is at xIndexWithinCoset in the bit-reversed order.
+			revXIndexWithinCoset := bits.Reverse64(xIndexWithinCoset) >> (64 - arityBits)
+			var x goldilocks.Element
+			x.Exp(g, big.NewInt(int64(revXIndexWithinCoset))).Mul(&x, &cosetStart)
+
+			circuit := TestComputeEvaluationCircuit{
+				Evals:     make([]gl.QuadraticExtensionVariable, arity),
+				arityBits: arityBits,
+			}
+			witness := TestComputeEvaluationCircuit{
+				X:                 gl.NewVariableUint64(x.Uint64()),
+				XIndexWithinCoset: xIndexWithinCoset,
+				Evals:             evals,
+				Beta:              gl.NewQuadraticExtensionVariable(gl.NewVariableUint64(beta[0].Uint64()), gl.NewVariableUint64(beta[1].Uint64())),
+				ExpectedEval:      gl.NewQuadraticExtensionVariable(gl.NewVariableUint64(expected[0].Uint64()), gl.NewVariableUint64(expected[1].Uint64())),
+			}
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package fri
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/hasher"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+type TestMerkleProofToCapCircuit struct {
+	Leaves    [][]gl.Variable
+	LeafIndex frontend.Variable
+
+	treeHeight int
+	capHeight  int
+	hasherType types.HasherType
+}
+
+func (c *TestMerkleProofToCapCircuit) Define(api frontend.API) error {
+	friChip := NewChip(api, &types.CommonCircuitData{Hasher: c.hasherType}, &types.FriParams{})
+	hasherChip := hasher.New(api, c.hasherType)
+
+	// Build every layer of the tree, from the leaf digests up to the cap.
+	layers := [][]variables.HashOut{make([]variables.HashOut, len(c.Leaves))}
+	for i, leaf := range c.Leaves {
+		layers[0][i] = hasherChip.HashOrNoop(leaf)
+	}
+	for height := 0; height < c.treeHeight-c.capHeight; height++ {
+		layer := layers[height]
+		nextLayer := make([]variables.HashOut, len(layer)/2)
+		for i := range nextLayer {
+			nextLayer[i] = hasherChip.TwoToOne(layer[2*i], layer[2*i+1])
+		}
+		layers = append(layers, nextLayer)
+	}
+	merkleCap := layers[len(layers)-1]
+
+	leafIndexBits := api.ToBinary(c.LeafIndex, c.treeHeight)
+	capIndexBits := leafIndexBits[c.treeHeight-c.capHeight:]
+
+	// The sibling at each height is the node at the leaf's index (at that height) with the lowest bit flipped.
+	proof := variables.NewFriMerkleProof(c.hasherType, uint64(c.treeHeight-c.capHeight))
+	for height := range proof.Siblings {
+		layer := layers[height]
+		nodeIndexBits := leafIndexBits[height+1:]
+		for j := range proof.Siblings[height] {
+			pairs := make([]frontend.Variable, 0, len(layer)/2)
+			for i := 0; i < len(layer)/2; i++ {
+				pairs = append(pairs, api.Select(leafIndexBits[height], layer[2*i][j], layer[2*i+1][j]))
+			}
+			proof.Siblings[height][j] = muxTestHelper(api, nodeIndexBits, pairs)
+		}
+	}
+
+	leafData := make([]gl.Variable, len(c.Leaves[0]))
+	for i := range leafData {
+		values := make([]frontend.Variable, len(c.Leaves))
+		for j := range c.Leaves {
+			values[j] = c.Leaves[j][i].Limb
+		}
+		leafData[i] = gl.NewVariable(muxTestHelper(api, leafIndexBits, values))
+	}
+
+	friChip.verifyMerkleProofToCapWithCapIndex(leafData, leafIndexBits, capIndexBits, merkleCap, &proof)
+	return nil
+}
+
+// Selects values[index] where index is given by its little-endian bits.
+func muxTestHelper(api frontend.API, indexBits []frontend.Variable, values []frontend.Variable) frontend.Variable {
+	for _, bit := range indexBits {
+		nextValues := make([]frontend.Variable, len(values)/2)
+		for i := range nextValues {
+			nextValues[i] = api.Select(bit, values[2*i+1], values[2*i])
+		}
+		values = nextValues
+	}
+	return values[0]
+}
+
+func TestMerkleProofToCapWithCapIndex(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const treeHeight = 3
+	const leafSize = 5
+	leaves := make([][]gl.Variable, 1<<treeHeight)
+	for i := range leaves {
+		leaves[i] = make([]gl.Variable, leafSize)
+		for j := range leaves[i] {
+			leaves[i][j] = gl.NewVariableUint64(uint64(leafSize*i + j))
+		}
+	}
+
+	for _, hasherType := range []types.HasherType{types.PoseidonBN254Hash, types.PoseidonGoldilocksHash, types.KeccakHash} {
+		for capHeight := 0; capHeight <= treeHeight; capHeight++ {
+			for _, leafIndex := range []int{0, 5, 7} {
+				circuit := TestMerkleProofToCapCircuit{
+					Leaves:     leaves,
+					treeHeight: treeHeight,
+					capHeight:  capHeight,
+					hasherType: hasherType,
+				}
+				witness := TestMerkleProofToCapCircuit{
+					Leaves:    leaves,
+					LeafIndex: leafIndex,
+				}
+				err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+				assert.NoError(err)
+			}
+		}
+	}
+}
+
+// The emulated BN254 hasher is much slower to solve, so it is only tested on one path, over the
+// BLS12 scalar fields it is meant for.
+func TestMerkleProofToCapEmulatedBN254(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const treeHeight = 3
+	const leafSize = 5
+	leaves := make([][]gl.Variable, 1<<treeHeight)
+	for i := range leaves {
+		leaves[i] = make([]gl.Variable, leafSize)
+		for j := range leaves[i] {
+			leaves[i][j] = gl.NewVariableUint64(uint64(leafSize*i + j))
+		}
+	}
+
+	for _, curve := range []ecc.ID{ecc.BLS12_377, ecc.BLS12_381} {
+		circuit := TestMerkleProofToCapCircuit{
+			Leaves:     leaves,
+			treeHeight: treeHeight,
+			capHeight:  1,
+			hasherType: types.PoseidonBN254EmulatedHash,
+		}
+		witness := TestMerkleProofToCapCircuit{
+			Leaves:    leaves,
+			LeafIndex: 5,
+		}
+		err := test.IsSolved(&circuit, &witness, curve.ScalarField())
+		assert.NoError(err, curve.String())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/profile"
@@ -114,3 +115,25 @@ func TestGoldilocksMulAdd(t *testing.T) {
 	witness.ExpectedResult = expectedValue
 	assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254), test.NoFuzzing())
 }
+
+// The generators must be plonky2's: the LDE cosets are shifted by the multiplicative group
+// generator, and the FRI query points are powers of the power of two generator.
+func TestGenerators(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.Equal(uint64(7), MULTIPLICATIVE_GROUP_GENERATOR.Uint64())
+	assert.Equal(uint64(1753635133440165772), POWER_OF_TWO_GENERATOR.Uint64())
+
+	// POWER_OF_TWO_GENERATOR = MULTIPLICATIVE_GROUP_GENERATOR^((p - 1) / 2^TWO_ADICITY), whose
+	// order is 2^TWO_ADICITY.
+	var expected goldilocks.Element
+	exponent := new(big.Int).Rsh(new(big.Int).Sub(MODULUS, big.NewInt(1)), uint(TWO_ADICITY))
+	expected.Exp(MULTIPLICATIVE_GROUP_GENERATOR, exponent)
+	assert.True(expected.Equal(&POWER_OF_TWO_GENERATOR))
+
+	var power goldilocks.Element
+	power.Exp(POWER_OF_TWO_GENERATOR, new(big.Int).Lsh(big.NewInt(1), uint(TWO_ADICITY-1)))
+	assert.False(power.IsOne())
+	power.Square(&power)
+	assert.True(power.IsOne())
+}
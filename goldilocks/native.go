@@ -0,0 +1,228 @@
+package goldilocks
+
+import (
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+)
+
+// An element of the quadratic extension of the Goldilocks field, computed out of circuit. It
+// mirrors QuadraticExtensionVariable for the native verifier.
+type QuadraticExtension [2]goldilocks.Element
+
+func NewQuadraticExtension(x goldilocks.Element, y goldilocks.Element) QuadraticExtension {
+	return QuadraticExtension{x, y}
+}
+
+func NewQuadraticExtensionUint64(x uint64, y uint64) QuadraticExtension {
+	return QuadraticExtension{goldilocks.NewElement(x), goldilocks.NewElement(y)}
+}
+
+// Embeds a base field element in the quadratic extension.
+func ToQuadraticExtension(x goldilocks.Element) QuadraticExtension {
+	return QuadraticExtension{x, goldilocks.NewElement(0)}
+}
+
+func ZeroExtensionNative() QuadraticExtension {
+	return NewQuadraticExtensionUint64(0, 0)
+}
+
+func OneExtensionNative() QuadraticExtension {
+	return NewQuadraticExtensionUint64(1, 0)
+}
+
+func (a QuadraticExtension) Add(b QuadraticExtension) QuadraticExtension {
+	var res QuadraticExtension
+	res[0].Add(&a[0], &b[0])
+	res[1].Add(&a[1], &b[1])
+	return res
+}
+
+func (a QuadraticExtension) Sub(b QuadraticExtension) QuadraticExtension {
+	var res QuadraticExtension
+	res[0].Sub(&a[0], &b[0])
+	res[1].Sub(&a[1], &b[1])
+	return res
+}
+
+func (a QuadraticExtension) Neg() QuadraticExtension {
+	var res QuadraticExtension
+	res[0].Neg(&a[0])
+	res[1].Neg(&a[1])
+	return res
+}
+
+func (a QuadraticExtension) Mul(b QuadraticExtension) QuadraticExtension {
+	w := goldilocks.NewElement(W)
+
+	var c0, c1, tmp goldilocks.Element
+	c0.Mul(&a[0], &b[0])
+	tmp.Mul(&a[1], &b[1]).Mul(&tmp, &w)
+	c0.Add(&c0, &tmp)
+	c1.Mul(&a[0], &b[1])
+	tmp.Mul(&a[1], &b[0])
+	c1.Add(&c1, &tmp)
+	return QuadraticExtension{c0, c1}
+}
+
+func (a QuadraticExtension) Square() QuadraticExtension {
+	return a.Mul(a)
+}
+
+// Multiplies the extension element by a base field element.
+func (a QuadraticExtension) ScalarMul(b goldilocks.Element) QuadraticExtension {
+	var res QuadraticExtension
+	res[0].Mul(&a[0], &b)
+	res[1].Mul(&a[1], &b)
+	return res
+}
+
+// Computes the inverse of the extension element, which is zero for zero like in gnark-crypto.
+func (a QuadraticExtension) Inverse() QuadraticExtension {
+	// a^r with r = (p^2 - 1) / (p - 1) = p + 1 is in the base field, and a^(r - 1) = (a[0], DTH_ROOT * a[1])
+	// is the Frobenius automorphism of a.
+	dthRoot := goldilocks.NewElement(DTH_ROOT)
+	var aPowRMinus1 QuadraticExtension
+	aPowRMinus1[0] = a[0]
+	aPowRMinus1[1].Mul(&a[1], &dthRoot)
+
+	aPowR := aPowRMinus1.Mul(a)
+	var aPowRInv goldilocks.Element
+	aPowRInv.Inverse(&aPowR[0])
+	return aPowRMinus1.ScalarMul(aPowRInv)
+}
+
+func (a QuadraticExtension) Div(b QuadraticExtension) QuadraticExtension {
+	return a.Mul(b.Inverse())
+}
+
+func (a QuadraticExtension) Exp(exponent uint64) QuadraticExtension {
+	current := a
+	product := OneExtensionNative()
+
+	for i := 0; i < bits.Len64(exponent); i++ {
+		if i != 0 {
+			current = current.Square()
+		}
+		if (exponent >> i & 1) != 0 {
+			product = product.Mul(current)
+		}
+	}
+
+	return product
+}
+
+// Computes a^(2^powerLog) by repeated squaring.
+func (a QuadraticExtension) ExpPowerOf2(powerLog uint64) QuadraticExtension {
+	for i := uint64(0); i < powerLog; i++ {
+		a = a.Square()
+	}
+	return a
+}
+
+func (a QuadraticExtension) IsZero() bool {
+	return a[0].IsZero() && a[1].IsZero()
+}
+
+func (a QuadraticExtension) Equal(b QuadraticExtension) bool {
+	return a[0].Equal(&b[0]) && a[1].Equal(&b[1])
+}
+
+func (a QuadraticExtension) Uint64s() [2]uint64 {
+	return [2]uint64{a[0].Uint64(), a[1].Uint64()}
+}
+
+// Reduces a list of extension field terms with a scalar power, i.e. sum_i terms[i] * scalar^i.
+func ReduceWithPowersNative(terms []QuadraticExtension, scalar QuadraticExtension) QuadraticExtension {
+	sum := ZeroExtensionNative()
+	for i := len(terms) - 1; i >= 0; i-- {
+		sum = sum.Mul(scalar).Add(terms[i])
+	}
+	return sum
+}
+
+// An element of the extension algebra over the quadratic extension, computed out of circuit. It
+// mirrors QuadraticExtensionAlgebraVariable for the native verifier.
+type QuadraticExtensionAlgebra = [D]QuadraticExtension
+
+func ToQuadraticExtensionAlgebra(a QuadraticExtension) QuadraticExtensionAlgebra {
+	return QuadraticExtensionAlgebra{a, ZeroExtensionNative()}
+}
+
+func AddExtensionAlgebraNative(a, b QuadraticExtensionAlgebra) QuadraticExtensionAlgebra {
+	var sum QuadraticExtensionAlgebra
+	for i := 0; i < D; i++ {
+		sum[i] = a[i].Add(b[i])
+	}
+	return sum
+}
+
+func SubExtensionAlgebraNative(a, b QuadraticExtensionAlgebra) QuadraticExtensionAlgebra {
+	var diff QuadraticExtensionAlgebra
+	for i := 0; i < D; i++ {
+		diff[i] = a[i].Sub(b[i])
+	}
+	return diff
+}
+
+func MulExtensionAlgebraNative(a, b QuadraticExtensionAlgebra) QuadraticExtensionAlgebra {
+	w := goldilocks.NewElement(W)
+
+	var product QuadraticExtensionAlgebra
+	for i := 0; i < D; i++ {
+		product[i] = ZeroExtensionNative()
+	}
+	for i := 0; i < D; i++ {
+		for j := 0; j < D; j++ {
+			term := a[i].Mul(b[j])
+			if i+j >= D {
+				term = term.ScalarMul(w)
+			}
+			product[(i+j)%D] = product[(i+j)%D].Add(term)
+		}
+	}
+	return product
+}
+
+func ScalarMulExtensionAlgebraNative(a QuadraticExtension, b QuadraticExtensionAlgebra) QuadraticExtensionAlgebra {
+	var product QuadraticExtensionAlgebra
+	for i := 0; i < D; i++ {
+		product[i] = a.Mul(b[i])
+	}
+	return product
+}
+
+// Out of circuit counterpart of Chip.PartialInterpolateExtAlgebra.
+func PartialInterpolateExtAlgebraNative(
+	domain []goldilocks.Element,
+	values []QuadraticExtensionAlgebra,
+	barycentricWeights []goldilocks.Element,
+	point QuadraticExtensionAlgebra,
+	initialEval QuadraticExtensionAlgebra,
+	initialPartialProd QuadraticExtensionAlgebra,
+) (QuadraticExtensionAlgebra, QuadraticExtensionAlgebra) {
+	n := len(values)
+	if n == 0 {
+		panic("Cannot interpolate with no values")
+	}
+	if n != len(domain) {
+		panic("Domain and values must have the same length")
+	}
+	if n != len(barycentricWeights) {
+		panic("Domain and barycentric weights must have the same length")
+	}
+
+	newEval := initialEval
+	newPartialProd := initialPartialProd
+	for i := 0; i < n; i++ {
+		xQEAlgebra := ToQuadraticExtensionAlgebra(ToQuadraticExtension(domain[i]))
+		weight := ToQuadraticExtension(barycentricWeights[i])
+		term := SubExtensionAlgebraNative(point, xQEAlgebra)
+		weightedVal := ScalarMulExtensionAlgebraNative(weight, values[i])
+		newEval = MulExtensionAlgebraNative(newEval, term)
+		newEval = AddExtensionAlgebraNative(newEval, MulExtensionAlgebraNative(weightedVal, newPartialProd))
+		newPartialProd = MulExtensionAlgebraNative(newPartialProd, term)
+	}
+
+	return newEval, newPartialProd
+}
@@ -220,6 +220,28 @@ func (p *Chip) Lookup2(
 	return p.Lookup(b1, c0, c1)
 }
 
+// LookupN returns the ith qe value (0 indexed) where i is bit decomposed to indexBits (little endian).
+// The number of values must be 2^len(indexBits).
+func (p *Chip) LookupN(
+	indexBits []frontend.Variable,
+	values []QuadraticExtensionVariable,
+) QuadraticExtensionVariable {
+	if len(values) != 1<<len(indexBits) {
+		panic("LookupN expects 2^len(indexBits) values")
+	}
+
+	// Halve the candidate values with each bit, starting from the least significant one.
+	for _, bit := range indexBits {
+		nextValues := make([]QuadraticExtensionVariable, len(values)/2)
+		for i := range nextValues {
+			nextValues[i] = p.Lookup(bit, values[2*i], values[2*i+1])
+		}
+		values = nextValues
+	}
+
+	return values[0]
+}
+
 // Asserts that two quadratic extension variables are equal.
 func (p *Chip) AssertIsEqualExtension(
 	a QuadraticExtensionVariable,
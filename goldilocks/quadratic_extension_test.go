@@ -92,3 +92,41 @@ func TestQuadraticExtensionDiv(t *testing.T) {
 	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
 	assert.NoError(err)
 }
+
+type TestQuadraticExtensionLookupNCircuit struct {
+	Index          frontend.Variable
+	Values         []QuadraticExtensionVariable
+	ExpectedResult QuadraticExtensionVariable
+
+	nbBits int
+}
+
+func (c *TestQuadraticExtensionLookupNCircuit) Define(api frontend.API) error {
+	glApi := New(api)
+	actualRes := glApi.LookupN(api.ToBinary(c.Index, c.nbBits), c.Values)
+	glApi.AssertIsEqualExtension(actualRes, c.ExpectedResult)
+	return nil
+}
+
+func TestQuadraticExtensionLookupN(t *testing.T) {
+	assert := test.NewAssert(t)
+	for nbBits := 0; nbBits <= 3; nbBits++ {
+		values := make([]QuadraticExtensionVariable, 1<<nbBits)
+		for i := range values {
+			values[i] = QuadraticExtensionVariable{NewVariable(2 * i), NewVariable(2*i + 1)}
+		}
+		for index := 0; index < len(values); index++ {
+			circuit := TestQuadraticExtensionLookupNCircuit{
+				Values: make([]QuadraticExtensionVariable, len(values)),
+				nbBits: nbBits,
+			}
+			witness := TestQuadraticExtensionLookupNCircuit{
+				Index:          index,
+				Values:         values,
+				ExpectedResult: values[index],
+			}
+			err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}
+	}
+}
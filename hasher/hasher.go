@@ -0,0 +1,43 @@
+package hasher
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// The in-circuit counterpart of plonky2's Hasher trait. It hashes the leaves and nodes of the
+// proof's Merkle trees and provides the permutation of the challenger's sponge.
+type Hasher interface {
+	// Hashes the leaf data, or returns it padded with zeros if it fits in a digest.
+	HashOrNoop(input []gl.Variable) variables.HashOut
+	// Hashes the input, whatever its length, like plonky2's hash_no_pad.
+	HashNoPad(input []gl.Variable) variables.HashOut
+	// Hashes two sibling nodes into their parent node.
+	TwoToOne(left variables.HashOut, right variables.HashOut) variables.HashOut
+	// Returns the Goldilocks elements the challenger observes for the digest.
+	ToVec(hash variables.HashOut) []gl.Variable
+	// The permutation of the challenger's sponge.
+	Permute(state poseidon.GoldilocksState) poseidon.GoldilocksState
+	// Range checks a digest that is part of the witness.
+	RangeCheck(hash variables.HashOut)
+}
+
+func New(api frontend.API, hasherType types.HasherType) Hasher {
+	switch hasherType {
+	case types.PoseidonBN254Hash:
+		return NewPoseidonBN254Hasher(api)
+	case types.PoseidonGoldilocksHash:
+		return NewPoseidonGoldilocksHasher(api)
+	case types.KeccakHash:
+		return NewKeccakHasher(api)
+	case types.PoseidonBN254EmulatedHash:
+		return NewPoseidonBN254EmulatedHasher(api)
+	default:
+		panic(fmt.Sprintf("unknown hasher type %d", hasherType))
+	}
+}
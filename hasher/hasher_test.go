@@ -0,0 +1,112 @@
+package hasher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+type TestPoseidonGoldilocksHasherCircuit struct {
+	Left     [4]frontend.Variable
+	Right    [4]frontend.Variable
+	Expected [4]frontend.Variable
+}
+
+func (circuit *TestPoseidonGoldilocksHasherCircuit) Define(api frontend.API) error {
+	hasher := NewPoseidonGoldilocksHasher(api)
+
+	output := hasher.TwoToOne(variables.HashOut(circuit.Left[:]), variables.HashOut(circuit.Right[:]))
+	for i := range output {
+		api.AssertIsEqual(output[i], circuit.Expected[i])
+	}
+
+	// Leaves that fit in a digest are padded with zeros instead of being hashed.
+	leaf := []gl.Variable{gl.NewVariable(circuit.Left[0]), gl.NewVariable(circuit.Left[1])}
+	digest := hasher.HashOrNoop(leaf)
+	api.AssertIsEqual(digest[0], circuit.Left[0])
+	api.AssertIsEqual(digest[1], circuit.Left[1])
+	api.AssertIsEqual(digest[2], 0)
+	api.AssertIsEqual(digest[3], 0)
+
+	return nil
+}
+
+func TestPoseidonGoldilocksHasherTwoToOne(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Compressing two zero digests permutes the all-zero state, so the expected digest is the
+	// first four elements of the Poseidon permutation of zero.
+	circuit := TestPoseidonGoldilocksHasherCircuit{}
+	witness := TestPoseidonGoldilocksHasherCircuit{
+		Left:  [4]frontend.Variable{0, 0, 0, 0},
+		Right: [4]frontend.Variable{0, 0, 0, 0},
+		Expected: [4]frontend.Variable{
+			"4330397376401421145", "14124799381142128323", "8742572140681234676", "14345658006221440202",
+		},
+	}
+	// The Goldilocks hasher doesn't depend on the scalar field of the circuit.
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381} {
+		err := test.IsSolved(&circuit, &witness, curve.ScalarField())
+		assert.NoError(err, curve.String())
+	}
+}
+
+type TestPoseidonBN254EmulatedHasherCircuit struct {
+	Leaf     []gl.Variable
+	Right    variables.HashOut
+	Expected variables.HashOut
+}
+
+func (circuit *TestPoseidonBN254EmulatedHasherCircuit) Define(api frontend.API) error {
+	hasher := NewPoseidonBN254EmulatedHasher(api)
+
+	hasher.RangeCheck(circuit.Right)
+	output := hasher.TwoToOne(hasher.HashOrNoop(circuit.Leaf), circuit.Right)
+	for i := range output {
+		api.AssertIsEqual(output[i], circuit.Expected[i])
+	}
+
+	return nil
+}
+
+// The emulated hasher computes the limbs of the digests of the native PoseidonBN254 hasher.
+func TestPoseidonBN254EmulatedHasher(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	leaf := make([]goldilocks.Element, 7)
+	for i := range leaf {
+		leaf[i] = goldilocks.NewElement(uint64(i+1) << 40)
+	}
+	right := poseidon.HashOrNoopBN254Native(leaf[:2])
+	expected := poseidon.TwoToOneBN254Native(poseidon.HashOrNoopBN254Native(leaf), right)
+
+	toHashOut := func(element fr.Element) variables.HashOut {
+		var value big.Int
+		element.BigInt(&value)
+		return variables.EmulateBN254HashOut(variables.HashOut{&value})
+	}
+
+	circuit := TestPoseidonBN254EmulatedHasherCircuit{
+		Leaf:     make([]gl.Variable, len(leaf)),
+		Right:    make(variables.HashOut, 4),
+		Expected: make(variables.HashOut, 4),
+	}
+	witness := TestPoseidonBN254EmulatedHasherCircuit{
+		Leaf:     make([]gl.Variable, len(leaf)),
+		Right:    toHashOut(right),
+		Expected: toHashOut(expected),
+	}
+	for i := range leaf {
+		witness.Leaf[i] = gl.NewVariable(leaf[i].Uint64())
+	}
+	err := test.IsSolved(&circuit, &witness, ecc.BLS12_377.ScalarField())
+	assert.NoError(err)
+}
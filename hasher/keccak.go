@@ -0,0 +1,146 @@
+package hasher
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/sha3"
+	"github.com/consensys/gnark/std/math/uints"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// The number of bytes of a KeccakHash<25> digest.
+const KECCAK_HASH_SIZE = 25
+
+// The number of bytes of a digest converted to a single Goldilocks element by ToVec.
+const KECCAK_BYTES_PER_ELEMENT = 7
+
+// Hashes the Merkle trees with Keccak-256 truncated to 25 bytes. Each variable of a digest holds a
+// single byte. The challenger uses plonky2's KeccakPermutation.
+type KeccakHasher struct {
+	api   frontend.API                  `gnark:"-"`
+	gl    *gl.Chip                      `gnark:"-"`
+	bytes *uints.Bytes                  `gnark:"-"`
+	u64   *uints.BinaryField[uints.U64] `gnark:"-"`
+}
+
+func NewKeccakHasher(api frontend.API) *KeccakHasher {
+	bytes, err := uints.NewBytes(api)
+	if err != nil {
+		panic(err)
+	}
+
+	u64, err := uints.New[uints.U64](api)
+	if err != nil {
+		panic(err)
+	}
+
+	return &KeccakHasher{api: api, gl: gl.New(api), bytes: bytes, u64: u64}
+}
+
+// The input elements can be outside of the Goldilocks field.
+func (h *KeccakHasher) HashOrNoop(input []gl.Variable) variables.HashOut {
+	inputBytes := h.inputToBytes(input)
+	if len(inputBytes) > KECCAK_HASH_SIZE {
+		return h.keccak(inputBytes)
+	}
+
+	for len(inputBytes) < KECCAK_HASH_SIZE {
+		inputBytes = append(inputBytes, uints.NewU8(0))
+	}
+	return h.fromBytes(inputBytes)
+}
+
+// The input elements can be outside of the Goldilocks field.
+func (h *KeccakHasher) HashNoPad(input []gl.Variable) variables.HashOut {
+	return h.keccak(h.inputToBytes(input))
+}
+
+// Returns the little-endian bytes of the reduced input elements.
+func (h *KeccakHasher) inputToBytes(input []gl.Variable) []uints.U8 {
+	inputBytes := make([]uints.U8, 0, 8*len(input))
+	for _, element := range input {
+		inputBytes = append(inputBytes, h.elementToBytes(h.gl.Reduce(element))...)
+	}
+	return inputBytes
+}
+
+func (h *KeccakHasher) TwoToOne(left variables.HashOut, right variables.HashOut) variables.HashOut {
+	return h.keccak(append(h.toBytes(left), h.toBytes(right)...))
+}
+
+// Packs the digest into little-endian chunks of 7 bytes, the way plonky2's BytesHash does.
+func (h *KeccakHasher) ToVec(hash variables.HashOut) []gl.Variable {
+	elements := []gl.Variable{}
+	for i := 0; i < len(hash); i += KECCAK_BYTES_PER_ELEMENT {
+		element := frontend.Variable(0)
+		for j := i; j < len(hash) && j < i+KECCAK_BYTES_PER_ELEMENT; j++ {
+			element = h.api.MulAcc(element, hash[j], uint64(1)<<(8*(j-i)))
+		}
+		elements = append(elements, gl.NewVariable(element))
+	}
+	return elements
+}
+
+// plonky2's KeccakPermutation: the state is serialized as little-endian words and repeatedly hashed,
+// and the output state is read from the resulting little-endian words. plonky2 skips the words
+// that aren't canonical Goldilocks elements, which can't be done in-circuit, so instead this
+// asserts that no word is skipped. That only fails with a probability of about 2^-32 per word.
+//
+// The input state MUST have all its elements be within Goldilocks field.
+func (h *KeccakHasher) Permute(state poseidon.GoldilocksState) poseidon.GoldilocksState {
+	digest := make([]uints.U8, 0, 8*poseidon.SPONGE_WIDTH)
+	for _, element := range state {
+		digest = append(digest, h.elementToBytes(element)...)
+	}
+
+	var output poseidon.GoldilocksState
+	for i := 0; i < poseidon.SPONGE_WIDTH; {
+		digest = h.keccak256(digest)
+		for j := 0; j+8 <= len(digest) && i < poseidon.SPONGE_WIDTH; j, i = j+8, i+1 {
+			output[i] = gl.NewVariable(h.u64.ToValue(h.u64.PackLSB(digest[j : j+8]...)))
+			h.gl.RangeCheck(output[i])
+		}
+	}
+
+	return output
+}
+
+func (h *KeccakHasher) RangeCheck(hash variables.HashOut) {
+	h.toBytes(hash)
+}
+
+func (h *KeccakHasher) keccak(input []uints.U8) variables.HashOut {
+	return h.fromBytes(h.keccak256(input)[:KECCAK_HASH_SIZE])
+}
+
+func (h *KeccakHasher) keccak256(input []uints.U8) []uints.U8 {
+	keccak, err := sha3.NewLegacyKeccak256(h.api)
+	if err != nil {
+		panic(err)
+	}
+	keccak.Write(input)
+	return keccak.Sum()
+}
+
+// Returns the little-endian bytes of x, which MUST be within Goldilocks field.
+func (h *KeccakHasher) elementToBytes(x gl.Variable) []uints.U8 {
+	return h.u64.UnpackLSB(h.u64.ValueOf(x.Limb))
+}
+
+// Converts the digest's variables into range checked bytes.
+func (h *KeccakHasher) toBytes(hash variables.HashOut) []uints.U8 {
+	hashBytes := make([]uints.U8, len(hash))
+	for i := range hash {
+		hashBytes[i] = h.bytes.ValueOf(hash[i])
+	}
+	return hashBytes
+}
+
+func (h *KeccakHasher) fromBytes(hashBytes []uints.U8) variables.HashOut {
+	hash := make(variables.HashOut, len(hashBytes))
+	for i := range hashBytes {
+		hash[i] = h.bytes.Value(hashBytes[i])
+	}
+	return hash
+}
@@ -0,0 +1,161 @@
+package hasher
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+	"golang.org/x/crypto/sha3"
+)
+
+type TestKeccakHasherCircuit struct {
+	Leaf          []frontend.Variable
+	ExpectedLeaf  []frontend.Variable
+	ExpectedHash  []frontend.Variable
+	Left          []frontend.Variable
+	Right         []frontend.Variable
+	ExpectedNode  []frontend.Variable
+	ExpectedVec   []frontend.Variable
+	State         [poseidon.SPONGE_WIDTH]frontend.Variable
+	ExpectedState [poseidon.SPONGE_WIDTH]frontend.Variable
+}
+
+func (circuit *TestKeccakHasherCircuit) Define(api frontend.API) error {
+	hasher := NewKeccakHasher(api)
+
+	leaf := make([]gl.Variable, len(circuit.Leaf))
+	for i := range leaf {
+		leaf[i] = gl.NewVariable(circuit.Leaf[i])
+	}
+	assertEqual(api, hasher.HashOrNoop(leaf), circuit.ExpectedLeaf)
+	assertEqual(api, hasher.HashNoPad(leaf), circuit.ExpectedHash)
+
+	node := hasher.TwoToOne(variables.HashOut(circuit.Left), variables.HashOut(circuit.Right))
+	assertEqual(api, node, circuit.ExpectedNode)
+
+	vec := hasher.ToVec(node)
+	for i := range vec {
+		api.AssertIsEqual(vec[i].Limb, circuit.ExpectedVec[i])
+	}
+
+	var state poseidon.GoldilocksState
+	for i := range state {
+		state[i] = gl.NewVariable(circuit.State[i])
+	}
+	state = hasher.Permute(state)
+	for i := range state {
+		api.AssertIsEqual(state[i].Limb, circuit.ExpectedState[i])
+	}
+
+	return nil
+}
+
+func assertEqual(api frontend.API, actual variables.HashOut, expected []frontend.Variable) {
+	if len(actual) != len(expected) {
+		panic("digests have different lengths")
+	}
+	for i := range actual {
+		api.AssertIsEqual(actual[i], expected[i])
+	}
+}
+
+// Native version of plonky2's KeccakHash<25>.
+func keccak(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func elementsToBytes(elements []uint64) []byte {
+	data := make([]byte, 8*len(elements))
+	for i, element := range elements {
+		binary.LittleEndian.PutUint64(data[8*i:], element)
+	}
+	return data
+}
+
+func keccakHashOrNoop(elements []uint64) []byte {
+	data := elementsToBytes(elements)
+	if len(data) <= KECCAK_HASH_SIZE {
+		return append(data, make([]byte, KECCAK_HASH_SIZE-len(data))...)
+	}
+	return keccak(data)[:KECCAK_HASH_SIZE]
+}
+
+func keccakToVec(hash []byte) []uint64 {
+	elements := []uint64{}
+	for i := 0; i < len(hash); i += KECCAK_BYTES_PER_ELEMENT {
+		var chunk [8]byte
+		copy(chunk[:], hash[i:min(len(hash), i+KECCAK_BYTES_PER_ELEMENT)])
+		elements = append(elements, binary.LittleEndian.Uint64(chunk[:]))
+	}
+	return elements
+}
+
+func keccakPermute(state []uint64) []uint64 {
+	digest := elementsToBytes(state)
+	output := []uint64{}
+	for len(output) < poseidon.SPONGE_WIDTH {
+		digest = keccak(digest)
+		for j := 0; j < len(digest) && len(output) < poseidon.SPONGE_WIDTH; j += 8 {
+			word := binary.LittleEndian.Uint64(digest[j:])
+			if word < gl.MODULUS_UINT64 {
+				output = append(output, word)
+			}
+		}
+	}
+	return output
+}
+
+func toVariables[T byte | uint64](values []T) []frontend.Variable {
+	variables := make([]frontend.Variable, len(values))
+	for i := range values {
+		variables[i] = values[i]
+	}
+	return variables
+}
+
+func TestKeccakHasher(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	left := keccakHashOrNoop([]uint64{1, 2, 3})
+	right := keccakHashOrNoop([]uint64{4, 5, 6, 7})
+	node := keccak(append(append([]byte{}, left...), right...))[:KECCAK_HASH_SIZE]
+
+	state := make([]uint64, poseidon.SPONGE_WIDTH)
+	for i := range state {
+		state[i] = uint64(i) * 0x0123456789abcdef % gl.MODULUS_UINT64
+	}
+
+	for _, leaf := range [][]uint64{{1, 2, 3}, {4, 5, 6, 7}} {
+		circuit := TestKeccakHasherCircuit{
+			Leaf:         make([]frontend.Variable, len(leaf)),
+			ExpectedLeaf: make([]frontend.Variable, KECCAK_HASH_SIZE),
+			ExpectedHash: make([]frontend.Variable, KECCAK_HASH_SIZE),
+			Left:         make([]frontend.Variable, KECCAK_HASH_SIZE),
+			Right:        make([]frontend.Variable, KECCAK_HASH_SIZE),
+			ExpectedNode: make([]frontend.Variable, KECCAK_HASH_SIZE),
+			ExpectedVec:  make([]frontend.Variable, 4),
+		}
+		witness := TestKeccakHasherCircuit{
+			Leaf:         toVariables(leaf),
+			ExpectedLeaf: toVariables(keccakHashOrNoop(leaf)),
+			// Hashed even when the leaf fits in a digest.
+			ExpectedHash: toVariables(keccak(elementsToBytes(leaf))[:KECCAK_HASH_SIZE]),
+			Left:         toVariables(left),
+			Right:        toVariables(right),
+			ExpectedNode: toVariables(node),
+			ExpectedVec:  toVariables(keccakToVec(node)),
+		}
+		copy(witness.State[:], toVariables(state))
+		copy(witness.ExpectedState[:], toVariables(keccakPermute(state)))
+
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+}
@@ -0,0 +1,45 @@
+package hasher
+
+import (
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// Hashes the Merkle trees with Poseidon over BN254, whose digests are a single BN254 element. The
+// challenger still uses the Goldilocks Poseidon permutation.
+type PoseidonBN254Hasher struct {
+	poseidonBN254Chip *poseidon.BN254Chip      `gnark:"-"`
+	poseidonGlChip    *poseidon.GoldilocksChip `gnark:"-"`
+}
+
+func NewPoseidonBN254Hasher(api frontend.API) *PoseidonBN254Hasher {
+	return &PoseidonBN254Hasher{
+		poseidonBN254Chip: poseidon.NewBN254Chip(api),
+		poseidonGlChip:    poseidon.NewGoldilocksChip(api),
+	}
+}
+
+func (h *PoseidonBN254Hasher) HashOrNoop(input []gl.Variable) variables.HashOut {
+	return variables.HashOut{h.poseidonBN254Chip.HashOrNoop(input)}
+}
+
+func (h *PoseidonBN254Hasher) HashNoPad(input []gl.Variable) variables.HashOut {
+	return variables.HashOut{h.poseidonBN254Chip.HashNoPad(input)}
+}
+
+func (h *PoseidonBN254Hasher) TwoToOne(left variables.HashOut, right variables.HashOut) variables.HashOut {
+	return variables.HashOut{h.poseidonBN254Chip.TwoToOne(left[0], right[0])}
+}
+
+func (h *PoseidonBN254Hasher) ToVec(hash variables.HashOut) []gl.Variable {
+	return h.poseidonBN254Chip.ToVec(hash[0])
+}
+
+func (h *PoseidonBN254Hasher) Permute(state poseidon.GoldilocksState) poseidon.GoldilocksState {
+	return h.poseidonGlChip.Poseidon(state)
+}
+
+// BN254 digests are native field elements, so there is nothing to check.
+func (h *PoseidonBN254Hasher) RangeCheck(hash variables.HashOut) {}
@@ -0,0 +1,68 @@
+package hasher
+
+import (
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// Hashes the Merkle trees with Poseidon over an emulated BN254 field, for circuits over other
+// scalar fields. Its digests are the four 64 bit limbs of the canonical BN254 element, least
+// significant first. The challenger still uses the Goldilocks Poseidon permutation.
+type PoseidonBN254EmulatedHasher struct {
+	poseidonBN254Chip *poseidon.BN254EmulatedChip `gnark:"-"`
+	poseidonGlChip    *poseidon.GoldilocksChip    `gnark:"-"`
+}
+
+func NewPoseidonBN254EmulatedHasher(api frontend.API) *PoseidonBN254EmulatedHasher {
+	return &PoseidonBN254EmulatedHasher{
+		poseidonBN254Chip: poseidon.NewBN254EmulatedChip(api),
+		poseidonGlChip:    poseidon.NewGoldilocksChip(api),
+	}
+}
+
+func (h *PoseidonBN254EmulatedHasher) HashOrNoop(input []gl.Variable) variables.HashOut {
+	return h.fromElement(h.poseidonBN254Chip.HashOrNoop(input))
+}
+
+func (h *PoseidonBN254EmulatedHasher) HashNoPad(input []gl.Variable) variables.HashOut {
+	return h.fromElement(h.poseidonBN254Chip.HashNoPad(input))
+}
+
+func (h *PoseidonBN254EmulatedHasher) TwoToOne(left variables.HashOut, right variables.HashOut) variables.HashOut {
+	return h.fromElement(h.poseidonBN254Chip.TwoToOne(h.toElement(left), h.toElement(right)))
+}
+
+func (h *PoseidonBN254EmulatedHasher) ToVec(hash variables.HashOut) []gl.Variable {
+	return h.poseidonBN254Chip.ToVec(h.toElement(hash))
+}
+
+func (h *PoseidonBN254EmulatedHasher) Permute(state poseidon.GoldilocksState) poseidon.GoldilocksState {
+	return h.poseidonGlChip.Poseidon(state)
+}
+
+// Checks that the limbs are 64 bits wide and that the digest is a canonical BN254 element, which
+// the Merkle proofs compare limb by limb.
+func (h *PoseidonBN254EmulatedHasher) RangeCheck(hash variables.HashOut) {
+	h.poseidonBN254Chip.Field().AssertIsInRange(h.toElement(hash))
+}
+
+// Creates the emulated element of the limbs, constraining their widths.
+func (h *PoseidonBN254EmulatedHasher) toElement(hash variables.HashOut) poseidon.BN254EmulatedElement {
+	return h.poseidonBN254Chip.Field().NewElement([]frontend.Variable(hash))
+}
+
+// Returns the limbs of the canonical representation of element.
+func (h *PoseidonBN254EmulatedHasher) fromElement(element poseidon.BN254EmulatedElement) variables.HashOut {
+	reduced := h.poseidonBN254Chip.Field().ReduceStrict(element)
+	hash := make(variables.HashOut, 4)
+	for i := range hash {
+		if i < len(reduced.Limbs) {
+			hash[i] = reduced.Limbs[i]
+		} else {
+			hash[i] = frontend.Variable(0)
+		}
+	}
+	return hash
+}
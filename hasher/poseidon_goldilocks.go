@@ -0,0 +1,85 @@
+package hasher
+
+import (
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// Hashes the Merkle trees with Poseidon over Goldilocks, whose digests are four Goldilocks elements.
+type PoseidonGoldilocksHasher struct {
+	gl             *gl.Chip                 `gnark:"-"`
+	poseidonGlChip *poseidon.GoldilocksChip `gnark:"-"`
+}
+
+func NewPoseidonGoldilocksHasher(api frontend.API) *PoseidonGoldilocksHasher {
+	return &PoseidonGoldilocksHasher{
+		gl:             gl.New(api),
+		poseidonGlChip: poseidon.NewGoldilocksChip(api),
+	}
+}
+
+// The input elements can be outside of the Goldilocks field.
+func (h *PoseidonGoldilocksHasher) HashOrNoop(input []gl.Variable) variables.HashOut {
+	if len(input) > poseidon.POSEIDON_GL_HASH_SIZE {
+		return h.HashNoPad(input)
+	}
+
+	elements := make([]gl.Variable, poseidon.POSEIDON_GL_HASH_SIZE)
+	for i := range elements {
+		if i < len(input) {
+			elements[i] = h.gl.Reduce(input[i])
+		} else {
+			elements[i] = gl.Zero()
+		}
+	}
+	return fromGoldilocks(elements)
+}
+
+// The input elements can be outside of the Goldilocks field.
+func (h *PoseidonGoldilocksHasher) HashNoPad(input []gl.Variable) variables.HashOut {
+	hash := h.poseidonGlChip.HashNoPad(input)
+	return fromGoldilocks(hash[:])
+}
+
+// Both digests MUST have their elements within the Goldilocks field.
+func (h *PoseidonGoldilocksHasher) TwoToOne(left variables.HashOut, right variables.HashOut) variables.HashOut {
+	var state poseidon.GoldilocksState
+	for i := range state {
+		state[i] = gl.Zero()
+	}
+	for i := 0; i < poseidon.POSEIDON_GL_HASH_SIZE; i++ {
+		state[i] = gl.NewVariable(left[i])
+		state[poseidon.POSEIDON_GL_HASH_SIZE+i] = gl.NewVariable(right[i])
+	}
+
+	state = h.poseidonGlChip.Poseidon(state)
+	return fromGoldilocks(state[:poseidon.POSEIDON_GL_HASH_SIZE])
+}
+
+func (h *PoseidonGoldilocksHasher) ToVec(hash variables.HashOut) []gl.Variable {
+	elements := make([]gl.Variable, len(hash))
+	for i := range hash {
+		elements[i] = gl.NewVariable(hash[i])
+	}
+	return elements
+}
+
+func (h *PoseidonGoldilocksHasher) Permute(state poseidon.GoldilocksState) poseidon.GoldilocksState {
+	return h.poseidonGlChip.Poseidon(state)
+}
+
+func (h *PoseidonGoldilocksHasher) RangeCheck(hash variables.HashOut) {
+	for _, element := range hash {
+		h.gl.RangeCheck(gl.NewVariable(element))
+	}
+}
+
+func fromGoldilocks(elements []gl.Variable) variables.HashOut {
+	hash := make(variables.HashOut, len(elements))
+	for i := range elements {
+		hash[i] = elements[i].Limb
+	}
+	return hash
+}
@@ -0,0 +1,141 @@
+package native
+
+import (
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// The out of circuit counterpart of challenger.Chip.
+type Challenger struct {
+	hasher       Hasher
+	spongeState  poseidon.GoldilocksStateNative
+	inputBuffer  []goldilocks.Element
+	outputBuffer []goldilocks.Element
+}
+
+// Creates a challenger whose sponge and observed Merkle digests follow the given hasher.
+func NewChallenger(hasher Hasher) *Challenger {
+	return &Challenger{hasher: hasher}
+}
+
+func (c *Challenger) ObserveElement(element goldilocks.Element) {
+	// Clear the output buffer
+	c.outputBuffer = c.outputBuffer[:0]
+	c.inputBuffer = append(c.inputBuffer, element)
+	if len(c.inputBuffer) == poseidon.SPONGE_RATE {
+		c.duplexing()
+	}
+}
+
+func (c *Challenger) ObserveElements(elements []goldilocks.Element) {
+	for _, element := range elements {
+		c.ObserveElement(element)
+	}
+}
+
+func (c *Challenger) ObserveHash(hash poseidon.GoldilocksHashOutNative) {
+	c.ObserveElements(hash[:])
+}
+
+// Observes a Merkle digest (or the circuit digest) produced by the challenger's hasher.
+func (c *Challenger) ObserveDigest(hash HashOut) {
+	c.ObserveElements(c.hasher.ToVec(hash))
+}
+
+func (c *Challenger) ObserveCap(cap FriMerkleCap) {
+	for _, hash := range cap {
+		c.ObserveDigest(hash)
+	}
+}
+
+// Observes the FRI reduction strategy the way plonky2 does: a tag identifying the strategy
+// followed by the strategy's parameters.
+func (c *Challenger) ObserveFriReductionStrategy(strategy types.FriReductionStrategy) {
+	c.ObserveElement(goldilocks.NewElement(uint64(strategy.Kind)))
+	for _, parameter := range strategy.Parameters() {
+		c.ObserveElement(goldilocks.NewElement(parameter))
+	}
+}
+
+func (c *Challenger) ObserveExtensionElement(element gl.QuadraticExtension) {
+	c.ObserveElements(element[:])
+}
+
+func (c *Challenger) ObserveExtensionElements(elements []gl.QuadraticExtension) {
+	for _, element := range elements {
+		c.ObserveExtensionElement(element)
+	}
+}
+
+func (c *Challenger) ObserveOpenings(openings [][]gl.QuadraticExtension) {
+	for _, batch := range openings {
+		c.ObserveExtensionElements(batch)
+	}
+}
+
+func (c *Challenger) GetChallenge() goldilocks.Element {
+	if len(c.inputBuffer) != 0 || len(c.outputBuffer) == 0 {
+		c.duplexing()
+	}
+
+	challenge := c.outputBuffer[len(c.outputBuffer)-1]
+	c.outputBuffer = c.outputBuffer[:len(c.outputBuffer)-1]
+
+	return challenge
+}
+
+func (c *Challenger) GetNChallenges(n uint64) []goldilocks.Element {
+	challenges := make([]goldilocks.Element, n)
+	for i := range challenges {
+		challenges[i] = c.GetChallenge()
+	}
+	return challenges
+}
+
+func (c *Challenger) GetExtensionChallenge() gl.QuadraticExtension {
+	values := c.GetNChallenges(2)
+	return gl.NewQuadraticExtension(values[0], values[1])
+}
+
+func (c *Challenger) GetFriChallenges(
+	commitPhaseMerkleCaps []FriMerkleCap,
+	finalPoly []gl.QuadraticExtension,
+	powWitness goldilocks.Element,
+	config types.FriConfig,
+) FriChallenges {
+	friAlpha := c.GetExtensionChallenge()
+
+	var friBetas []gl.QuadraticExtension
+	for _, cap := range commitPhaseMerkleCaps {
+		c.ObserveCap(cap)
+		friBetas = append(friBetas, c.GetExtensionChallenge())
+	}
+
+	c.ObserveExtensionElements(finalPoly)
+	c.ObserveElement(powWitness)
+
+	friPowResponse := c.GetChallenge()
+	friQueryIndices := c.GetNChallenges(config.NumQueryRounds)
+
+	return FriChallenges{
+		FriAlpha:        friAlpha,
+		FriBetas:        friBetas,
+		FriPowResponse:  friPowResponse,
+		FriQueryIndices: friQueryIndices,
+	}
+}
+
+func (c *Challenger) duplexing() {
+	if len(c.inputBuffer) > poseidon.SPONGE_RATE {
+		panic("input buffer exceeds the sponge rate")
+	}
+
+	copy(c.spongeState[:], c.inputBuffer)
+	// Clear the input buffer
+	c.inputBuffer = c.inputBuffer[:0]
+	c.spongeState = c.hasher.Permute(c.spongeState)
+
+	c.outputBuffer = append(c.outputBuffer[:0], c.spongeState[:poseidon.SPONGE_RATE]...)
+}
@@ -0,0 +1,115 @@
+package native
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+func elementsToUint64s(elements []goldilocks.Element) []uint64 {
+	res := make([]uint64, len(elements))
+	for i := range elements {
+		res[i] = elements[i].Uint64()
+	}
+	return res
+}
+
+func extensionToUint64s(extension gl.QuadraticExtension) []uint64 {
+	return elementsToUint64s(extension[:])
+}
+
+// Converts the challenges to the layout plonky2 serializes its ProofChallenges with.
+func (c ProofChallenges) ToRaw() types.ProofChallengesRaw {
+	var raw types.ProofChallengesRaw
+	raw.PlonkBetas = elementsToUint64s(c.PlonkBetas)
+	raw.PlonkGammas = elementsToUint64s(c.PlonkGammas)
+	raw.PlonkAlphas = elementsToUint64s(c.PlonkAlphas)
+	raw.PlonkDeltas = elementsToUint64s(c.PlonkDeltas)
+	raw.PlonkZeta = extensionToUint64s(c.PlonkZeta)
+
+	raw.FriChallenges.FriAlpha = extensionToUint64s(c.FriChallenges.FriAlpha)
+	raw.FriChallenges.FriBetas = make([][]uint64, len(c.FriChallenges.FriBetas))
+	for i, beta := range c.FriChallenges.FriBetas {
+		raw.FriChallenges.FriBetas[i] = extensionToUint64s(beta)
+	}
+	raw.FriChallenges.FriPowResponse = c.FriChallenges.FriPowResponse.Uint64()
+	raw.FriChallenges.FriQueryIndices = elementsToUint64s(c.FriChallenges.FriQueryIndices)
+	return raw
+}
+
+// Computes the challenges the verifier draws for a deserialized proof, e.g. to compare them with
+// the challenges plonky2 computes for the same proof.
+func GetProofChallenges(
+	proofWithPis types.ProofWithPublicInputsRaw,
+	verifierData types.VerifierOnlyCircuitDataRaw,
+	commonData types.CommonCircuitData,
+) (types.ProofChallengesRaw, error) {
+	verifier, err := NewVerifier(commonData)
+	if err != nil {
+		return types.ProofChallengesRaw{}, err
+	}
+
+	nativeProofWithPis, err := NewProofWithPublicInputs(verifier.hasher, proofWithPis)
+	if err != nil {
+		return types.ProofChallengesRaw{}, fmt.Errorf("invalid proof: %w", err)
+	}
+	nativeVerifierData, err := NewVerifierOnlyCircuitData(verifier.hasher, verifierData)
+	if err != nil {
+		return types.ProofChallengesRaw{}, fmt.Errorf("invalid verifier data: %w", err)
+	}
+
+	publicInputsHash := verifier.GetPublicInputsHash(nativeProofWithPis.PublicInputs)
+	challenges := verifier.GetChallenges(nativeProofWithPis.Proof, publicInputsHash, nativeVerifierData)
+	return challenges.ToRaw(), nil
+}
+
+// Compares computed challenges with the expected ones, returning an error listing the first
+// mismatch of every challenge that differs. As each challenge depends on everything observed before
+// it, the first mismatching challenge locates where the transcripts diverge.
+func CompareProofChallenges(expected types.ProofChallengesRaw, actual types.ProofChallengesRaw) error {
+	errs := []error{
+		compareChallenges("plonk_betas", expected.PlonkBetas, actual.PlonkBetas),
+		compareChallenges("plonk_gammas", expected.PlonkGammas, actual.PlonkGammas),
+		compareChallenges("plonk_deltas", expected.PlonkDeltas, actual.PlonkDeltas),
+		compareChallenges("plonk_alphas", expected.PlonkAlphas, actual.PlonkAlphas),
+		compareChallenges("plonk_zeta", expected.PlonkZeta, actual.PlonkZeta),
+		compareChallenges("fri_alpha", expected.FriChallenges.FriAlpha, actual.FriChallenges.FriAlpha),
+	}
+
+	expectedBetas := expected.FriChallenges.FriBetas
+	actualBetas := actual.FriChallenges.FriBetas
+	if len(expectedBetas) != len(actualBetas) {
+		errs = append(errs, fmt.Errorf("fri_betas: expected %d challenges, got %d", len(expectedBetas), len(actualBetas)))
+	} else {
+		for i := range expectedBetas {
+			errs = append(errs, compareChallenges(fmt.Sprintf("fri_betas[%d]", i), expectedBetas[i], actualBetas[i]))
+		}
+	}
+
+	errs = append(
+		errs,
+		compareChallenges(
+			"fri_pow_response",
+			[]uint64{expected.FriChallenges.FriPowResponse},
+			[]uint64{actual.FriChallenges.FriPowResponse},
+		),
+		compareChallenges("fri_query_indices", expected.FriChallenges.FriQueryIndices, actual.FriChallenges.FriQueryIndices),
+	)
+
+	return errors.Join(errs...)
+}
+
+func compareChallenges(name string, expected []uint64, actual []uint64) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("%s: expected %d challenges, got %d", name, len(expected), len(actual))
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			return fmt.Errorf("%s[%d]: expected %d, got %d", name, i, expected[i], actual[i])
+		}
+	}
+	return nil
+}
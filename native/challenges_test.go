@@ -0,0 +1,148 @@
+package native
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/elliottech/gnark-plonky2-verifier/challenger"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/hasher"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// Returns the challenges of proof_challenges.json and the ones GetProofChallenges draws for
+// decode_block's proof, with its PoseidonBN254 hasher and the legacy transcript if legacyTranscript.
+func readDecodeBlockChallenges(assert *test.Assert, legacyTranscript bool) (types.ProofChallengesRaw, types.ProofChallengesRaw) {
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	commonCircuitData.Hasher = types.PoseidonBN254Hash
+	commonCircuitData.LegacyTranscript = legacyTranscript
+	challenges, err := GetProofChallenges(
+		types.ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json"),
+		types.ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json"),
+		commonCircuitData,
+	)
+	assert.NoError(err)
+	return types.ReadProofChallenges("../testdata/decode_block/proof_challenges.json"), challenges
+}
+
+// Compares the challenges of decode_block's proof with proof_challenges.json. That file was
+// written by GetProofChallenges rather than exported by plonky2: its first challenges are the ones
+// plonky2 draws (see TestDecodeBlockChallenges), and the proof only verifies with the others.
+func TestProofChallengesFile(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	expected, challenges := readDecodeBlockChallenges(assert, true)
+	assert.NoError(CompareProofChallenges(expected, challenges))
+
+	// The current transcript, which observes the FRI config first, diverges from the first
+	// challenge.
+	_, current := readDecodeBlockChallenges(assert, false)
+	assert.ErrorContains(CompareProofChallenges(expected, current), "plonk_betas[0]")
+}
+
+// The challenges plonky2 draws for decode_block's proof, from fri_test.TestFriCircuit.
+func TestDecodeBlockChallenges(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	v, proofWithPis, verifierData := readDecodeBlock(assert)
+	challenges := v.GetChallenges(proofWithPis.Proof, v.GetPublicInputsHash(proofWithPis.PublicInputs), verifierData)
+	assert.Equal(uint64(17615363392879944733), challenges.PlonkBetas[0].Uint64())
+	assert.Equal(uint64(15174493176564484303), challenges.PlonkGammas[0].Uint64())
+	assert.Equal(uint64(9276470834414745550), challenges.PlonkAlphas[0].Uint64())
+	assert.Equal(uint64(3892795992421241388), challenges.PlonkZeta[0].Uint64())
+	assert.Equal(uint64(885535811531859621), challenges.FriChallenges.FriAlpha[0].Uint64())
+	assert.Equal(uint64(5231781384587895507), challenges.FriChallenges.FriBetas[0][0].Uint64())
+	assert.Equal(uint64(70715523064019), challenges.FriChallenges.FriPowResponse.Uint64())
+	assert.Equal(uint64(11890500485816111017), challenges.FriChallenges.FriQueryIndices[0].Uint64())
+}
+
+func TestCompareProofChallenges(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	expected, actual := readDecodeBlockChallenges(assert, true)
+	assert.NoError(CompareProofChallenges(expected, actual))
+
+	actual.PlonkZeta[1]++
+	actual.FriChallenges.FriBetas = actual.FriChallenges.FriBetas[1:]
+	err := CompareProofChallenges(expected, actual)
+	assert.ErrorContains(err, "plonk_zeta[1]")
+	assert.ErrorContains(err, "fri_betas: expected")
+	assert.NotContains(err.Error(), "plonk_alphas")
+}
+
+type TestChallengerCircuit struct {
+	Elements []gl.Variable
+	Cap      variables.FriMerkleCap
+	Expected []gl.Variable
+
+	HasherType types.HasherType `gnark:"-"`
+}
+
+func (circuit *TestChallengerCircuit) Define(api frontend.API) error {
+	glApi := gl.New(api)
+	challengerChip := challenger.NewChip(api, hasher.New(api, circuit.HasherType))
+
+	challengerChip.ObserveElements(circuit.Elements)
+	challengerChip.ObserveCap(circuit.Cap)
+	challenges := challengerChip.GetNChallenges(uint64(len(circuit.Expected)))
+	for i := range challenges {
+		glApi.AssertIsEqual(challenges[i], circuit.Expected[i])
+	}
+
+	return nil
+}
+
+// Checks that the native challenger draws the same challenges as challenger.Chip.
+func TestChallengerMatchesChip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, hasherType := range []types.HasherType{types.PoseidonBN254Hash, types.PoseidonGoldilocksHash, types.KeccakHash} {
+		h, err := NewHasher(hasherType)
+		assert.NoError(err)
+
+		// Observe more than a sponge rate of elements and draw more than a sponge rate of
+		// challenges, so that both the observing and the squeezing duplex several times.
+		elements := make([]goldilocks.Element, 13)
+		for i := range elements {
+			elements[i] = goldilocks.NewElement(uint64(i) * 0x9e3779b97f4a7c15)
+		}
+		cap := FriMerkleCap{h.HashOrNoop(elements[:3]), h.HashOrNoop(elements)}
+
+		nativeChallenger := NewChallenger(h)
+		nativeChallenger.ObserveElements(elements)
+		nativeChallenger.ObserveCap(cap)
+		challenges := nativeChallenger.GetNChallenges(10)
+
+		circuit := TestChallengerCircuit{
+			Elements:   make([]gl.Variable, len(elements)),
+			Cap:        variables.FriMerkleCap{make(variables.HashOut, len(cap[0])), make(variables.HashOut, len(cap[1]))},
+			Expected:   make([]gl.Variable, len(challenges)),
+			HasherType: hasherType,
+		}
+		witness := TestChallengerCircuit{
+			Elements: make([]gl.Variable, len(elements)),
+			Cap:      variables.FriMerkleCap{},
+			Expected: make([]gl.Variable, len(challenges)),
+		}
+		for i := range elements {
+			witness.Elements[i] = gl.NewVariable(elements[i].Uint64())
+		}
+		for _, digest := range cap {
+			hashOut := make(variables.HashOut, len(digest))
+			for i := range digest {
+				hashOut[i] = digest[i]
+			}
+			witness.Cap = append(witness.Cap, hashOut)
+		}
+		for i := range challenges {
+			witness.Expected[i] = gl.NewVariable(challenges[i].Uint64())
+		}
+
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, "hasher %d", hasherType)
+	}
+}
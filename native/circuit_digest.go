@@ -0,0 +1,51 @@
+package native
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// The padded default (empty) domain separator, whose digest plonky2 includes in the circuit digest.
+// hash_pad appends a one, zeros up to the last element of the sponge rate and a final one.
+func domainSeparatorPadding() []goldilocks.Element {
+	padding := make([]goldilocks.Element, poseidon.SPONGE_RATE)
+	padding[0] = goldilocks.One()
+	padding[len(padding)-1] = goldilocks.One()
+	return padding
+}
+
+// Computes the circuit digest of plonky2's CircuitBuilder::build from the constants and sigmas cap:
+// the hash of the flattened cap, the digest of the default domain separator and the degree bits.
+// It's the counterpart of verifier.VerifierChip.GetCircuitDigest.
+func (v *Verifier) GetCircuitDigest(constantSigmasCap FriMerkleCap) HashOut {
+	var input []goldilocks.Element
+	for _, hash := range constantSigmasCap {
+		input = append(input, v.hasher.ToVec(hash)...)
+	}
+	input = append(input, v.hasher.ToVec(v.hasher.HashNoPad(domainSeparatorPadding()))...)
+	input = append(input, goldilocks.NewElement(v.commonData.DegreeBits))
+	return v.hasher.HashNoPad(input)
+}
+
+// Checks that the circuit digest of verifierData is the one derived from its constants and sigmas
+// cap, which verifier.UniversalVerifierCircuit asserts. Circuits built with a custom domain
+// separator fail this check.
+func VerifyCircuitDigest(verifierData types.VerifierOnlyCircuitDataRaw, commonData types.CommonCircuitData) error {
+	verifier, err := NewVerifier(commonData)
+	if err != nil {
+		return err
+	}
+	nativeVerifierData, err := NewVerifierOnlyCircuitData(verifier.hasher, verifierData)
+	if err != nil {
+		return fmt.Errorf("invalid verifier data: %w", err)
+	}
+
+	circuitDigest := verifier.GetCircuitDigest(nativeVerifierData.ConstantSigmasCap)
+	if !hashOutEqual(circuitDigest, nativeVerifierData.CircuitDigest) {
+		return fmt.Errorf("circuit digest %v doesn't match the constants sigmas cap, whose digest is %v", nativeVerifierData.CircuitDigest, circuitDigest)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package native
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+	"github.com/elliottech/gnark-plonky2-verifier/verifier"
+)
+
+type TestCircuitDigestCircuit struct {
+	ConstantSigmasCap variables.FriMerkleCap
+	Expected          variables.HashOut
+
+	CommonCircuitData types.CommonCircuitData `gnark:"-"`
+}
+
+func (circuit *TestCircuitDigestCircuit) Define(api frontend.API) error {
+	verifierChip, err := verifier.NewVerifierChip(api, circuit.CommonCircuitData, nil)
+	if err != nil {
+		return err
+	}
+
+	circuitDigest := verifierChip.GetCircuitDigest(circuit.ConstantSigmasCap)
+	for i := range circuitDigest {
+		api.AssertIsEqual(circuitDigest[i], circuit.Expected[i])
+	}
+	return nil
+}
+
+// Checks that the native circuit digest matches VerifierChip.GetCircuitDigest, and that
+// VerifyCircuitDigest only accepts verifier data holding it.
+func TestCircuitDigestMatchesChip(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, plonky2Circuit := range []string{"step", "decode_block"} {
+		commonCircuitData := types.ReadCommonCircuitData("../testdata/" + plonky2Circuit + "/common_circuit_data.json")
+		verifierDataRaw := types.ReadVerifierOnlyCircuitData("../testdata/" + plonky2Circuit + "/verifier_only_circuit_data.json")
+
+		v, err := NewVerifier(commonCircuitData)
+		assert.NoError(err)
+		verifierData, err := NewVerifierOnlyCircuitData(v.Hasher(), verifierDataRaw)
+		assert.NoError(err)
+		circuitDigest := v.GetCircuitDigest(verifierData.ConstantSigmasCap)
+
+		variablesData := variables.DeserializeVerifierOnlyCircuitData(verifierDataRaw)
+		expected := make(variables.HashOut, len(circuitDigest))
+		for i := range circuitDigest {
+			expected[i] = circuitDigest[i]
+		}
+		circuit := TestCircuitDigestCircuit{
+			ConstantSigmasCap: variablesData.ConstantSigmasCap,
+			Expected:          expected,
+			CommonCircuitData: commonCircuitData,
+		}
+		witness := TestCircuitDigestCircuit{
+			ConstantSigmasCap: variablesData.ConstantSigmasCap,
+			Expected:          expected,
+		}
+		assert.NoError(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), plonky2Circuit)
+
+		witness.Expected = append(variables.HashOut{}, expected...)
+		witness.Expected[0] = new(big.Int).Add(circuitDigest[0], big.NewInt(1))
+		assert.Error(test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()), plonky2Circuit)
+
+		verifierDataRaw.CircuitDigest = toRawHashOuts([]HashOut{circuitDigest})[0]
+		assert.NoError(VerifyCircuitDigest(verifierDataRaw, commonCircuitData), plonky2Circuit)
+		verifierDataRaw.CircuitDigest = toRawHashOuts([]HashOut{{new(big.Int).Add(circuitDigest[0], big.NewInt(1))}})[0]
+		assert.ErrorContains(VerifyCircuitDigest(verifierDataRaw, commonCircuitData), "doesn't match", plonky2Circuit)
+	}
+}
@@ -0,0 +1,310 @@
+package native
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// Restores the query rounds of a compressed proof, like plonky2's
+// CompressedProofWithPublicInputs::decompress. The query indices and the evaluations the compression
+// leaves out are recomputed from the challenges of the rest of the proof, so the result can be
+// verified like any proof, e.g. by verifier.VerifierChip once converted by
+// variables.DeserializeProofWithPublicInputs.
+func DecompressProofWithPublicInputs(
+	compressed types.CompressedProofWithPublicInputsRaw,
+	verifierData types.VerifierOnlyCircuitDataRaw,
+	commonData types.CommonCircuitData,
+) (types.ProofWithPublicInputsRaw, error) {
+	verifier, err := NewVerifier(commonData)
+	if err != nil {
+		return types.ProofWithPublicInputsRaw{}, err
+	}
+
+	nativeProofWithPis, err := NewProofWithPublicInputs(verifier.hasher, compressed.ProofWithPublicInputsRaw)
+	if err != nil {
+		return types.ProofWithPublicInputsRaw{}, fmt.Errorf("invalid proof: %w", err)
+	}
+	nativeVerifierData, err := NewVerifierOnlyCircuitData(verifier.hasher, verifierData)
+	if err != nil {
+		return types.ProofWithPublicInputsRaw{}, fmt.Errorf("invalid verifier data: %w", err)
+	}
+
+	publicInputsHash := verifier.GetPublicInputsHash(nativeProofWithPis.PublicInputs)
+	challenges := verifier.GetChallenges(nativeProofWithPis.Proof, publicInputsHash, nativeVerifierData)
+	rounds, err := verifier.decompressQueryRounds(&nativeProofWithPis.Proof, &challenges, compressed.QueryRounds)
+	if err != nil {
+		return types.ProofWithPublicInputsRaw{}, fmt.Errorf("invalid compressed proof: %w", err)
+	}
+
+	raw := compressed.ProofWithPublicInputsRaw
+	setRawQueryRounds(&raw, rounds)
+	return raw, nil
+}
+
+// Restores the query rounds of the proof, whose challenges are given.
+func (v *Verifier) decompressQueryRounds(
+	proof *Proof,
+	challenges *ProofChallenges,
+	compressed types.CompressedFriQueryRoundsRaw,
+) ([]FriQueryRound, error) {
+	friParams := &v.commonData.FriParams
+	friChallenges := &challenges.FriChallenges
+	instance := v.getFriInstance(challenges.PlonkZeta)
+	reductionArityBits := friParams.ReductionArityBits
+
+	// Size of the LDE domain.
+	nLog := friParams.DegreeBits + friParams.Config.RateBits
+
+	if len(compressed.Indices) != len(friChallenges.FriQueryIndices) {
+		return nil, fmt.Errorf("%d query rounds, expected %d", len(compressed.Indices), len(friChallenges.FriQueryIndices))
+	}
+	for i, index := range compressed.Indices {
+		if expected := friChallenges.FriQueryIndices[i].Uint64() & (1<<nLog - 1); index != expected {
+			return nil, fmt.Errorf("query round %d has index %d, the challenges give %d", i, index, expected)
+		}
+	}
+	if len(compressed.Steps) != len(reductionArityBits) {
+		return nil, fmt.Errorf("%d reduction steps, expected %d", len(compressed.Steps), len(reductionArityBits))
+	}
+
+	initialTreesProofs := make(map[uint64]*FriInitialTreeProof, len(compressed.InitialTreesProofs))
+	for index, rawEvalsProofs := range compressed.InitialTreesProofs {
+		if len(rawEvalsProofs) != len(instance.oracles) {
+			return nil, fmt.Errorf("index %d has %d initial trees, expected %d", index, len(rawEvalsProofs), len(instance.oracles))
+		}
+		proof := &FriInitialTreeProof{EvalsProofs: make([]FriEvalProof, len(rawEvalsProofs))}
+		for i, rawEvalsProof := range rawEvalsProofs {
+			evalsProof := &proof.EvalsProofs[i]
+			var err error
+			if evalsProof.Elements, err = newElements(rawEvalsProof.LeafElements); err != nil {
+				return nil, fmt.Errorf("index %d, initial tree %d leaf: %w", index, i, err)
+			}
+			if evalsProof.MerkleProof.Siblings, err = newHashOuts(v.hasher, rawEvalsProof.MerkleProof.Hash); err != nil {
+				return nil, fmt.Errorf("index %d, initial tree %d merkle proof: %w", index, i, err)
+			}
+		}
+		initialTreesProofs[index] = proof
+	}
+
+	precomputedReducedEvals := make([]gl.QuadraticExtension, len(instance.batches))
+	for i, batch := range toOpenings(proof.Openings) {
+		precomputedReducedEvals[i] = gl.ReduceWithPowersNative(batch, friChallenges.FriAlpha)
+	}
+
+	// The evaluations of each step's cosets, completed with the evaluation the previous step
+	// computes, as in plonky2's get_inferred_elements. As queries sharing a coset share the following
+	// ones too, a query stops at the first coset already completed by a previous query.
+	stepsEvals := make([]map[uint64][]gl.QuadraticExtension, len(reductionArityBits))
+	for i := range stepsEvals {
+		stepsEvals[i] = map[uint64][]gl.QuadraticExtension{}
+	}
+	for round, xIndex := range compressed.Indices {
+		initialTreesProof, ok := initialTreesProofs[xIndex]
+		if !ok {
+			return nil, fmt.Errorf("query round %d: no initial trees proof for index %d", round, xIndex)
+		}
+
+		var subgroupX goldilocks.Element
+		subgroupX.Exp(gl.PrimitiveRootOfUnity(nLog), new(big.Int).SetUint64(reverseBits(xIndex, nLog)))
+		subgroupX.Mul(&subgroupX, &gl.MULTIPLICATIVE_GROUP_GENERATOR)
+
+		oldEval, err := v.friCombineInitial(
+			instance,
+			initialTreesProof,
+			friChallenges.FriAlpha,
+			gl.ToQuadraticExtension(subgroupX),
+			precomputedReducedEvals,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("query round %d: %w", round, err)
+		}
+
+		for i, arityBits := range reductionArityBits {
+			cosetIndex := xIndex >> arityBits
+			if _, ok := stepsEvals[i][cosetIndex]; ok {
+				break
+			}
+
+			step, ok := compressed.Steps[i][cosetIndex]
+			if !ok {
+				return nil, fmt.Errorf("query round %d: no step %d for coset %d", round, i, cosetIndex)
+			}
+			evals, err := newExtensions(step.Evals)
+			if err != nil {
+				return nil, fmt.Errorf("query round %d, step %d evals: %w", round, i, err)
+			}
+			if len(evals) != 1<<arityBits-1 {
+				return nil, fmt.Errorf("query round %d, step %d has %d evals, expected %d", round, i, len(evals), 1<<arityBits-1)
+			}
+
+			xIndexWithinCoset := xIndex & (1<<arityBits - 1)
+			evals = slices.Insert(evals, int(xIndexWithinCoset), oldEval)
+			stepsEvals[i][cosetIndex] = evals
+			oldEval = computeEvaluation(subgroupX, xIndexWithinCoset, arityBits, evals, friChallenges.FriBetas[i])
+
+			// Update the point x to x^arity.
+			for j := uint64(0); j < arityBits; j++ {
+				subgroupX.Square(&subgroupX)
+			}
+
+			xIndex = cosetIndex
+		}
+	}
+
+	rounds := make([]FriQueryRound, len(compressed.Indices))
+	for i := range rounds {
+		rounds[i].InitialTreesProof.EvalsProofs = make([]FriEvalProof, len(instance.oracles))
+		rounds[i].Steps = make([]FriQueryStep, len(reductionArityBits))
+	}
+
+	leaves := make([][]goldilocks.Element, len(rounds))
+	compressedProofs := make([][]HashOut, len(rounds))
+	for tree := range instance.oracles {
+		for i, index := range compressed.Indices {
+			evalsProof := initialTreesProofs[index].EvalsProofs[tree]
+			leaves[i] = evalsProof.Elements
+			compressedProofs[i] = evalsProof.MerkleProof.Siblings
+		}
+
+		merkleProofs, err := v.decompressMerkleProofs(leaves, compressed.Indices, compressedProofs, nLog)
+		if err != nil {
+			return nil, fmt.Errorf("initial tree %d: %w", tree, err)
+		}
+		for i, index := range compressed.Indices {
+			rounds[i].InitialTreesProof.EvalsProofs[tree] = FriEvalProof{
+				Elements:    initialTreesProofs[index].EvalsProofs[tree].Elements,
+				MerkleProof: FriMerkleProof{Siblings: merkleProofs[i]},
+			}
+		}
+	}
+
+	height := nLog
+	indices := slices.Clone(compressed.Indices)
+	for step, arityBits := range reductionArityBits {
+		height -= arityBits
+		for i := range indices {
+			indices[i] >>= arityBits
+
+			evals := stepsEvals[step][indices[i]]
+			leaves[i] = make([]goldilocks.Element, 0, 2*len(evals))
+			for _, eval := range evals {
+				leaves[i] = append(leaves[i], eval[:]...)
+			}
+
+			var err error
+			if compressedProofs[i], err = newHashOuts(v.hasher, compressed.Steps[step][indices[i]].Siblings); err != nil {
+				return nil, fmt.Errorf("step %d, coset %d merkle proof: %w", step, indices[i], err)
+			}
+		}
+
+		merkleProofs, err := v.decompressMerkleProofs(leaves, indices, compressedProofs, height)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", step, err)
+		}
+		for i := range rounds {
+			rounds[i].Steps[step] = FriQueryStep{
+				Evals:       stepsEvals[step][indices[i]],
+				MerkleProof: FriMerkleProof{Siblings: merkleProofs[i]},
+			}
+		}
+	}
+
+	return rounds, nil
+}
+
+// The counterpart of plonky2's decompress_merkle_proofs. Rebuilds the Merkle proofs of the leaves at
+// indices, in a tree of height height, from compressed proofs which only hold the siblings that
+// neither the leaves nor the previous compressed proofs determine.
+func (v *Verifier) decompressMerkleProofs(
+	leaves [][]goldilocks.Element,
+	indices []uint64,
+	compressedProofs [][]HashOut,
+	height uint64,
+) ([][]HashOut, error) {
+	capHeight := v.commonData.Config.FriConfig.CapHeight
+	if height < capHeight {
+		return nil, fmt.Errorf("tree height %d is below the cap height %d", height, capHeight)
+	}
+	numLeaves := uint64(1) << height
+
+	// The digests of the nodes known so far. The root is node 1 and node i has children 2i and 2i+1,
+	// so that the leaf at index i is node numLeaves+i.
+	seen := make(map[uint64]HashOut)
+	for i, index := range indices {
+		seen[numLeaves+index] = v.hasher.HashOrNoop(leaves[i])
+	}
+
+	// Fill seen layer by layer, from the leaves to the cap.
+	nextSiblings := make([]int, len(indices))
+	for layer := uint64(0); layer < height-capHeight; layer++ {
+		for i, index := range indices {
+			node := (numLeaves + index) >> layer
+			sibling, ok := seen[node^1]
+			if !ok {
+				if nextSiblings[i] == len(compressedProofs[i]) {
+					return nil, fmt.Errorf("the merkle proof of leaf %d lacks siblings", index)
+				}
+				sibling = compressedProofs[i][nextSiblings[i]]
+				nextSiblings[i]++
+				seen[node^1] = sibling
+			}
+
+			if node&1 == 0 {
+				seen[node>>1] = v.hasher.TwoToOne(seen[node], sibling)
+			} else {
+				seen[node>>1] = v.hasher.TwoToOne(sibling, seen[node])
+			}
+		}
+	}
+
+	proofs := make([][]HashOut, len(indices))
+	for i, index := range indices {
+		proofs[i] = make([]HashOut, height-capHeight)
+		node := numLeaves + index
+		for j := range proofs[i] {
+			proofs[i][j] = seen[node^1]
+			node >>= 1
+		}
+	}
+	return proofs, nil
+}
+
+// Sets the query rounds of a deserialized proof, whose anonymous structs are allocated with
+// slices.Grow.
+func setRawQueryRounds(raw *types.ProofWithPublicInputsRaw, rounds []FriQueryRound) {
+	rawRounds := slices.Grow(raw.Proof.OpeningProof.QueryRoundProofs[:0:0], len(rounds))[:len(rounds)]
+	for i, round := range rounds {
+		rawRound := &rawRounds[i]
+
+		rawRound.InitialTreesProof.EvalsProofs = make([]types.EvalProofRaw, len(round.InitialTreesProof.EvalsProofs))
+		for j, evalsProof := range round.InitialTreesProof.EvalsProofs {
+			rawRound.InitialTreesProof.EvalsProofs[j] = types.EvalProofRaw{
+				LeafElements: elementsToUint64s(evalsProof.Elements),
+				MerkleProof:  types.MerkleProofRaw{Hash: toRawHashOuts(evalsProof.MerkleProof.Siblings)},
+			}
+		}
+
+		rawRound.Steps = slices.Grow(rawRound.Steps, len(round.Steps))[:len(round.Steps)]
+		for j, step := range round.Steps {
+			rawRound.Steps[j].Evals = make([][]uint64, len(step.Evals))
+			for k, eval := range step.Evals {
+				rawRound.Steps[j].Evals[k] = extensionToUint64s(eval)
+			}
+			rawRound.Steps[j].MerkleProof.Siblings = toRawHashOuts(step.MerkleProof.Siblings)
+		}
+	}
+	raw.Proof.OpeningProof.QueryRoundProofs = rawRounds
+}
+
+func toRawHashOuts(hashes []HashOut) []types.HashOutRaw {
+	raws := make([]types.HashOutRaw, len(hashes))
+	for i, hash := range hashes {
+		raws[i] = types.HashOutRaw{Elements: hash}
+	}
+	return raws
+}
@@ -0,0 +1,249 @@
+package native
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/consensys/gnark/test"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// Mirrors plonky2's compress_merkle_proofs, removing from the Merkle proofs of the leaves at indices
+// the siblings on the paths of the leaves or already in a previous proof.
+func compressMerkleProofs(indices []uint64, proofs [][]HashOut, height uint64) [][]HashOut {
+	numLeaves := uint64(1) << height
+	known := map[uint64]bool{}
+	for i, index := range indices {
+		for j := range proofs[i] {
+			known[(numLeaves+index)>>j] = true
+		}
+	}
+
+	compressed := make([][]HashOut, len(indices))
+	for i, index := range indices {
+		compressed[i] = []HashOut{}
+		node := numLeaves + index
+		for _, sibling := range proofs[i] {
+			if !known[node^1] {
+				compressed[i] = append(compressed[i], sibling)
+				known[node^1] = true
+			}
+			node >>= 1
+		}
+	}
+	return compressed
+}
+
+// Mirrors plonky2's ProofWithPublicInputs::compress, with the query indices of the proof.
+func compressQueryRounds(v *Verifier, proof *Proof, indices []uint64) types.CompressedFriQueryRoundsRaw {
+	friParams := &v.commonData.FriParams
+	rounds := proof.OpeningProof.QueryRoundProofs
+	height := friParams.DegreeBits + friParams.Config.RateBits
+
+	compressed := types.CompressedFriQueryRoundsRaw{
+		Indices:            indices,
+		InitialTreesProofs: map[uint64][]types.EvalProofRaw{},
+		Steps:              make([]map[uint64]types.CompressedFriQueryStepRaw, len(friParams.ReductionArityBits)),
+	}
+
+	proofs := make([][]HashOut, len(rounds))
+	initialTreesProofs := make([][]types.EvalProofRaw, len(rounds))
+	for tree := range rounds[0].InitialTreesProof.EvalsProofs {
+		for i, round := range rounds {
+			proofs[i] = round.InitialTreesProof.EvalsProofs[tree].MerkleProof.Siblings
+		}
+		for i, siblings := range compressMerkleProofs(indices, proofs, height) {
+			initialTreesProofs[i] = append(initialTreesProofs[i], types.EvalProofRaw{
+				LeafElements: elementsToUint64s(rounds[i].InitialTreesProof.EvalsProofs[tree].Elements),
+				MerkleProof:  types.MerkleProofRaw{Hash: toRawHashOuts(siblings)},
+			})
+		}
+	}
+	for i, index := range indices {
+		if _, ok := compressed.InitialTreesProofs[index]; !ok {
+			compressed.InitialTreesProofs[index] = initialTreesProofs[i]
+		}
+	}
+
+	indices = slices.Clone(indices)
+	for step, arityBits := range friParams.ReductionArityBits {
+		height -= arityBits
+		for i, round := range rounds {
+			proofs[i] = round.Steps[step].MerkleProof.Siblings
+		}
+
+		compressed.Steps[step] = map[uint64]types.CompressedFriQueryStepRaw{}
+		compressedProofs := compressMerkleProofs(shiftIndices(indices, arityBits), proofs, height)
+		for i, round := range rounds {
+			xIndexWithinCoset := indices[i] & (1<<arityBits - 1)
+			indices[i] >>= arityBits
+			if _, ok := compressed.Steps[step][indices[i]]; ok {
+				continue
+			}
+
+			var evals [][]uint64
+			for j, eval := range round.Steps[step].Evals {
+				if uint64(j) != xIndexWithinCoset {
+					evals = append(evals, extensionToUint64s(eval))
+				}
+			}
+			compressed.Steps[step][indices[i]] = types.CompressedFriQueryStepRaw{
+				Evals:    evals,
+				Siblings: toRawHashOuts(compressedProofs[i]),
+			}
+		}
+	}
+
+	return compressed
+}
+
+func shiftIndices(indices []uint64, bits uint64) []uint64 {
+	shifted := make([]uint64, len(indices))
+	for i, index := range indices {
+		shifted[i] = index >> bits
+	}
+	return shifted
+}
+
+func friQueryIndices(v *Verifier, challenges ProofChallenges) []uint64 {
+	friParams := v.commonData.FriParams
+	nLog := friParams.DegreeBits + friParams.Config.RateBits
+	indices := make([]uint64, len(challenges.FriChallenges.FriQueryIndices))
+	for i, index := range challenges.FriChallenges.FriQueryIndices {
+		indices[i] = index.Uint64() & (1<<nLog - 1)
+	}
+	return indices
+}
+
+func TestDecompressQueryRounds(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	v, proofWithPis, verifierData := readDecodeBlock(assert)
+	challenges := v.GetChallenges(proofWithPis.Proof, v.GetPublicInputsHash(proofWithPis.PublicInputs), verifierData)
+	proof := &proofWithPis.Proof
+
+	// Query the same index twice, which the compression stores once.
+	challenges.FriChallenges.FriQueryIndices[1] = challenges.FriChallenges.FriQueryIndices[0]
+	proof.OpeningProof.QueryRoundProofs[1] = proof.OpeningProof.QueryRoundProofs[0]
+
+	indices := friQueryIndices(v, challenges)
+	compressed := compressQueryRounds(v, proof, indices)
+	assert.Equal(len(indices)-1, len(compressed.InitialTreesProofs))
+
+	rounds, err := v.decompressQueryRounds(proof, &challenges, compressed)
+	assert.NoError(err)
+	assert.Equal(proof.OpeningProof.QueryRoundProofs, rounds)
+
+	decompressed := proofWithPis
+	decompressed.Proof.OpeningProof.QueryRoundProofs = rounds
+	assert.NoError(verifyDecodeBlock(v, decompressed, verifierData, challenges))
+
+	// The raw query rounds convert back to the same proof.
+	raw := types.ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json")
+	setRawQueryRounds(&raw, rounds)
+	rawDecompressed, err := NewProofWithPublicInputs(v.Hasher(), raw)
+	assert.NoError(err)
+	assert.Equal(rounds, rawDecompressed.Proof.OpeningProof.QueryRoundProofs)
+}
+
+func TestDecompressQueryRoundsTampered(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	v, proofWithPis, verifierData := readDecodeBlock(assert)
+	challenges := v.GetChallenges(proofWithPis.Proof, v.GetPublicInputsHash(proofWithPis.PublicInputs), verifierData)
+	proof := &proofWithPis.Proof
+	indices := friQueryIndices(v, challenges)
+
+	// The indices must be the ones the challenger draws.
+	compressed := compressQueryRounds(v, proof, indices)
+	compressed.Indices = slices.Clone(indices)
+	compressed.Indices[0] ^= 1
+	_, err := v.decompressQueryRounds(proof, &challenges, compressed)
+	assert.ErrorContains(err, "query round 0 has index")
+
+	// A missing sibling can't be recovered.
+	compressed = compressQueryRounds(v, proof, indices)
+	evalsProofs := compressed.InitialTreesProofs[indices[0]]
+	evalsProofs[1].MerkleProof.Hash = evalsProofs[1].MerkleProof.Hash[1:]
+	_, err = v.decompressQueryRounds(proof, &challenges, compressed)
+	assert.ErrorContains(err, "initial tree 1")
+
+	// A wrong step evaluation changes the inferred evaluations and Merkle proofs, which no longer
+	// verify.
+	compressed = compressQueryRounds(v, proof, indices)
+	step := compressed.Steps[0][indices[0]>>v.commonData.FriParams.ReductionArityBits[0]]
+	step.Evals[0] = []uint64{step.Evals[0][0] ^ 1, step.Evals[0][1]}
+	rounds, err := v.decompressQueryRounds(proof, &challenges, compressed)
+	assert.NoError(err)
+	proof.OpeningProof.QueryRoundProofs = rounds
+	assert.ErrorContains(verifyDecodeBlock(v, proofWithPis, verifierData, challenges), "merkle proof")
+}
+
+// Compresses the proofs of testdata with the query indices their challenges draw, and restores
+// them with DecompressProofWithPublicInputs.
+func TestDecompressProofWithPublicInputs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, plonky2Circuit := range []string{"decode_block", "step"} {
+		commonCircuitData := types.ReadCommonCircuitData("../testdata/" + plonky2Circuit + "/common_circuit_data.json")
+		commonCircuitData.LegacyTranscript = true
+		raw := types.ReadProofWithPublicInputs("../testdata/" + plonky2Circuit + "/proof_with_public_inputs.json")
+		verifierDataRaw := types.ReadVerifierOnlyCircuitData("../testdata/" + plonky2Circuit + "/verifier_only_circuit_data.json")
+
+		v, err := NewVerifier(commonCircuitData)
+		assert.NoError(err)
+		proofWithPis, err := NewProofWithPublicInputs(v.Hasher(), raw)
+		assert.NoError(err)
+		verifierData, err := NewVerifierOnlyCircuitData(v.Hasher(), verifierDataRaw)
+		assert.NoError(err)
+		challenges := v.GetChallenges(proofWithPis.Proof, v.GetPublicInputsHash(proofWithPis.PublicInputs), verifierData)
+
+		compressed := types.CompressedProofWithPublicInputsRaw{
+			ProofWithPublicInputsRaw: raw,
+			QueryRounds:              compressQueryRounds(v, &proofWithPis.Proof, friQueryIndices(v, challenges)),
+		}
+		compressed.Proof.OpeningProof.QueryRoundProofs = nil
+
+		decompressed, err := DecompressProofWithPublicInputs(compressed, verifierDataRaw, commonCircuitData)
+		assert.NoError(err, plonky2Circuit)
+		assert.NoError(Verify(decompressed, verifierDataRaw, commonCircuitData), plonky2Circuit)
+		decompressedProofWithPis, err := NewProofWithPublicInputs(v.Hasher(), decompressed)
+		assert.NoError(err)
+		assert.Equal(proofWithPis.Proof.OpeningProof.QueryRoundProofs, decompressedProofWithPis.Proof.OpeningProof.QueryRoundProofs, plonky2Circuit)
+
+		// The query indices are checked against the challenges of the proof.
+		compressed.QueryRounds.Indices = slices.Clone(compressed.QueryRounds.Indices)
+		compressed.QueryRounds.Indices[0] ^= 1
+		_, err = DecompressProofWithPublicInputs(compressed, verifierDataRaw, commonCircuitData)
+		assert.ErrorContains(err, "query round 0 has index", plonky2Circuit)
+	}
+}
+
+// The bytes of decode_block's proof compressed by plonky2's CompressedProofWithPublicInputs::to_bytes.
+const decodeBlockCompressed = "../testdata/decode_block/compressed_proof_with_public_inputs.bin"
+
+// Decompresses decode_block's proof compressed by plonky2 and compares it to the proof plonky2
+// compressed.
+func TestDecompressProofWithPublicInputsFile(t *testing.T) {
+	if _, err := os.Stat(decodeBlockCompressed); errors.Is(err, os.ErrNotExist) {
+		t.Skipf("no compressed proof at %s", decodeBlockCompressed)
+	}
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	commonCircuitData.LegacyTranscript = true
+	raw := types.ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json")
+	verifierDataRaw := types.ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json")
+
+	compressed, err := types.ReadCompressedProofWithPublicInputsBinary(decodeBlockCompressed, &commonCircuitData)
+	assert.NoError(err)
+	decompressed, err := DecompressProofWithPublicInputs(compressed, verifierDataRaw, commonCircuitData)
+	assert.NoError(err)
+	// The JSON proof has no lookup openings, which are empty in the binary one, so only the parts the
+	// compression changes are compared.
+	assert.Equal(raw.PublicInputs, decompressed.PublicInputs)
+	assert.Equal(raw.Proof.OpeningProof.QueryRoundProofs, decompressed.Proof.OpeningProof.QueryRoundProofs)
+	assert.NoError(Verify(decompressed, verifierDataRaw, commonCircuitData))
+}
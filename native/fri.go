@@ -0,0 +1,403 @@
+package native
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/fri"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// The out of circuit counterpart of fri.BatchInfo.
+type batchInfo struct {
+	point       gl.QuadraticExtension
+	polynomials []fri.PolynomialInfo
+}
+
+// The out of circuit counterpart of fri.InstanceInfo.
+type instanceInfo struct {
+	oracles []fri.OracleInfo
+	batches []batchInfo
+}
+
+func (v *Verifier) getFriInstance(zeta gl.QuadraticExtension) instanceInfo {
+	g := gl.PrimitiveRootOfUnity(v.commonData.DegreeBits)
+	zetaNext := zeta.ScalarMul(g)
+
+	return instanceInfo{
+		oracles: fri.FriOracles(&v.commonData),
+		batches: []batchInfo{
+			{point: zeta, polynomials: fri.FriAllPolys(&v.commonData)},
+			{point: zetaNext, polynomials: fri.FriNextBatchPolys(&v.commonData)},
+		},
+	}
+}
+
+// The out of circuit counterpart of fri.Chip.ToOpenings.
+func toOpenings(c OpeningSet) [][]gl.QuadraticExtension {
+	values := []gl.QuadraticExtension{}
+	values = append(values, c.Constants...)       // num_constants + 1
+	values = append(values, c.PlonkSigmas...)     // num_routed_wires
+	values = append(values, c.Wires...)           // num_wires
+	values = append(values, c.PlonkZs...)         // num_challenges
+	values = append(values, c.PartialProducts...) // num_challenges * num_partial_products
+	values = append(values, c.QuotientPolys...)   // num_challenges * quotient_degree_factor
+	values = append(values, c.LookupZs...)        // num_challenges * num_lookup_polys
+	nextValues := []gl.QuadraticExtension{}
+	nextValues = append(nextValues, c.PlonkZsNext...)  // num_challenges
+	nextValues = append(nextValues, c.LookupZsNext...) // num_challenges * num_lookup_polys
+	return [][]gl.QuadraticExtension{values, nextValues}
+}
+
+// The out of circuit counterpart of fri.validateFriProofShape, returning an error instead of panicking.
+func validateFriProofShape(proof *FriProof, instance instanceInfo, params *types.FriParams) error {
+	capHeight := params.Config.CapHeight
+	for i, cap := range proof.CommitPhaseMerkleCaps {
+		if 1<<capHeight != len(cap) {
+			return fmt.Errorf("commit phase merkle cap %d has %d digests, expected %d", i, len(cap), 1<<capHeight)
+		}
+	}
+
+	if len(proof.CommitPhaseMerkleCaps) != len(params.ReductionArityBits) {
+		return fmt.Errorf(
+			"proof has %d commit phase merkle caps, expected %d",
+			len(proof.CommitPhaseMerkleCaps), len(params.ReductionArityBits),
+		)
+	}
+
+	if len(proof.QueryRoundProofs) != int(params.Config.NumQueryRounds) {
+		return fmt.Errorf(
+			"proof has %d query rounds, expected %d",
+			len(proof.QueryRoundProofs), params.Config.NumQueryRounds,
+		)
+	}
+
+	for r, queryRound := range proof.QueryRoundProofs {
+		evalsProofs := queryRound.InitialTreesProof.EvalsProofs
+		if len(evalsProofs) != len(instance.oracles) {
+			return fmt.Errorf(
+				"query round %d has %d initial tree proofs, expected %d",
+				r, len(evalsProofs), len(instance.oracles),
+			)
+		}
+		for i, evalProof := range evalsProofs {
+			oracle := instance.oracles[i]
+			if len(evalProof.Elements) != int(oracle.NumPolys+params.SaltSize(oracle.Blinding)) {
+				return fmt.Errorf("query round %d, initial tree %d leaf length doesn't match its oracle", r, i)
+			}
+			if len(evalProof.MerkleProof.Siblings)+int(capHeight) != params.LdeBits() {
+				return fmt.Errorf("query round %d, initial tree %d merkle proof length doesn't match lde_bits", r, i)
+			}
+		}
+
+		if len(queryRound.Steps) != len(params.ReductionArityBits) {
+			return fmt.Errorf(
+				"query round %d has %d steps, expected %d",
+				r, len(queryRound.Steps), len(params.ReductionArityBits),
+			)
+		}
+
+		codewordLenBits := params.LdeBits()
+		for i, step := range queryRound.Steps {
+			arityBits := params.ReductionArityBits[i]
+			codewordLenBits -= int(arityBits)
+
+			if len(step.Evals) != 1<<arityBits {
+				return fmt.Errorf("query round %d, step %d has %d evals, expected %d", r, i, len(step.Evals), 1<<arityBits)
+			}
+			if len(step.MerkleProof.Siblings)+int(capHeight) != codewordLenBits {
+				return fmt.Errorf("query round %d, step %d merkle proof length doesn't match the codeword length", r, i)
+			}
+		}
+	}
+
+	if len(proof.FinalPoly) != params.FinalPolyLen() {
+		return fmt.Errorf("final poly has %d coefficients, expected %d", len(proof.FinalPoly), params.FinalPolyLen())
+	}
+
+	return nil
+}
+
+// Verifies a Merkle proof of the leaf at leafIndex, whose cap index is given by the bits of leafIndex
+// above the proof's siblings.
+func (v *Verifier) verifyMerkleProofToCap(
+	leafData []goldilocks.Element,
+	leafIndex uint64,
+	merkleCap FriMerkleCap,
+	proof *FriMerkleProof,
+) error {
+	currentDigest := v.hasher.HashOrNoop(leafData)
+	index := leafIndex
+	for _, sibling := range proof.Siblings {
+		if index&1 == 1 {
+			currentDigest = v.hasher.TwoToOne(sibling, currentDigest)
+		} else {
+			currentDigest = v.hasher.TwoToOne(currentDigest, sibling)
+		}
+		index >>= 1
+	}
+
+	if index >= uint64(len(merkleCap)) {
+		return fmt.Errorf("cap index %d is out of range", index)
+	}
+	if !hashOutEqual(currentDigest, merkleCap[index]) {
+		return fmt.Errorf("merkle proof of leaf %d doesn't match cap entry %d", leafIndex, index)
+	}
+	return nil
+}
+
+func (v *Verifier) verifyInitialProof(
+	xIndex uint64,
+	proof *FriInitialTreeProof,
+	initialMerkleCaps []FriMerkleCap,
+) error {
+	for i, cap := range initialMerkleCaps {
+		evalsProof := proof.EvalsProofs[i]
+		if err := v.verifyMerkleProofToCap(evalsProof.Elements, xIndex, cap, &evalsProof.MerkleProof); err != nil {
+			return fmt.Errorf("initial tree %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Returns the polynomial evaluations of an oracle's leaf, without the salt of hiding proofs.
+func (v *Verifier) unsaltedEvals(instance instanceInfo, proof *FriInitialTreeProof, oracleIndex uint64) []goldilocks.Element {
+	evals := proof.EvalsProofs[oracleIndex].Elements
+	saltSize := v.commonData.FriParams.SaltSize(instance.oracles[oracleIndex].Blinding)
+	return evals[:uint64(len(evals))-saltSize]
+}
+
+func (v *Verifier) friCombineInitial(
+	instance instanceInfo,
+	proof *FriInitialTreeProof,
+	friAlpha gl.QuadraticExtension,
+	subgroupX gl.QuadraticExtension,
+	precomputedReducedEvals []gl.QuadraticExtension,
+) (gl.QuadraticExtension, error) {
+	sum := gl.ZeroExtensionNative()
+
+	for i, batch := range instance.batches {
+		evals := make([]gl.QuadraticExtension, 0, len(batch.polynomials))
+		for _, polynomial := range batch.polynomials {
+			unsaltedEvals := v.unsaltedEvals(instance, proof, polynomial.OracleIndex)
+			evals = append(evals, gl.ToQuadraticExtension(unsaltedEvals[polynomial.PolynomialInfo]))
+		}
+
+		reducedEvals := gl.ReduceWithPowersNative(evals, friAlpha)
+		numerator := reducedEvals.Sub(precomputedReducedEvals[i])
+		denominator := subgroupX.Sub(batch.point)
+		if denominator.IsZero() {
+			return gl.QuadraticExtension{}, fmt.Errorf("the query point is the opening point of batch %d", i)
+		}
+
+		sum = friAlpha.Exp(uint64(len(evals))).Mul(sum)
+		sum = numerator.Div(denominator).Add(sum)
+	}
+
+	return sum, nil
+}
+
+func finalPolyEval(finalPoly []gl.QuadraticExtension, point gl.QuadraticExtension) gl.QuadraticExtension {
+	ret := gl.ZeroExtensionNative()
+	for i := len(finalPoly) - 1; i >= 0; i-- {
+		ret = ret.Mul(point).Add(finalPoly[i])
+	}
+	return ret
+}
+
+// Evaluates at x the polynomial interpolating the points (xPoints[i], yPoints[i]), with the
+// barycentric formula.
+func interpolate(x gl.QuadraticExtension, xPoints []gl.QuadraticExtension, yPoints []gl.QuadraticExtension) gl.QuadraticExtension {
+	for i := range xPoints {
+		if x.Equal(xPoints[i]) {
+			return yPoints[i]
+		}
+	}
+
+	lX := gl.OneExtensionNative()
+	for i := range xPoints {
+		lX = lX.Mul(x.Sub(xPoints[i]))
+	}
+
+	sum := gl.ZeroExtensionNative()
+	for i := range xPoints {
+		barycentricWeight := gl.OneExtensionNative()
+		for j := range xPoints {
+			if i != j {
+				barycentricWeight = barycentricWeight.Mul(xPoints[i].Sub(xPoints[j]))
+			}
+		}
+		sum = sum.Add(yPoints[i].Div(barycentricWeight.Mul(x.Sub(xPoints[i]))))
+	}
+
+	return lX.Mul(sum)
+}
+
+// The out of circuit counterpart of fri.Chip.computeEvaluation.
+func computeEvaluation(
+	x goldilocks.Element,
+	xIndexWithinCoset uint64,
+	arityBits uint64,
+	evals []gl.QuadraticExtension,
+	beta gl.QuadraticExtension,
+) gl.QuadraticExtension {
+	arity := 1 << arityBits
+
+	g := gl.PrimitiveRootOfUnity(arityBits)
+	var gInv goldilocks.Element
+	gInv.Exp(g, big.NewInt(int64(arity-1)))
+
+	// The evaluation vector needs to be reordered first. Permute the evals array such that each
+	// element's new index is the bit reverse of it's original index.
+	permutedEvals := make([]gl.QuadraticExtension, len(evals))
+	for i := range evals {
+		newIndex := reverseBits(uint64(i), arityBits)
+		permutedEvals[newIndex] = evals[i]
+	}
+
+	// Want `g^(arity - rev_x_index_within_coset)` as in the out-of-circuit version. Compute it
+	// as `(g^-1)^rev_x_index_within_coset`.
+	var cosetStart goldilocks.Element
+	cosetStart.Exp(gInv, new(big.Int).SetUint64(reverseBits(xIndexWithinCoset, arityBits)))
+	cosetStart.Mul(&cosetStart, &x)
+
+	xPoints := make([]gl.QuadraticExtension, arity)
+	xPoints[0] = gl.ToQuadraticExtension(cosetStart)
+	for i := 1; i < arity; i++ {
+		xPoints[i] = xPoints[i-1].ScalarMul(g)
+	}
+
+	return interpolate(beta, xPoints, permutedEvals)
+}
+
+// Returns the numBits least significant bits of x in reverse order.
+func reverseBits(x uint64, numBits uint64) uint64 {
+	if numBits == 0 {
+		return 0
+	}
+	return bits.Reverse64(x) >> (64 - numBits)
+}
+
+func (v *Verifier) verifyQueryRound(
+	instance instanceInfo,
+	challenges *FriChallenges,
+	precomputedReducedEvals []gl.QuadraticExtension,
+	initialMerkleCaps []FriMerkleCap,
+	proof *FriProof,
+	xIndex uint64,
+	nLog uint64,
+	roundProof *FriQueryRound,
+) error {
+	xIndex &= (1 << nLog) - 1
+
+	if err := v.verifyInitialProof(xIndex, &roundProof.InitialTreesProof, initialMerkleCaps); err != nil {
+		return err
+	}
+
+	// `subgroup_x` is `subgroup[x_index]`, i.e., the actual field element in the domain.
+	var subgroupX goldilocks.Element
+	subgroupX.Exp(gl.PrimitiveRootOfUnity(nLog), new(big.Int).SetUint64(reverseBits(xIndex, nLog)))
+	subgroupX.Mul(&subgroupX, &gl.MULTIPLICATIVE_GROUP_GENERATOR)
+
+	oldEval, err := v.friCombineInitial(
+		instance,
+		&roundProof.InitialTreesProof,
+		challenges.FriAlpha,
+		gl.ToQuadraticExtension(subgroupX),
+		precomputedReducedEvals,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i, arityBits := range v.commonData.FriParams.ReductionArityBits {
+		evals := roundProof.Steps[i].Evals
+
+		cosetIndex := xIndex >> arityBits
+		xIndexWithinCoset := xIndex & ((1 << arityBits) - 1)
+
+		// The eval at xIndexWithinCoset must match the previous step's evaluation.
+		if !evals[xIndexWithinCoset].Equal(oldEval) {
+			return fmt.Errorf("step %d: eval doesn't match the previous step's evaluation", i)
+		}
+
+		oldEval = computeEvaluation(subgroupX, xIndexWithinCoset, arityBits, evals, challenges.FriBetas[i])
+
+		fieldEvals := make([]goldilocks.Element, 0, 2*len(evals))
+		for _, eval := range evals {
+			fieldEvals = append(fieldEvals, eval[:]...)
+		}
+		err := v.verifyMerkleProofToCap(
+			fieldEvals,
+			cosetIndex,
+			proof.CommitPhaseMerkleCaps[i],
+			&roundProof.Steps[i].MerkleProof,
+		)
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+
+		// Update the point x to x^arity.
+		for j := uint64(0); j < arityBits; j++ {
+			subgroupX.Square(&subgroupX)
+		}
+
+		xIndex = cosetIndex
+	}
+
+	if !finalPolyEval(proof.FinalPoly, gl.ToQuadraticExtension(subgroupX)).Equal(oldEval) {
+		return errors.New("final polynomial evaluation doesn't match the last step's evaluation")
+	}
+
+	return nil
+}
+
+// The out of circuit counterpart of fri.Chip.VerifyFriProof.
+func (v *Verifier) verifyFriProof(
+	instance instanceInfo,
+	openings [][]gl.QuadraticExtension,
+	friChallenges *FriChallenges,
+	initialMerkleCaps []FriMerkleCap,
+	friProof *FriProof,
+) error {
+	friParams := &v.commonData.FriParams
+	if err := validateFriProofShape(friProof, instance, friParams); err != nil {
+		return err
+	}
+
+	// Check POW
+	powBits := friParams.Config.ProofOfWorkBits
+	if leadingZeros := uint64(bits.LeadingZeros64(friChallenges.FriPowResponse.Uint64())); leadingZeros < powBits {
+		return fmt.Errorf("proof of work response has %d leading zeros, expected at least %d", leadingZeros, powBits)
+	}
+
+	precomputedReducedEvals := make([]gl.QuadraticExtension, len(openings))
+	for i, batch := range openings {
+		precomputedReducedEvals[i] = gl.ReduceWithPowersNative(batch, friChallenges.FriAlpha)
+	}
+
+	// Size of the LDE domain.
+	nLog := friParams.DegreeBits + friParams.Config.RateBits
+
+	for idx, xIndex := range friChallenges.FriQueryIndices {
+		err := v.verifyQueryRound(
+			instance,
+			friChallenges,
+			precomputedReducedEvals,
+			initialMerkleCaps,
+			friProof,
+			xIndex.Uint64(),
+			nLog,
+			&friProof.QueryRoundProofs[idx],
+		)
+		if err != nil {
+			return fmt.Errorf("query round %d: %w", idx, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package native
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"golang.org/x/crypto/sha3"
+)
+
+// A Merkle tree digest, laid out like variables.HashOut: a single BN254 element for
+// PoseidonBN254Hash, four Goldilocks elements for PoseidonGoldilocksHash and 25 bytes for KeccakHash.
+// PoseidonBN254EmulatedHash digests are single BN254 elements too, which only the circuit splits
+// into limbs.
+type HashOut = []*big.Int
+
+// The out of circuit counterpart of hasher.Hasher.
+type Hasher interface {
+	// Hashes the leaf data, or returns it padded with zeros if it fits in a digest.
+	HashOrNoop(input []goldilocks.Element) HashOut
+	// Hashes the input, whatever its length, like plonky2's hash_no_pad.
+	HashNoPad(input []goldilocks.Element) HashOut
+	// Hashes two sibling nodes into their parent node.
+	TwoToOne(left HashOut, right HashOut) HashOut
+	// Returns the Goldilocks elements the challenger observes for the digest.
+	ToVec(hash HashOut) []goldilocks.Element
+	// The permutation of the challenger's sponge.
+	Permute(state poseidon.GoldilocksStateNative) poseidon.GoldilocksStateNative
+	// Checks that a digest of the proof is well formed, which the circuit does with range checks.
+	Check(hash HashOut) error
+}
+
+func NewHasher(hasherType types.HasherType) (Hasher, error) {
+	switch hasherType {
+	case types.PoseidonBN254Hash, types.PoseidonBN254EmulatedHash:
+		return &PoseidonBN254Hasher{}, nil
+	case types.PoseidonGoldilocksHash:
+		return &PoseidonGoldilocksHasher{}, nil
+	case types.KeccakHash:
+		return &KeccakHasher{}, nil
+	default:
+		return nil, fmt.Errorf("unknown hasher type %d", hasherType)
+	}
+}
+
+// Checks that the digest has the expected number of elements, all below the given bound.
+func checkHashElements(hash HashOut, length int, bound *big.Int) error {
+	if len(hash) != length {
+		return fmt.Errorf("digest has %d elements, expected %d", len(hash), length)
+	}
+	for i, element := range hash {
+		if element == nil || element.Sign() < 0 || element.Cmp(bound) >= 0 {
+			return fmt.Errorf("digest element %d (%v) is not below %v", i, element, bound)
+		}
+	}
+	return nil
+}
+
+func hashOutEqual(a HashOut, b HashOut) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Hashes the Merkle trees with Poseidon over BN254. The challenger still uses the Goldilocks
+// Poseidon permutation.
+type PoseidonBN254Hasher struct{}
+
+func (h *PoseidonBN254Hasher) HashOrNoop(input []goldilocks.Element) HashOut {
+	return h.fromElement(poseidon.HashOrNoopBN254Native(input))
+}
+
+func (h *PoseidonBN254Hasher) HashNoPad(input []goldilocks.Element) HashOut {
+	return h.fromElement(poseidon.HashNoPadBN254Native(input))
+}
+
+func (h *PoseidonBN254Hasher) TwoToOne(left HashOut, right HashOut) HashOut {
+	return h.fromElement(poseidon.TwoToOneBN254Native(h.toElement(left), h.toElement(right)))
+}
+
+func (h *PoseidonBN254Hasher) ToVec(hash HashOut) []goldilocks.Element {
+	return poseidon.ToVecBN254Native(h.toElement(hash))
+}
+
+func (h *PoseidonBN254Hasher) Permute(state poseidon.GoldilocksStateNative) poseidon.GoldilocksStateNative {
+	return poseidon.PoseidonNative(state)
+}
+
+func (h *PoseidonBN254Hasher) Check(hash HashOut) error {
+	return checkHashElements(hash, 1, fr.Modulus())
+}
+
+func (h *PoseidonBN254Hasher) toElement(hash HashOut) fr.Element {
+	var element fr.Element
+	element.SetBigInt(hash[0])
+	return element
+}
+
+func (h *PoseidonBN254Hasher) fromElement(element fr.Element) HashOut {
+	var res big.Int
+	element.BigInt(&res)
+	return HashOut{&res}
+}
+
+// Hashes the Merkle trees with Poseidon over Goldilocks.
+type PoseidonGoldilocksHasher struct{}
+
+func (h *PoseidonGoldilocksHasher) HashOrNoop(input []goldilocks.Element) HashOut {
+	if len(input) > poseidon.POSEIDON_GL_HASH_SIZE {
+		return h.HashNoPad(input)
+	}
+
+	elements := make([]goldilocks.Element, poseidon.POSEIDON_GL_HASH_SIZE)
+	copy(elements, input)
+	return fromGoldilocks(elements)
+}
+
+func (h *PoseidonGoldilocksHasher) HashNoPad(input []goldilocks.Element) HashOut {
+	hash := poseidon.HashNoPadNative(input)
+	return fromGoldilocks(hash[:])
+}
+
+func (h *PoseidonGoldilocksHasher) TwoToOne(left HashOut, right HashOut) HashOut {
+	var state poseidon.GoldilocksStateNative
+	copy(state[:poseidon.POSEIDON_GL_HASH_SIZE], toGoldilocks(left))
+	copy(state[poseidon.POSEIDON_GL_HASH_SIZE:], toGoldilocks(right))
+
+	state = poseidon.PoseidonNative(state)
+	return fromGoldilocks(state[:poseidon.POSEIDON_GL_HASH_SIZE])
+}
+
+func (h *PoseidonGoldilocksHasher) ToVec(hash HashOut) []goldilocks.Element {
+	return toGoldilocks(hash)
+}
+
+func (h *PoseidonGoldilocksHasher) Permute(state poseidon.GoldilocksStateNative) poseidon.GoldilocksStateNative {
+	return poseidon.PoseidonNative(state)
+}
+
+func (h *PoseidonGoldilocksHasher) Check(hash HashOut) error {
+	return checkHashElements(hash, poseidon.POSEIDON_GL_HASH_SIZE, gl.MODULUS)
+}
+
+func toGoldilocks(hash HashOut) []goldilocks.Element {
+	elements := make([]goldilocks.Element, len(hash))
+	for i := range hash {
+		elements[i].SetBigInt(hash[i])
+	}
+	return elements
+}
+
+func fromGoldilocks(elements []goldilocks.Element) HashOut {
+	hash := make(HashOut, len(elements))
+	for i := range elements {
+		hash[i] = new(big.Int).SetUint64(elements[i].Uint64())
+	}
+	return hash
+}
+
+// Hashes the Merkle trees with Keccak-256 truncated to 25 bytes, and runs the challenger's sponge
+// with plonky2's KeccakPermutation.
+type KeccakHasher struct{}
+
+const keccakHashSize = 25
+const keccakBytesPerElement = 7
+
+func (h *KeccakHasher) HashOrNoop(input []goldilocks.Element) HashOut {
+	inputBytes := elementsToBytes(input)
+	if len(inputBytes) > keccakHashSize {
+		return fromBytes(keccak256(inputBytes)[:keccakHashSize])
+	}
+
+	padded := make([]byte, keccakHashSize)
+	copy(padded, inputBytes)
+	return fromBytes(padded)
+}
+
+func (h *KeccakHasher) HashNoPad(input []goldilocks.Element) HashOut {
+	return fromBytes(keccak256(elementsToBytes(input))[:keccakHashSize])
+}
+
+func (h *KeccakHasher) TwoToOne(left HashOut, right HashOut) HashOut {
+	return fromBytes(keccak256(append(toBytes(left), toBytes(right)...))[:keccakHashSize])
+}
+
+// Packs the digest into little-endian chunks of 7 bytes, the way plonky2's BytesHash does.
+func (h *KeccakHasher) ToVec(hash HashOut) []goldilocks.Element {
+	hashBytes := toBytes(hash)
+	elements := []goldilocks.Element{}
+	for i := 0; i < len(hashBytes); i += keccakBytesPerElement {
+		var chunk [8]byte
+		copy(chunk[:], hashBytes[i:min(len(hashBytes), i+keccakBytesPerElement)])
+		elements = append(elements, goldilocks.NewElement(binary.LittleEndian.Uint64(chunk[:])))
+	}
+	return elements
+}
+
+// plonky2's KeccakPermutation: the state is serialized as little-endian words and repeatedly
+// hashed, and the output state is read from the resulting little-endian words, skipping the words
+// that aren't canonical Goldilocks elements.
+func (h *KeccakHasher) Permute(state poseidon.GoldilocksStateNative) poseidon.GoldilocksStateNative {
+	digest := elementsToBytes(state[:])
+
+	var output poseidon.GoldilocksStateNative
+	for i := 0; i < poseidon.SPONGE_WIDTH; {
+		digest = keccak256(digest)
+		for j := 0; j+8 <= len(digest) && i < poseidon.SPONGE_WIDTH; j += 8 {
+			word := binary.LittleEndian.Uint64(digest[j : j+8])
+			if word < gl.MODULUS_UINT64 {
+				output[i] = goldilocks.NewElement(word)
+				i++
+			}
+		}
+	}
+
+	return output
+}
+
+func (h *KeccakHasher) Check(hash HashOut) error {
+	return checkHashElements(hash, keccakHashSize, big.NewInt(256))
+}
+
+func keccak256(input []byte) []byte {
+	keccak := sha3.NewLegacyKeccak256()
+	keccak.Write(input)
+	return keccak.Sum(nil)
+}
+
+func elementsToBytes(elements []goldilocks.Element) []byte {
+	res := make([]byte, 0, 8*len(elements))
+	for _, element := range elements {
+		res = binary.LittleEndian.AppendUint64(res, element.Uint64())
+	}
+	return res
+}
+
+func toBytes(hash HashOut) []byte {
+	hashBytes := make([]byte, len(hash))
+	for i := range hash {
+		hashBytes[i] = byte(hash[i].Uint64())
+	}
+	return hashBytes
+}
+
+func fromBytes(hashBytes []byte) HashOut {
+	hash := make(HashOut, len(hashBytes))
+	for i := range hashBytes {
+		hash[i] = new(big.Int).SetUint64(uint64(hashBytes[i]))
+	}
+	return hash
+}
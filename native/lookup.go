@@ -0,0 +1,177 @@
+package native
+
+import (
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+)
+
+// The out of circuit counterpart of plonk.PlonkChip.evalLutPoly.
+func (v *Verifier) evalLutPoly(lutIndex int, deltas []goldilocks.Element, degree uint64) goldilocks.Element {
+	lut := v.commonData.Luts[lutIndex]
+
+	var inputsEval, outputsEval goldilocks.Element
+	delta := deltas[plonk.LOOKUP_CHALLENGE_DELTA]
+	for i := uint64(0); i < degree; i++ {
+		inputsEval.Mul(&inputsEval, &delta)
+		outputsEval.Mul(&outputsEval, &delta)
+		if i < uint64(len(lut)) {
+			input := goldilocks.NewElement(uint64(lut[i][0]))
+			output := goldilocks.NewElement(uint64(lut[i][1]))
+			inputsEval.Add(&inputsEval, &input)
+			outputsEval.Add(&outputsEval, &output)
+		}
+	}
+
+	var res goldilocks.Element
+	res.Mul(&deltas[plonk.LOOKUP_CHALLENGE_B], &outputsEval).Add(&res, &inputsEval)
+	return res
+}
+
+// Returns prod_{j in [start, end), j != skip} (alpha - combos[j]). Pass skip >= end to include every term.
+func lookupProduct(
+	alpha gl.QuadraticExtension,
+	combos []gl.QuadraticExtension,
+	start uint64,
+	end uint64,
+	skip uint64,
+) gl.QuadraticExtension {
+	product := gl.OneExtensionNative()
+	for j := start; j < end; j++ {
+		if j == skip {
+			continue
+		}
+		product = product.Mul(alpha.Sub(combos[j]))
+	}
+	return product
+}
+
+// The out of circuit counterpart of plonk.PlonkChip.checkLookupConstraints.
+func (v *Verifier) checkLookupConstraints(
+	challengeNum uint64,
+	openings OpeningSet,
+	deltas []goldilocks.Element,
+) []gl.QuadraticExtension {
+	numRoutedWires := v.commonData.Config.NumRoutedWires
+	numLuSlots := gates.LookupGateNumSlots(numRoutedWires)
+	numLutSlots := gates.LookupTableGateNumSlots(numRoutedWires)
+	luDegree := v.commonData.QuotientDegreeFactor - 1
+	numLookupPolys := v.commonData.NumLookupPolys
+	numSldcPolys := numLookupPolys - 1
+	lutDegree := (numLutSlots + numSldcPolys - 1) / numSldcPolys
+
+	numSelectors := v.commonData.SelectorsInfo.NumSelectors()
+	numLookupSelectors := v.commonData.SelectorsInfo.NumLookupSelectors()
+	lookupSelectors := openings.Constants[numSelectors : numSelectors+numLookupSelectors]
+
+	localLookupZs := openings.LookupZs[challengeNum*numLookupPolys : (challengeNum+1)*numLookupPolys]
+	nextLookupZs := openings.LookupZsNext[challengeNum*numLookupPolys : (challengeNum+1)*numLookupPolys]
+
+	// RE is the first polynomial stored, the partial Sums and LDCs are stored in the remaining SLDC polynomials.
+	zRe := localLookupZs[0]
+	nextZRe := nextLookupZs[0]
+	zXLookupSldcs := localLookupZs[1:]
+	zGXLookupSldcs := nextLookupZs[1:]
+
+	challengeA := deltas[plonk.LOOKUP_CHALLENGE_A]
+	challengeB := deltas[plonk.LOOKUP_CHALLENGE_B]
+	challengeAlpha := gl.ToQuadraticExtension(deltas[plonk.LOOKUP_CHALLENGE_ALPHA])
+	challengeDelta := deltas[plonk.LOOKUP_CHALLENGE_DELTA]
+
+	wires := openings.Wires
+
+	// Compute all current looked and looking combos, i.e. the combos we need for the SLDC polynomials.
+	currentLookedCombos := make([]gl.QuadraticExtension, numLutSlots)
+	for s := uint64(0); s < numLutSlots; s++ {
+		inputWire := wires[gates.LookupTableGateWireIthLookedInp(s)]
+		outputWire := wires[gates.LookupTableGateWireIthLookedOut(s)]
+		currentLookedCombos[s] = inputWire.Add(outputWire.ScalarMul(challengeA))
+	}
+
+	currentLookingCombos := make([]gl.QuadraticExtension, numLuSlots)
+	for s := uint64(0); s < numLuSlots; s++ {
+		inputWire := wires[gates.LookupGateWireIthLookingInp(s)]
+		outputWire := wires[gates.LookupGateWireIthLookingOut(s)]
+		currentLookingCombos[s] = inputWire.Add(outputWire.ScalarMul(challengeA))
+	}
+
+	// Compute all current lookup combos, i.e. the combos used to check that the LUT is correct.
+	currentLookupCombos := make([]gl.QuadraticExtension, numLutSlots)
+	for s := uint64(0); s < numLutSlots; s++ {
+		inputWire := wires[gates.LookupTableGateWireIthLookedInp(s)]
+		outputWire := wires[gates.LookupTableGateWireIthLookedOut(s)]
+		currentLookupCombos[s] = inputWire.Add(outputWire.ScalarMul(challengeB))
+	}
+
+	constraints := make([]gl.QuadraticExtension, 0, 4+uint64(len(v.commonData.Luts))+2*numSldcPolys)
+
+	// Check last LDC constraint.
+	constraints = append(constraints, lookupSelectors[plonk.LOOKUP_SELECTOR_LAST_LDC].Mul(zXLookupSldcs[numSldcPolys-1]))
+
+	// Check initial Sum constraint.
+	constraints = append(constraints, lookupSelectors[plonk.LOOKUP_SELECTOR_INIT_SRE].Mul(zXLookupSldcs[0]))
+
+	// Check initial RE constraint.
+	constraints = append(constraints, lookupSelectors[plonk.LOOKUP_SELECTOR_INIT_SRE].Mul(zRe))
+
+	// Check final RE constraints for each different LUT.
+	for r := uint64(plonk.LOOKUP_SELECTOR_START_END); r < numLookupSelectors; r++ {
+		lutIndex := int(r - plonk.LOOKUP_SELECTOR_START_END)
+		lutRowNumber := (uint64(len(v.commonData.Luts[lutIndex])) + numLutSlots - 1) / numLutSlots
+		curFunctionEval := v.evalLutPoly(lutIndex, deltas, numLutSlots*lutRowNumber)
+
+		constraints = append(constraints, lookupSelectors[r].Mul(zRe.Sub(gl.ToQuadraticExtension(curFunctionEval))))
+	}
+
+	// Check RE row transition constraint.
+	curSum := nextZRe
+	for _, combo := range currentLookupCombos {
+		curSum = curSum.ScalarMul(challengeDelta).Add(combo)
+	}
+	constraints = append(constraints, lookupSelectors[plonk.LOOKUP_SELECTOR_TRANS_SRE].Mul(zRe.Sub(curSum)))
+
+	for poly := uint64(0); poly < numSldcPolys; poly++ {
+		lutStart := poly * lutDegree
+		lutEnd := min((poly+1)*lutDegree, numLutSlots)
+		luStart := poly * luDegree
+		luEnd := min((poly+1)*luDegree, numLuSlots)
+
+		// Compute prod(alpha - combo) for the current slot for Sum and LDC.
+		lutProd := lookupProduct(challengeAlpha, currentLookedCombos, lutStart, lutEnd, lutEnd)
+		luProd := lookupProduct(challengeAlpha, currentLookingCombos, luStart, luEnd, luEnd)
+
+		// Compute sum_i(prod_{j!=i}(alpha - combo_j)) for LDC.
+		luSumProds := gl.ZeroExtensionNative()
+		for i := luStart; i < luEnd; i++ {
+			luSumProds = luSumProds.Add(lookupProduct(challengeAlpha, currentLookingCombos, luStart, luEnd, i))
+		}
+
+		// Compute sum_i(mul_i.prod_{j!=i}(alpha - combo_j)) for Sum.
+		lutSumProdsWithMul := gl.ZeroExtensionNative()
+		for i := lutStart; i < lutEnd; i++ {
+			lutSumProdsWithMul = lutSumProdsWithMul.Add(
+				wires[gates.LookupTableGateWireIthMultiplicity(i)].Mul(
+					lookupProduct(challengeAlpha, currentLookedCombos, lutStart, lutEnd, i),
+				),
+			)
+		}
+
+		// The previous element is the previous poly of the current row or the last poly of the next row.
+		prev := zGXLookupSldcs[numSldcPolys-1]
+		if poly != 0 {
+			prev = zXLookupSldcs[poly-1]
+		}
+		diff := zXLookupSldcs[poly].Sub(prev)
+
+		// Check Sum row and col transitions. It's the same constraint, with a row transition happening for slot == 0.
+		unfilteredSumTransition := lutProd.Mul(diff).Sub(lutSumProdsWithMul)
+		constraints = append(constraints, lookupSelectors[plonk.LOOKUP_SELECTOR_TRANS_SRE].Mul(unfilteredSumTransition))
+
+		// Check LDC row and col transitions. It's the same constraint, with a row transition happening for slot == 0.
+		unfilteredLdcTransition := luProd.Mul(diff).Add(luSumProds)
+		constraints = append(constraints, lookupSelectors[plonk.LOOKUP_SELECTOR_TRANS_LDC].Mul(unfilteredLdcTransition))
+	}
+
+	return constraints
+}
@@ -0,0 +1,208 @@
+package native
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// Returns the common circuit data of decode_block with two lookup tables, whose lookup selectors
+// follow its gate selectors in the constant polynomials.
+func lookupCommonCircuitData(t *testing.T) types.CommonCircuitData {
+	path := "../testdata/decode_block/common_circuit_data.json"
+	common := types.ReadCommonCircuitData(path)
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw types.CommonCircuitDataRaw
+	if err := json.Unmarshal(rawBytes, &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	// A table shorter than the LookupTableGate's slots, and one spanning several of its rows.
+	squares := make(types.LookupTable, 60)
+	for i := range squares {
+		squares[i] = [2]uint16{uint16(i), uint16(i * i)}
+	}
+	common.Luts = []types.LookupTable{{{0, 1}, {1, 2}, {2, 4}}, squares}
+
+	numLookupSelectors := uint64(plonk.LOOKUP_SELECTOR_START_END + len(common.Luts))
+	var groupStarts, groupEnds []uint64
+	for _, group := range raw.SelectorsInfo.Groups {
+		groupStarts = append(groupStarts, group.Start)
+		groupEnds = append(groupEnds, group.End)
+	}
+	common.SelectorsInfo = *gates.NewSelectorsInfo(raw.SelectorsInfo.SelectorIndices, groupStarts, groupEnds, numLookupSelectors)
+	common.NumConstants += numLookupSelectors
+
+	// One RE polynomial, and enough SLDC polynomials for the LookupGate's slots.
+	luDegree := common.QuotientDegreeFactor - 1
+	common.NumLookupPolys = 1 + (gates.LookupGateNumSlots(common.Config.NumRoutedWires)+luDegree-1)/luDegree
+	return common
+}
+
+type testVanishingPolyCircuit struct {
+	PlonkBetas       []gl.Variable
+	PlonkGammas      []gl.Variable
+	PlonkAlphas      []gl.Variable
+	PlonkDeltas      []gl.Variable
+	PlonkZeta        gl.QuadraticExtensionVariable
+	Openings         variables.OpeningSet
+	PublicInputsHash poseidon.GoldilocksHashOut
+
+	CommonCircuitData types.CommonCircuitData `gnark:"-"`
+}
+
+func (c *testVanishingPolyCircuit) Define(api frontend.API) error {
+	plonkChip, err := plonk.NewPlonkChip(api, c.CommonCircuitData, nil)
+	if err != nil {
+		return err
+	}
+	challenges := variables.ProofChallenges{
+		PlonkBetas:  c.PlonkBetas,
+		PlonkGammas: c.PlonkGammas,
+		PlonkAlphas: c.PlonkAlphas,
+		PlonkDeltas: c.PlonkDeltas,
+		PlonkZeta:   c.PlonkZeta,
+	}
+	plonkChip.Verify(challenges, c.Openings, c.PublicInputsHash)
+	return nil
+}
+
+// Checks that the circuit evaluates the lookup vanishing terms like the native verifier, on random
+// openings and challenges: the quotient polynomials are set so that the native vanishing polynomial
+// is Z_H(zeta) * t(zeta), which the circuit only accepts if its vanishing polynomial is the same.
+func TestLookupVanishingTermsMatchCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	common := lookupCommonCircuitData(t)
+	v, err := NewVerifier(common)
+	assert.NoError(err)
+
+	randomElements := func(n uint64) []uint64 {
+		elements := make([]uint64, n)
+		for i := range elements {
+			elements[i] = rng.Uint64N(gl.MODULUS_UINT64)
+		}
+		return elements
+	}
+	randomExtensions := func(n uint64) [][]uint64 {
+		extensions := make([][]uint64, n)
+		for i := range extensions {
+			extensions[i] = randomElements(2)
+		}
+		return extensions
+	}
+
+	numChallenges := common.Config.NumChallenges
+	var raw types.ProofWithPublicInputsRaw
+	openings := &raw.Proof.Openings
+	openings.Constants = randomExtensions(common.NumConstants)
+	openings.PlonkSigmas = randomExtensions(common.Config.NumRoutedWires)
+	openings.Wires = randomExtensions(common.Config.NumWires)
+	openings.PlonkZs = randomExtensions(numChallenges)
+	openings.PlonkZsNext = randomExtensions(numChallenges)
+	openings.PartialProducts = randomExtensions(numChallenges * common.NumPartialProducts)
+	openings.QuotientPolys = make([][]uint64, numChallenges*common.QuotientDegreeFactor)
+	openings.LookupZs = randomExtensions(numChallenges * common.NumLookupPolys)
+	openings.LookupZsNext = randomExtensions(numChallenges * common.NumLookupPolys)
+
+	betas, gammas, alphas := randomElements(numChallenges), randomElements(numChallenges), randomElements(numChallenges)
+	deltas := randomElements(plonk.NUM_COINS_LOOKUP * numChallenges)
+	zeta := randomElements(2)
+	publicInputsHash := randomElements(poseidon.POSEIDON_GL_HASH_SIZE)
+
+	toElements := func(xs []uint64) []goldilocks.Element {
+		elements, err := newElements(xs)
+		assert.NoError(err)
+		return elements
+	}
+	challenges := ProofChallenges{
+		PlonkBetas:  toElements(betas),
+		PlonkGammas: toElements(gammas),
+		PlonkAlphas: toElements(alphas),
+		PlonkDeltas: toElements(deltas),
+		PlonkZeta:   gl.NewQuadraticExtensionUint64(zeta[0], zeta[1]),
+	}
+	var hash poseidon.GoldilocksHashOutNative
+	copy(hash[:], toElements(publicInputsHash))
+
+	nativeOpenings := func() OpeningSet {
+		var nativeOpenings OpeningSet
+		for _, field := range []struct {
+			dst *[]gl.QuadraticExtension
+			src [][]uint64
+		}{
+			{&nativeOpenings.Constants, openings.Constants},
+			{&nativeOpenings.PlonkSigmas, openings.PlonkSigmas},
+			{&nativeOpenings.Wires, openings.Wires},
+			{&nativeOpenings.PlonkZs, openings.PlonkZs},
+			{&nativeOpenings.PlonkZsNext, openings.PlonkZsNext},
+			{&nativeOpenings.PartialProducts, openings.PartialProducts},
+			{&nativeOpenings.LookupZs, openings.LookupZs},
+			{&nativeOpenings.LookupZsNext, openings.LookupZsNext},
+		} {
+			*field.dst, err = newExtensions(field.src)
+			assert.NoError(err)
+		}
+		return nativeOpenings
+	}()
+
+	// Sets the quotient polynomials from the native vanishing polynomial, with t = t_0.
+	zetaPowN := challenges.PlonkZeta.ExpPowerOf2(common.DegreeBits)
+	vars := gates.NewNativeEvaluationVars(nativeOpenings.Constants, nativeOpenings.Wires, hash)
+	vanishingPolysZeta, err := v.evalVanishingPoly(*vars, challenges, nativeOpenings, zetaPowN)
+	assert.NoError(err)
+	zHZeta := zetaPowN.Sub(gl.OneExtensionNative())
+	for i := range openings.QuotientPolys {
+		openings.QuotientPolys[i] = []uint64{0, 0}
+	}
+	for i, vanishingPolyZeta := range vanishingPolysZeta {
+		quotient := vanishingPolyZeta.Div(zHZeta).Uint64s()
+		openings.QuotientPolys[uint64(i)*common.QuotientDegreeFactor] = quotient[:]
+	}
+	nativeOpenings.QuotientPolys, err = newExtensions(openings.QuotientPolys)
+	assert.NoError(err)
+	assert.NoError(v.verifyPlonk(challenges, nativeOpenings, hash))
+
+	circuit := testVanishingPolyCircuit{
+		PlonkBetas:        make([]gl.Variable, numChallenges),
+		PlonkGammas:       make([]gl.Variable, numChallenges),
+		PlonkAlphas:       make([]gl.Variable, numChallenges),
+		PlonkDeltas:       make([]gl.Variable, len(deltas)),
+		Openings:          variables.NewOpeningSet(&common),
+		CommonCircuitData: common,
+	}
+	newAssignment := func() *testVanishingPolyCircuit {
+		var assignment testVanishingPolyCircuit
+		assignment.PlonkBetas = gl.Uint64ArrayToVariableArray(betas)
+		assignment.PlonkGammas = gl.Uint64ArrayToVariableArray(gammas)
+		assignment.PlonkAlphas = gl.Uint64ArrayToVariableArray(alphas)
+		assignment.PlonkDeltas = gl.Uint64ArrayToVariableArray(deltas)
+		assignment.PlonkZeta = gl.Uint64ArrayToQuadraticExtension(zeta)
+		assignment.Openings = variables.DeserializeProofWithPublicInputs(raw).Proof.Openings
+		for i, element := range publicInputsHash {
+			assignment.PublicInputsHash[i] = gl.NewVariable(element)
+		}
+		return &assignment
+	}
+	assert.NoError(test.IsSolved(&circuit, newAssignment(), ecc.BN254.ScalarField()))
+
+	// A wrong opening of the RE polynomial breaks the lookup terms.
+	openings.LookupZs[0][0] = (openings.LookupZs[0][0] + 1) % gl.MODULUS_UINT64
+	assert.Error(test.IsSolved(&circuit, newAssignment(), ecc.BN254.ScalarField()))
+}
@@ -0,0 +1,165 @@
+package native
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+)
+
+// The out of circuit counterpart of plonk.PlonkChip.Verify: checks that the vanishing polynomial
+// evaluated at zeta matches the opened quotient polynomials.
+func (v *Verifier) verifyPlonk(
+	proofChallenges ProofChallenges,
+	openings OpeningSet,
+	publicInputsHash poseidon.GoldilocksHashOutNative,
+) error {
+	zetaPowN := proofChallenges.PlonkZeta.ExpPowerOf2(v.commonData.DegreeBits)
+
+	vars := gates.NewNativeEvaluationVars(openings.Constants, openings.Wires, publicInputsHash)
+
+	vanishingPolysZeta, err := v.evalVanishingPoly(*vars, proofChallenges, openings, zetaPowN)
+	if err != nil {
+		return err
+	}
+
+	// Calculate Z(H)
+	zHZeta := zetaPowN.Sub(gl.OneExtensionNative())
+
+	// Each chunk of `quotient_degree_factor` quotient polynomial openings holds the evaluations
+	// of `t_0(zeta),...,t_{quotient_degree_factor-1}(zeta)`, where the "real" quotient polynomial
+	// is `t(X) = t_0(X) + t_1(X)*X^n + t_2(X)*X^{2n} + ...`.
+	quotientDegreeFactor := int(v.commonData.QuotientDegreeFactor)
+	for i, vanishingPolyZeta := range vanishingPolysZeta {
+		quotientPolys := openings.QuotientPolys[i*quotientDegreeFactor : (i+1)*quotientDegreeFactor]
+		prod := zHZeta.Mul(gl.ReduceWithPowersNative(quotientPolys, zetaPowN))
+		if !vanishingPolyZeta.Equal(prod) {
+			return fmt.Errorf(
+				"vanishing polynomial %d at zeta is %v but Z_H(zeta) * t(zeta) is %v",
+				i, vanishingPolyZeta.Uint64s(), prod.Uint64s(),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (v *Verifier) evalL0(x gl.QuadraticExtension, xPowN gl.QuadraticExtension) (gl.QuadraticExtension, error) {
+	// L_0(x) = (x^n - 1) / (n * (x - 1))
+	n := goldilocks.NewElement(1 << v.commonData.DegreeBits)
+	denominator := x.Sub(gl.OneExtensionNative()).ScalarMul(n)
+	if denominator.IsZero() {
+		return gl.QuadraticExtension{}, errors.New("zeta is in the subgroup H, L_0(zeta) is undefined")
+	}
+	return xPowN.Sub(gl.OneExtensionNative()).Div(denominator), nil
+}
+
+func (v *Verifier) checkPartialProducts(
+	numerators []gl.QuadraticExtension,
+	denominators []gl.QuadraticExtension,
+	challengeNum uint64,
+	openings OpeningSet,
+) []gl.QuadraticExtension {
+	numPartProds := v.commonData.NumPartialProducts
+	quotDegreeFactor := v.commonData.QuotientDegreeFactor
+
+	productAccs := make([]gl.QuadraticExtension, 0, numPartProds+2)
+	productAccs = append(productAccs, openings.PlonkZs[challengeNum])
+	productAccs = append(productAccs, openings.PartialProducts[challengeNum*numPartProds:(challengeNum+1)*numPartProds]...)
+	productAccs = append(productAccs, openings.PlonkZsNext[challengeNum])
+
+	partialProductChecks := make([]gl.QuadraticExtension, 0, numPartProds+1)
+	for i := uint64(0); i <= numPartProds; i++ {
+		ppStartIdx := i * quotDegreeFactor
+		numeProduct := numerators[ppStartIdx]
+		denoProduct := denominators[ppStartIdx]
+		for j := uint64(1); j < quotDegreeFactor; j++ {
+			numeProduct = numeProduct.Mul(numerators[ppStartIdx+j])
+			denoProduct = denoProduct.Mul(denominators[ppStartIdx+j])
+		}
+
+		partialProductChecks = append(
+			partialProductChecks,
+			productAccs[i].Mul(numeProduct).Sub(productAccs[i+1].Mul(denoProduct)),
+		)
+	}
+	return partialProductChecks
+}
+
+func (v *Verifier) evalVanishingPoly(
+	vars gates.NativeEvaluationVars,
+	proofChallenges ProofChallenges,
+	openings OpeningSet,
+	zetaPowN gl.QuadraticExtension,
+) ([]gl.QuadraticExtension, error) {
+	config := v.commonData.Config
+	constraintTerms := gates.EvaluateGateConstraintsNative(
+		v.gates,
+		v.commonData.NumGateConstraints,
+		v.commonData.SelectorsInfo,
+		vars,
+	)
+
+	// Calculate the k[i] * x
+	sIDs := make([]gl.QuadraticExtension, config.NumRoutedWires)
+	for i := range sIDs {
+		sIDs[i] = proofChallenges.PlonkZeta.ScalarMul(goldilocks.NewElement(v.commonData.KIs[i]))
+	}
+
+	// Calculate L_0(zeta)
+	l0Zeta, err := v.evalL0(proofChallenges.PlonkZeta, zetaPowN)
+	if err != nil {
+		return nil, err
+	}
+
+	vanishingZ1Terms := make([]gl.QuadraticExtension, 0, config.NumChallenges)
+	vanishingPartialProductsTerms := make([]gl.QuadraticExtension, 0, config.NumChallenges*v.commonData.NumPartialProducts)
+	vanishingAllLookupTerms := []gl.QuadraticExtension{}
+	for i := uint64(0); i < config.NumChallenges; i++ {
+		// L_0(zeta) (Z(zeta) - 1) = 0
+		vanishingZ1Terms = append(vanishingZ1Terms, l0Zeta.Mul(openings.PlonkZs[i].Sub(gl.OneExtensionNative())))
+
+		if v.commonData.NumLookupPolys != 0 {
+			curDeltas := proofChallenges.PlonkDeltas[plonk.NUM_COINS_LOOKUP*i : plonk.NUM_COINS_LOOKUP*(i+1)]
+			vanishingAllLookupTerms = append(vanishingAllLookupTerms, v.checkLookupConstraints(i, openings, curDeltas)...)
+		}
+
+		beta := gl.ToQuadraticExtension(proofChallenges.PlonkBetas[i])
+		gamma := gl.ToQuadraticExtension(proofChallenges.PlonkGammas[i])
+		numeratorValues := make([]gl.QuadraticExtension, 0, config.NumRoutedWires)
+		denominatorValues := make([]gl.QuadraticExtension, 0, config.NumRoutedWires)
+		for j := uint64(0); j < config.NumRoutedWires; j++ {
+			// The numerator is `beta * s_id + wire_value + gamma`, and the denominator is
+			// `beta * s_sigma + wire_value + gamma`.
+			wireValuePlusGamma := openings.Wires[j].Add(gamma)
+			numeratorValues = append(numeratorValues, beta.Mul(sIDs[j]).Add(wireValuePlusGamma))
+			denominatorValues = append(denominatorValues, beta.Mul(openings.PlonkSigmas[j]).Add(wireValuePlusGamma))
+		}
+
+		vanishingPartialProductsTerms = append(
+			vanishingPartialProductsTerms,
+			v.checkPartialProducts(numeratorValues, denominatorValues, i, openings)...,
+		)
+	}
+
+	vanishingTerms := append(vanishingZ1Terms, vanishingPartialProductsTerms...)
+	vanishingTerms = append(vanishingTerms, vanishingAllLookupTerms...)
+	vanishingTerms = append(vanishingTerms, constraintTerms...)
+
+	reducedValues := make([]gl.QuadraticExtension, config.NumChallenges)
+	for j := range reducedValues {
+		reducedValues[j] = gl.ZeroExtensionNative()
+	}
+
+	for i := len(vanishingTerms) - 1; i >= 0; i-- {
+		for j := range reducedValues {
+			reducedValues[j] = vanishingTerms[i].Add(reducedValues[j].ScalarMul(proofChallenges.PlonkAlphas[j]))
+		}
+	}
+
+	return reducedValues, nil
+}
@@ -0,0 +1,290 @@
+package native
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// The out of circuit counterparts of the proof types of the variables package.
+
+type FriMerkleCap = []HashOut
+
+type FriMerkleProof struct {
+	Siblings []HashOut
+}
+
+type FriEvalProof struct {
+	Elements    []goldilocks.Element
+	MerkleProof FriMerkleProof
+}
+
+type FriInitialTreeProof struct {
+	EvalsProofs []FriEvalProof
+}
+
+type FriQueryStep struct {
+	Evals       []gl.QuadraticExtension
+	MerkleProof FriMerkleProof
+}
+
+type FriQueryRound struct {
+	InitialTreesProof FriInitialTreeProof
+	Steps             []FriQueryStep
+}
+
+type FriProof struct {
+	CommitPhaseMerkleCaps []FriMerkleCap
+	QueryRoundProofs      []FriQueryRound
+	FinalPoly             []gl.QuadraticExtension
+	PowWitness            goldilocks.Element
+}
+
+type OpeningSet struct {
+	Constants       []gl.QuadraticExtension
+	PlonkSigmas     []gl.QuadraticExtension
+	Wires           []gl.QuadraticExtension
+	PlonkZs         []gl.QuadraticExtension
+	PlonkZsNext     []gl.QuadraticExtension
+	PartialProducts []gl.QuadraticExtension
+	QuotientPolys   []gl.QuadraticExtension
+	LookupZs        []gl.QuadraticExtension
+	LookupZsNext    []gl.QuadraticExtension
+}
+
+type Proof struct {
+	WiresCap                  FriMerkleCap
+	PlonkZsPartialProductsCap FriMerkleCap
+	QuotientPolysCap          FriMerkleCap
+	Openings                  OpeningSet
+	OpeningProof              FriProof
+}
+
+type ProofWithPublicInputs struct {
+	Proof        Proof
+	PublicInputs []goldilocks.Element
+}
+
+type VerifierOnlyCircuitData struct {
+	ConstantSigmasCap FriMerkleCap
+	CircuitDigest     HashOut
+}
+
+type FriChallenges struct {
+	FriAlpha        gl.QuadraticExtension
+	FriBetas        []gl.QuadraticExtension
+	FriPowResponse  goldilocks.Element
+	FriQueryIndices []goldilocks.Element
+}
+
+type ProofChallenges struct {
+	PlonkBetas    []goldilocks.Element
+	PlonkGammas   []goldilocks.Element
+	PlonkAlphas   []goldilocks.Element
+	PlonkDeltas   []goldilocks.Element // Empty for circuits without lookups
+	PlonkZeta     gl.QuadraticExtension
+	FriChallenges FriChallenges
+}
+
+// Converts a canonical uint64 to a field element. Like the circuit's range checks, it rejects
+// non-canonical values.
+func newElement(x uint64) (goldilocks.Element, error) {
+	if x >= gl.MODULUS_UINT64 {
+		return goldilocks.Element{}, fmt.Errorf("%d is not a canonical Goldilocks element", x)
+	}
+	return goldilocks.NewElement(x), nil
+}
+
+func newElements(xs []uint64) ([]goldilocks.Element, error) {
+	elements := make([]goldilocks.Element, len(xs))
+	for i, x := range xs {
+		var err error
+		if elements[i], err = newElement(x); err != nil {
+			return nil, err
+		}
+	}
+	return elements, nil
+}
+
+func newExtensions(xs [][]uint64) ([]gl.QuadraticExtension, error) {
+	extensions := make([]gl.QuadraticExtension, len(xs))
+	for i, x := range xs {
+		if len(x) != 2 {
+			return nil, fmt.Errorf("extension element %d has %d coordinates", i, len(x))
+		}
+		elements, err := newElements(x)
+		if err != nil {
+			return nil, err
+		}
+		extensions[i] = gl.NewQuadraticExtension(elements[0], elements[1])
+	}
+	return extensions, nil
+}
+
+func newHashOut(hasher Hasher, raw types.HashOutRaw) (HashOut, error) {
+	if err := hasher.Check(raw.Elements); err != nil {
+		return nil, err
+	}
+	return raw.Elements, nil
+}
+
+func newHashOuts(hasher Hasher, raws []types.HashOutRaw) ([]HashOut, error) {
+	hashes := make([]HashOut, len(raws))
+	for i, raw := range raws {
+		var err error
+		if hashes[i], err = newHashOut(hasher, raw); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// Converts a deserialized proof to its native representation, checking that its field elements are
+// canonical and its digests well formed for the hasher.
+func NewProofWithPublicInputs(hasher Hasher, raw types.ProofWithPublicInputsRaw) (ProofWithPublicInputs, error) {
+	var proofWithPis ProofWithPublicInputs
+	var err error
+
+	// Like the circuit, which reduces them before hashing, the public inputs may be non-canonical.
+	proofWithPis.PublicInputs = make([]goldilocks.Element, len(raw.PublicInputs))
+	for i, publicInput := range raw.PublicInputs {
+		proofWithPis.PublicInputs[i] = goldilocks.NewElement(publicInput)
+	}
+
+	proof := &proofWithPis.Proof
+	if proof.WiresCap, err = newHashOuts(hasher, raw.Proof.WiresCap); err != nil {
+		return proofWithPis, fmt.Errorf("wires cap: %w", err)
+	}
+	if proof.PlonkZsPartialProductsCap, err = newHashOuts(hasher, raw.Proof.PlonkZsPartialProductsCap); err != nil {
+		return proofWithPis, fmt.Errorf("plonk zs partial products cap: %w", err)
+	}
+	if proof.QuotientPolysCap, err = newHashOuts(hasher, raw.Proof.QuotientPolysCap); err != nil {
+		return proofWithPis, fmt.Errorf("quotient polys cap: %w", err)
+	}
+
+	rawOpenings := raw.Proof.Openings
+	openings := &proof.Openings
+	for _, field := range []struct {
+		name string
+		raw  [][]uint64
+		dst  *[]gl.QuadraticExtension
+	}{
+		{"constants", rawOpenings.Constants, &openings.Constants},
+		{"plonk sigmas", rawOpenings.PlonkSigmas, &openings.PlonkSigmas},
+		{"wires", rawOpenings.Wires, &openings.Wires},
+		{"plonk zs", rawOpenings.PlonkZs, &openings.PlonkZs},
+		{"plonk zs next", rawOpenings.PlonkZsNext, &openings.PlonkZsNext},
+		{"partial products", rawOpenings.PartialProducts, &openings.PartialProducts},
+		{"quotient polys", rawOpenings.QuotientPolys, &openings.QuotientPolys},
+		{"lookup zs", rawOpenings.LookupZs, &openings.LookupZs},
+		{"lookup zs next", rawOpenings.LookupZsNext, &openings.LookupZsNext},
+	} {
+		if *field.dst, err = newExtensions(field.raw); err != nil {
+			return proofWithPis, fmt.Errorf("%s openings: %w", field.name, err)
+		}
+	}
+
+	rawFriProof := raw.Proof.OpeningProof
+	friProof := &proof.OpeningProof
+	if friProof.PowWitness, err = newElement(rawFriProof.PowWitness); err != nil {
+		return proofWithPis, fmt.Errorf("pow witness: %w", err)
+	}
+	if friProof.FinalPoly, err = newExtensions(rawFriProof.FinalPoly.Coeffs); err != nil {
+		return proofWithPis, fmt.Errorf("final poly: %w", err)
+	}
+
+	friProof.CommitPhaseMerkleCaps = make([]FriMerkleCap, len(rawFriProof.CommitPhaseMerkleCaps))
+	for i, rawCap := range rawFriProof.CommitPhaseMerkleCaps {
+		if friProof.CommitPhaseMerkleCaps[i], err = newHashOuts(hasher, rawCap); err != nil {
+			return proofWithPis, fmt.Errorf("commit phase merkle cap %d: %w", i, err)
+		}
+	}
+
+	friProof.QueryRoundProofs = make([]FriQueryRound, len(rawFriProof.QueryRoundProofs))
+	for i, rawRound := range rawFriProof.QueryRoundProofs {
+		round := &friProof.QueryRoundProofs[i]
+
+		round.InitialTreesProof.EvalsProofs = make([]FriEvalProof, len(rawRound.InitialTreesProof.EvalsProofs))
+		for j, rawEvalsProof := range rawRound.InitialTreesProof.EvalsProofs {
+			evalsProof := &round.InitialTreesProof.EvalsProofs[j]
+			if evalsProof.Elements, err = newElements(rawEvalsProof.LeafElements); err != nil {
+				return proofWithPis, fmt.Errorf("query round %d, initial tree %d leaf: %w", i, j, err)
+			}
+			if evalsProof.MerkleProof.Siblings, err = newHashOuts(hasher, rawEvalsProof.MerkleProof.Hash); err != nil {
+				return proofWithPis, fmt.Errorf("query round %d, initial tree %d merkle proof: %w", i, j, err)
+			}
+		}
+
+		round.Steps = make([]FriQueryStep, len(rawRound.Steps))
+		for j, rawStep := range rawRound.Steps {
+			if round.Steps[j].Evals, err = newExtensions(rawStep.Evals); err != nil {
+				return proofWithPis, fmt.Errorf("query round %d, step %d evals: %w", i, j, err)
+			}
+			if round.Steps[j].MerkleProof.Siblings, err = newHashOuts(hasher, rawStep.MerkleProof.Siblings); err != nil {
+				return proofWithPis, fmt.Errorf("query round %d, step %d merkle proof: %w", i, j, err)
+			}
+		}
+	}
+
+	return proofWithPis, nil
+}
+
+func NewVerifierOnlyCircuitData(hasher Hasher, raw types.VerifierOnlyCircuitDataRaw) (VerifierOnlyCircuitData, error) {
+	var verifierData VerifierOnlyCircuitData
+	var err error
+	if verifierData.ConstantSigmasCap, err = newHashOuts(hasher, raw.ConstantsSigmasCap); err != nil {
+		return verifierData, fmt.Errorf("constants sigmas cap: %w", err)
+	}
+	if verifierData.CircuitDigest, err = newHashOut(hasher, raw.CircuitDigest); err != nil {
+		return verifierData, fmt.Errorf("circuit digest: %w", err)
+	}
+	return verifierData, nil
+}
+
+// Checks that the proof has the shape given by the common circuit data, so that verifying it can't
+// go out of bounds. The FRI part of the proof is checked by validateFriProofShape.
+func validateProofShape(proof *ProofWithPublicInputs, commonData *types.CommonCircuitData) error {
+	if uint64(len(proof.PublicInputs)) != commonData.NumPublicInputs {
+		return fmt.Errorf("proof has %d public inputs, expected %d", len(proof.PublicInputs), commonData.NumPublicInputs)
+	}
+
+	capLen := 1 << commonData.Config.FriConfig.CapHeight
+	for _, cap := range []struct {
+		name string
+		cap  FriMerkleCap
+	}{
+		{"wires", proof.Proof.WiresCap},
+		{"plonk zs partial products", proof.Proof.PlonkZsPartialProductsCap},
+		{"quotient polys", proof.Proof.QuotientPolysCap},
+	} {
+		if len(cap.cap) != capLen {
+			return fmt.Errorf("%s cap has %d digests, expected %d", cap.name, len(cap.cap), capLen)
+		}
+	}
+
+	numChallenges := commonData.Config.NumChallenges
+	openings := proof.Proof.Openings
+	for _, field := range []struct {
+		name   string
+		values []gl.QuadraticExtension
+		length uint64
+	}{
+		{"constants", openings.Constants, commonData.NumConstants},
+		{"plonk sigmas", openings.PlonkSigmas, commonData.Config.NumRoutedWires},
+		{"wires", openings.Wires, commonData.Config.NumWires},
+		{"plonk zs", openings.PlonkZs, numChallenges},
+		{"plonk zs next", openings.PlonkZsNext, numChallenges},
+		{"partial products", openings.PartialProducts, numChallenges * commonData.NumPartialProducts},
+		{"quotient polys", openings.QuotientPolys, numChallenges * commonData.QuotientDegreeFactor},
+		{"lookup zs", openings.LookupZs, numChallenges * commonData.NumLookupPolys},
+		{"lookup zs next", openings.LookupZsNext, numChallenges * commonData.NumLookupPolys},
+	} {
+		if uint64(len(field.values)) != field.length {
+			return fmt.Errorf("proof has %d %s openings, expected %d", len(field.values), field.name, field.length)
+		}
+	}
+
+	return nil
+}
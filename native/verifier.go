@@ -0,0 +1,192 @@
+// Package native verifies plonky2 proofs out of circuit. It mirrors verifier.VerifierChip,
+// challenger.Chip, plonk.PlonkChip and fri.Chip on gnark-crypto field elements, and reports why a
+// proof is rejected instead of leaving an unsatisfied constraint. It's meant as a fast check
+// before proving, and as a reference to compare the circuit against.
+package native
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// The out of circuit counterpart of verifier.VerifierChip.
+type Verifier struct {
+	commonData types.CommonCircuitData
+	hasher     Hasher
+	gates      []gates.NativeGate
+}
+
+func NewVerifier(commonData types.CommonCircuitData) (*Verifier, error) {
+	hasher, err := NewHasher(commonData.Hasher)
+	if err != nil {
+		return nil, err
+	}
+
+	createdGates, err := commonData.CreateGates(nil)
+	if err != nil {
+		return nil, err
+	}
+	nativeGates := make([]gates.NativeGate, len(createdGates))
+	for i, gate := range createdGates {
+		nativeGate, ok := gate.(gates.NativeGate)
+		if !ok {
+			return nil, fmt.Errorf("gate %s can't be evaluated out of circuit, it doesn't implement gates.NativeGate", gate.Id())
+		}
+		nativeGates[i] = nativeGate
+	}
+
+	return &Verifier{
+		commonData: commonData,
+		hasher:     hasher,
+		gates:      nativeGates,
+	}, nil
+}
+
+func (v *Verifier) Hasher() Hasher {
+	return v.hasher
+}
+
+func (v *Verifier) GetPublicInputsHash(publicInputs []goldilocks.Element) poseidon.GoldilocksHashOutNative {
+	return poseidon.HashNoPadNative(publicInputs)
+}
+
+func (v *Verifier) GetChallenges(
+	proof Proof,
+	publicInputsHash poseidon.GoldilocksHashOutNative,
+	verifierData VerifierOnlyCircuitData,
+) ProofChallenges {
+	config := v.commonData.Config
+	friParams := v.commonData.FriParams
+	numChallenges := config.NumChallenges
+	challenger := NewChallenger(v.hasher)
+
+	if !v.commonData.LegacyTranscript {
+		challenger.ObserveElement(goldilocks.NewElement(config.FriConfig.RateBits))
+		challenger.ObserveElement(goldilocks.NewElement(config.FriConfig.CapHeight))
+		challenger.ObserveElement(goldilocks.NewElement(config.FriConfig.ProofOfWorkBits))
+		challenger.ObserveFriReductionStrategy(config.FriConfig.ReductionStrategy)
+		challenger.ObserveElement(goldilocks.NewElement(config.FriConfig.NumQueryRounds))
+
+		if friParams.Hiding {
+			challenger.ObserveElement(goldilocks.One())
+		} else {
+			challenger.ObserveElement(goldilocks.NewElement(0))
+		}
+		challenger.ObserveElement(goldilocks.NewElement(friParams.DegreeBits))
+		for _, bit := range friParams.ReductionArityBits {
+			challenger.ObserveElement(goldilocks.NewElement(bit))
+		}
+	}
+
+	challenger.ObserveDigest(verifierData.CircuitDigest)
+	challenger.ObserveHash(publicInputsHash)
+	challenger.ObserveCap(proof.WiresCap)
+	plonkBetas := challenger.GetNChallenges(numChallenges)
+	plonkGammas := challenger.GetNChallenges(numChallenges)
+
+	// If there are lookups in the circuit, we need delta challenges as well. The already generated
+	// betas and gammas are reused as the first deltas.
+	plonkDeltas := []goldilocks.Element{}
+	if v.commonData.NumLookupPolys != 0 {
+		numLookupChallenges := plonk.NUM_COINS_LOOKUP * numChallenges
+		numAdditionalChallenges := numLookupChallenges - 2*numChallenges
+		plonkDeltas = append(plonkDeltas, plonkBetas...)
+		plonkDeltas = append(plonkDeltas, plonkGammas...)
+		plonkDeltas = append(plonkDeltas, challenger.GetNChallenges(numAdditionalChallenges)...)
+	}
+
+	challenger.ObserveCap(proof.PlonkZsPartialProductsCap)
+	plonkAlphas := challenger.GetNChallenges(numChallenges)
+
+	challenger.ObserveCap(proof.QuotientPolysCap)
+	plonkZeta := challenger.GetExtensionChallenge()
+
+	challenger.ObserveOpenings(toOpenings(proof.Openings))
+
+	return ProofChallenges{
+		PlonkBetas:  plonkBetas,
+		PlonkGammas: plonkGammas,
+		PlonkAlphas: plonkAlphas,
+		PlonkDeltas: plonkDeltas,
+		PlonkZeta:   plonkZeta,
+		FriChallenges: challenger.GetFriChallenges(
+			proof.OpeningProof.CommitPhaseMerkleCaps,
+			proof.OpeningProof.FinalPoly,
+			proof.OpeningProof.PowWitness,
+			config.FriConfig,
+		),
+	}
+}
+
+// Verifies the proof, returning an error describing the first check that fails.
+func (v *Verifier) Verify(
+	proofWithPis ProofWithPublicInputs,
+	verifierData VerifierOnlyCircuitData,
+) error {
+	if err := validateProofShape(&proofWithPis, &v.commonData); err != nil {
+		return fmt.Errorf("invalid proof shape: %w", err)
+	}
+	capLen := 1 << v.commonData.Config.FriConfig.CapHeight
+	if len(verifierData.ConstantSigmasCap) != capLen {
+		return fmt.Errorf(
+			"constants sigmas cap has %d digests, expected %d",
+			len(verifierData.ConstantSigmasCap), capLen,
+		)
+	}
+
+	proof := proofWithPis.Proof
+	publicInputsHash := v.GetPublicInputsHash(proofWithPis.PublicInputs)
+	proofChallenges := v.GetChallenges(proof, publicInputsHash, verifierData)
+
+	if err := v.verifyPlonk(proofChallenges, proof.Openings, publicInputsHash); err != nil {
+		return fmt.Errorf("plonk: %w", err)
+	}
+
+	initialMerkleCaps := []FriMerkleCap{
+		verifierData.ConstantSigmasCap,
+		proof.WiresCap,
+		proof.PlonkZsPartialProductsCap,
+		proof.QuotientPolysCap,
+	}
+
+	err := v.verifyFriProof(
+		v.getFriInstance(proofChallenges.PlonkZeta),
+		toOpenings(proof.Openings),
+		&proofChallenges.FriChallenges,
+		initialMerkleCaps,
+		&proof.OpeningProof,
+	)
+	if err != nil {
+		return fmt.Errorf("fri: %w", err)
+	}
+
+	return nil
+}
+
+// Verifies a deserialized proof against its verifier data and common circuit data.
+func Verify(
+	proofWithPis types.ProofWithPublicInputsRaw,
+	verifierData types.VerifierOnlyCircuitDataRaw,
+	commonData types.CommonCircuitData,
+) error {
+	verifier, err := NewVerifier(commonData)
+	if err != nil {
+		return err
+	}
+
+	nativeProofWithPis, err := NewProofWithPublicInputs(verifier.hasher, proofWithPis)
+	if err != nil {
+		return fmt.Errorf("invalid proof: %w", err)
+	}
+	nativeVerifierData, err := NewVerifierOnlyCircuitData(verifier.hasher, verifierData)
+	if err != nil {
+		return fmt.Errorf("invalid verifier data: %w", err)
+	}
+
+	return verifier.Verify(nativeProofWithPis, nativeVerifierData)
+}
@@ -0,0 +1,161 @@
+package native
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/test"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// Reads decode_block, whose proof predates the observation of the FRI config in the transcript.
+func readDecodeBlock(assert *test.Assert) (*Verifier, ProofWithPublicInputs, VerifierOnlyCircuitData) {
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	commonCircuitData.LegacyTranscript = true
+	verifier, err := NewVerifier(commonCircuitData)
+	assert.NoError(err)
+
+	proofWithPis, err := NewProofWithPublicInputs(
+		verifier.Hasher(),
+		types.ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json"),
+	)
+	assert.NoError(err)
+
+	verifierData, err := NewVerifierOnlyCircuitData(
+		verifier.Hasher(),
+		types.ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json"),
+	)
+	assert.NoError(err)
+
+	assert.NoError(validateProofShape(&proofWithPis, &verifier.commonData))
+
+	return verifier, proofWithPis, verifierData
+}
+
+func verifyDecodeBlock(v *Verifier, proofWithPis ProofWithPublicInputs, verifierData VerifierOnlyCircuitData, challenges ProofChallenges) error {
+	proof := proofWithPis.Proof
+	if err := v.verifyPlonk(challenges, proof.Openings, v.GetPublicInputsHash(proofWithPis.PublicInputs)); err != nil {
+		return err
+	}
+	return v.verifyFriProof(
+		v.getFriInstance(challenges.PlonkZeta),
+		toOpenings(proof.Openings),
+		&challenges.FriChallenges,
+		[]FriMerkleCap{
+			verifierData.ConstantSigmasCap,
+			proof.WiresCap,
+			proof.PlonkZsPartialProductsCap,
+			proof.QuotientPolysCap,
+		},
+		&proof.OpeningProof,
+	)
+}
+
+func TestDecodeBlockNative(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	v, proofWithPis, verifierData := readDecodeBlock(assert)
+	challenges := v.GetChallenges(proofWithPis.Proof, v.GetPublicInputsHash(proofWithPis.PublicInputs), verifierData)
+	assert.NoError(verifyDecodeBlock(v, proofWithPis, verifierData, challenges))
+}
+
+func TestDecodeBlockNativeTampered(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	v, proofWithPis, verifierData := readDecodeBlock(assert)
+	challenges := v.GetChallenges(proofWithPis.Proof, v.GetPublicInputsHash(proofWithPis.PublicInputs), verifierData)
+
+	one := goldilocks.One()
+
+	// A wrong wire opening breaks the vanishing polynomial identity.
+	tampered := readDecodeBlockProof(assert, v)
+	tampered.Proof.Openings.Wires[0][0].Add(&tampered.Proof.Openings.Wires[0][0], &one)
+	assert.ErrorContains(verifyDecodeBlock(v, tampered, verifierData, challenges), "vanishing polynomial")
+
+	// A wrong leaf element breaks its Merkle proof.
+	tampered = readDecodeBlockProof(assert, v)
+	leaf := tampered.Proof.OpeningProof.QueryRoundProofs[1].InitialTreesProof.EvalsProofs[2].Elements
+	leaf[0].Add(&leaf[0], &one)
+	assert.ErrorContains(verifyDecodeBlock(v, tampered, verifierData, challenges), "query round 1: initial tree 2")
+
+	// A wrong final polynomial breaks the last folding check.
+	tampered = readDecodeBlockProof(assert, v)
+	finalPoly := tampered.Proof.OpeningProof.FinalPoly
+	finalPoly[0][0].Add(&finalPoly[0][0], &one)
+	assert.ErrorContains(verifyDecodeBlock(v, tampered, verifierData, challenges), "final polynomial")
+
+	// A proof with a missing query round is rejected before being verified.
+	tampered = readDecodeBlockProof(assert, v)
+	tampered.Proof.OpeningProof.QueryRoundProofs = tampered.Proof.OpeningProof.QueryRoundProofs[1:]
+	assert.ErrorContains(verifyDecodeBlock(v, tampered, verifierData, challenges), "query rounds")
+}
+
+func readDecodeBlockProof(assert *test.Assert, v *Verifier) ProofWithPublicInputs {
+	proofWithPis, err := NewProofWithPublicInputs(
+		v.Hasher(),
+		types.ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json"),
+	)
+	assert.NoError(err)
+	return proofWithPis
+}
+
+func TestNewProofWithPublicInputsRejectsNonCanonical(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	verifier, err := NewVerifier(commonCircuitData)
+	assert.NoError(err)
+
+	raw := types.ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json")
+	raw.Proof.OpeningProof.PowWitness = goldilocks.Modulus().Uint64()
+	_, err = NewProofWithPublicInputs(verifier.Hasher(), raw)
+	assert.ErrorContains(err, "pow witness")
+}
+
+func TestNewVerifierRejectsUnknownGate(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	commonCircuitData.GateIds = append(commonCircuitData.GateIds, "UnknownGate")
+	_, err := NewVerifier(commonCircuitData)
+	assert.ErrorContains(err, "UnknownGate")
+}
+
+// Verifies the plonky2 proofs of testdata end to end, through the challenges of their transcript.
+func TestVerify(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, plonky2Circuit := range []string{"decode_block", "step"} {
+		commonCircuitData := types.ReadCommonCircuitData("../testdata/" + plonky2Circuit + "/common_circuit_data.json")
+		commonCircuitData.LegacyTranscript = true
+		proofWithPis := types.ReadProofWithPublicInputs("../testdata/" + plonky2Circuit + "/proof_with_public_inputs.json")
+		verifierData := types.ReadVerifierOnlyCircuitData("../testdata/" + plonky2Circuit + "/verifier_only_circuit_data.json")
+		assert.NoError(Verify(proofWithPis, verifierData, commonCircuitData), plonky2Circuit)
+
+		// The current transcript draws other challenges.
+		commonCircuitData.LegacyTranscript = false
+		assert.Error(Verify(proofWithPis, verifierData, commonCircuitData), plonky2Circuit)
+		commonCircuitData.LegacyTranscript = true
+
+		// A wrong wire opening breaks the vanishing polynomial identity.
+		proofWithPis.Proof.Openings.Wires[0][0]++
+		assert.ErrorContains(Verify(proofWithPis, verifierData, commonCircuitData), "vanishing polynomial", plonky2Circuit)
+	}
+}
+
+// A gate without an out of circuit evaluation.
+type circuitOnlyGate struct {
+	gates.Gate
+}
+
+func TestNewVerifierRejectsCircuitOnlyGate(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	createdGates, err := commonCircuitData.CreateGates(nil)
+	assert.NoError(err)
+	commonCircuitData.Gates = append(createdGates[:len(createdGates):len(createdGates)], circuitOnlyGate{createdGates[0]})
+	_, err = NewVerifier(commonCircuitData)
+	assert.ErrorContains(err, "doesn't implement gates.NativeGate")
+}
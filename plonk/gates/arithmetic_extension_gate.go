@@ -84,3 +84,25 @@ func (g *ArithmeticExtensionGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *ArithmeticExtensionGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	const0 := vars.localConstants[0]
+	const1 := vars.localConstants[1]
+
+	constraints := []gl.QuadraticExtension{}
+	for i := uint64(0); i < g.numOps; i++ {
+		multiplicand0 := vars.GetLocalExtAlgebra(g.wiresIthMultiplicand0(i))
+		multiplicand1 := vars.GetLocalExtAlgebra(g.wiresIthMultiplicand1(i))
+		addend := vars.GetLocalExtAlgebra(g.wiresIthAddend(i))
+		output := vars.GetLocalExtAlgebra(g.wiresIthOutput(i))
+
+		mul := gl.MulExtensionAlgebraNative(multiplicand0, multiplicand1)
+		scaledMul := gl.ScalarMulExtensionAlgebraNative(const0, mul)
+		computedOutput := gl.AddExtensionAlgebraNative(gl.ScalarMulExtensionAlgebraNative(const1, addend), scaledMul)
+
+		diff := gl.SubExtensionAlgebraNative(output, computedOutput)
+		constraints = append(constraints, diff[:]...)
+	}
+
+	return constraints
+}
@@ -82,3 +82,22 @@ func (g *ArithmeticGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *ArithmeticGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	const0 := vars.localConstants[0]
+	const1 := vars.localConstants[1]
+
+	constraints := []gl.QuadraticExtension{}
+	for i := uint64(0); i < g.numOps; i++ {
+		multiplicand0 := vars.localWires[g.WireIthMultiplicand0(i)]
+		multiplicand1 := vars.localWires[g.WireIthMultiplicand1(i)]
+		addend := vars.localWires[g.WireIthAddend(i)]
+		output := vars.localWires[g.WireIthOutput(i)]
+
+		computedOutput := multiplicand0.Mul(multiplicand1).Mul(const0).Add(addend.Mul(const1))
+
+		constraints = append(constraints, output.Sub(computedOutput))
+	}
+
+	return constraints
+}
@@ -94,3 +94,25 @@ func (g *BaseSumGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *BaseSumGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	sum := vars.localWires[BASESUM_GATE_WIRE_SUM]
+	limbs := make([]gl.QuadraticExtension, g.numLimbs)
+	for i, limbIdx := range g.limbs() {
+		limbs[i] = vars.localWires[limbIdx]
+	}
+
+	computedSum := gl.ReduceWithPowersNative(limbs, gl.NewQuadraticExtensionUint64(g.base, 0))
+
+	var constraints []gl.QuadraticExtension
+	constraints = append(constraints, computedSum.Sub(sum))
+	for _, limb := range limbs {
+		acc := gl.OneExtensionNative()
+		for i := uint64(0); i < g.base; i++ {
+			acc = acc.Mul(limb.Sub(gl.NewQuadraticExtensionUint64(i, 0)))
+		}
+		constraints = append(constraints, acc)
+	}
+
+	return constraints
+}
@@ -67,3 +67,13 @@ func (g *ConstantGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *ConstantGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	constraints := []gl.QuadraticExtension{}
+
+	for i := uint64(0); i < g.numConsts; i++ {
+		constraints = append(constraints, vars.localConstants[g.ConstInput(i)].Sub(vars.localWires[g.WireOutput(i)]))
+	}
+
+	return constraints
+}
@@ -224,3 +224,60 @@ func (g *CosetInterpolationGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *CosetInterpolationGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	constraints := []gl.QuadraticExtension{}
+
+	shift := vars.localWires[g.wireShift()]
+	evaluationPoint := vars.GetLocalExtAlgebra(g.wiresEvaluationPoint())
+	shiftedEvaluationPoint := vars.GetLocalExtAlgebra(g.wiresShiftedEvaluationPoint())
+
+	tmp := gl.ScalarMulExtensionAlgebraNative(shift.Neg(), shiftedEvaluationPoint)
+	tmp = gl.AddExtensionAlgebraNative(tmp, evaluationPoint)
+	constraints = append(constraints, tmp[:]...)
+
+	domain := gl.TwoAdicSubgroup(g.subgroupBits)
+	values := []gl.QuadraticExtensionAlgebra{}
+	for i := uint64(0); i < g.numPoints(); i++ {
+		values = append(values, vars.GetLocalExtAlgebra(g.wiresValue(i)))
+	}
+	weights := g.barycentricWeights
+
+	computedEval, computedProd := gl.PartialInterpolateExtAlgebraNative(
+		domain[:g.degree],
+		values[:g.degree],
+		weights[:g.degree],
+		shiftedEvaluationPoint,
+		gl.QuadraticExtensionAlgebra{gl.ZeroExtensionNative(), gl.ZeroExtensionNative()},
+		gl.ToQuadraticExtensionAlgebra(gl.OneExtensionNative()),
+	)
+
+	for i := uint64(0); i < g.numIntermediates(); i++ {
+		intermediateEval := vars.GetLocalExtAlgebra(g.wiresIntermediateEval(i))
+		intermediateProd := vars.GetLocalExtAlgebra(g.wiresIntermediateProd(i))
+
+		evalDiff := gl.SubExtensionAlgebraNative(intermediateEval, computedEval)
+		constraints = append(constraints, evalDiff[:]...)
+
+		prodDiff := gl.SubExtensionAlgebraNative(intermediateProd, computedProd)
+		constraints = append(constraints, prodDiff[:]...)
+
+		startIndex := 1 + (g.degree-1)*(i+1)
+		endIndex := min(startIndex+g.degree-1, g.numPoints())
+
+		computedEval, computedProd = gl.PartialInterpolateExtAlgebraNative(
+			domain[startIndex:endIndex],
+			values[startIndex:endIndex],
+			weights[startIndex:endIndex],
+			shiftedEvaluationPoint,
+			intermediateEval,
+			intermediateProd,
+		)
+	}
+
+	evaluationValue := vars.GetLocalExtAlgebra(g.wiresEvaluationValue())
+	evalDiff := gl.SubExtensionAlgebraNative(evaluationValue, computedEval)
+	constraints = append(constraints, evalDiff[:]...)
+
+	return constraints
+}
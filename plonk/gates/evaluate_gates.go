@@ -3,6 +3,7 @@ package gates
 import (
 	"github.com/consensys/gnark/frontend"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/profiler"
 )
 
 type EvaluateGatesChip struct {
@@ -61,11 +62,13 @@ func (g *EvaluateGatesChip) evalFiltered(
 	selectorIndex uint64,
 	groupRange Range,
 	numSelectors uint64,
+	numLookupSelectors uint64,
 ) []gl.QuadraticExtensionVariable {
 	glApi := gl.New(g.api)
 	filter := g.computeFilter(row, groupRange, vars.localConstants[selectorIndex], numSelectors > 1)
 
 	vars.RemovePrefix(numSelectors)
+	vars.RemovePrefix(numLookupSelectors)
 
 	unfiltered := gate.EvalUnfiltered(g.api, glApi, vars)
 	for i := range unfiltered {
@@ -81,9 +84,11 @@ func (g *EvaluateGatesChip) EvaluateGateConstraints(vars EvaluationVars) []gl.Qu
 		constraints[i] = gl.ZeroExtension()
 	}
 
+	defer profiler.Start("gates")()
 	for i, gate := range g.gates {
 		selectorIndex := g.selectorsInfo.selectorIndices[i]
 
+		stop := profiler.Start(gate.Id())
 		gateConstraints := g.evalFiltered(
 			gate,
 			vars,
@@ -91,6 +96,7 @@ func (g *EvaluateGatesChip) EvaluateGateConstraints(vars EvaluationVars) []gl.Qu
 			selectorIndex,
 			g.selectorsInfo.groups[selectorIndex],
 			g.selectorsInfo.NumSelectors(),
+			g.selectorsInfo.NumLookupSelectors(),
 		)
 
 		for i, constraint := range gateConstraints {
@@ -99,6 +105,65 @@ func (g *EvaluateGatesChip) EvaluateGateConstraints(vars EvaluationVars) []gl.Qu
 			}
 			constraints[i] = glApi.AddExtension(constraints[i], constraint)
 		}
+		stop()
+	}
+
+	return constraints
+}
+
+func computeFilterNative(
+	row uint64,
+	groupRange Range,
+	s gl.QuadraticExtension,
+	manySelector bool,
+) gl.QuadraticExtension {
+	product := gl.OneExtensionNative()
+	for i := groupRange.start; i < groupRange.end; i++ {
+		if i == uint64(row) {
+			continue
+		}
+		product = product.Mul(gl.NewQuadraticExtensionUint64(i, 0).Sub(s))
+	}
+
+	if manySelector {
+		product = product.Mul(gl.NewQuadraticExtensionUint64(UNUSED_SELECTOR, 0).Sub(s))
+	}
+
+	return product
+}
+
+// The out of circuit counterpart of EvaluateGatesChip.EvaluateGateConstraints, used by the native verifier.
+func EvaluateGateConstraintsNative(
+	gates []NativeGate,
+	numGateConstraints uint64,
+	selectorsInfo SelectorsInfo,
+	vars NativeEvaluationVars,
+) []gl.QuadraticExtension {
+	constraints := make([]gl.QuadraticExtension, numGateConstraints)
+	for i := range constraints {
+		constraints[i] = gl.ZeroExtensionNative()
+	}
+
+	numSelectors := selectorsInfo.NumSelectors()
+	for i, gate := range gates {
+		selectorIndex := selectorsInfo.selectorIndices[i]
+		filter := computeFilterNative(
+			uint64(i),
+			selectorsInfo.groups[selectorIndex],
+			vars.localConstants[selectorIndex],
+			numSelectors > 1,
+		)
+
+		gateVars := vars
+		gateVars.RemovePrefix(numSelectors)
+		gateVars.RemovePrefix(selectorsInfo.NumLookupSelectors())
+
+		for j, constraint := range gate.EvalUnfilteredNative(gateVars) {
+			if uint64(j) >= numGateConstraints {
+				panic("num_constraints() gave too low of a number")
+			}
+			constraints[j] = constraints[j].Add(constraint.Mul(filter))
+		}
 	}
 
 	return constraints
@@ -126,3 +126,29 @@ func (g *ExponentiationGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *ExponentiationGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	base := vars.localWires[g.wireBase()]
+	output := vars.localWires[g.wireOutput()]
+
+	var constraints []gl.QuadraticExtension
+
+	for i := uint64(0); i < g.numPowerBits; i++ {
+		prevIntermediateValue := gl.OneExtensionNative()
+		if i != 0 {
+			prevIntermediateValue = vars.localWires[g.wireIntermediateValue(i-1)].Square()
+		}
+
+		// powerBits is in LE order, but we accumulate in BE order.
+		curBit := vars.localWires[g.wirePowerBit(g.numPowerBits-i-1)]
+
+		// if b { x } else { y }, i.e. `bx - (by-y)`.
+		mulBy := curBit.Mul(base).Sub(curBit.Sub(gl.OneExtensionNative()))
+		intermediateValueDiff := prevIntermediateValue.Mul(mulBy).Sub(vars.localWires[g.wireIntermediateValue(i)])
+		constraints = append(constraints, intermediateValueDiff)
+	}
+
+	constraints = append(constraints, output.Sub(vars.localWires[g.wireIntermediateValue(g.numPowerBits-1)]))
+
+	return constraints
+}
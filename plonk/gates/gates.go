@@ -3,6 +3,8 @@ package gates
 import (
 	"fmt"
 	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/consensys/gnark/frontend"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
@@ -17,39 +19,111 @@ type Gate interface {
 	) []gl.QuadraticExtensionVariable
 }
 
-var gateRegexHandlers = map[*regexp.Regexp]func(parameters map[string]string) Gate{
-	arithmeticGateRegex:          deserializeArithmeticGate,
-	arithmeticExtensionGateRegex: deserializeExtensionArithmeticGate,
-	baseSumGateRegex:             deserializeBaseSumGate,
-	constantGateRegex:            deserializeConstantGate,
-	cosetInterpolationGateRegex:  deserializeCosetInterpolationGate,
-	exponentiationGateRegex:      deserializeExponentiationGate,
-	mulExtensionGateRegex:        deserializeMulExtensionGate,
-	noopGateRegex:                deserializeNoopGate,
-	poseidonGateRegex:            deserializePoseidonGate,
-	poseidonMdsGateRegex:         deserializePoseidonMdsGate,
-	publicInputGateRegex:         deserializePublicInputGate,
-	randomAccessGateRegex:        deserializeRandomAccessGate,
-	reducingExtensionGateRegex:   deserializeReducingExtensionGate,
-	reducingGateRegex:            deserializeReducingGate,
-	poseidon2GateRegex:           deserializePoseidon2Gate,
-}
-
-func GateInstanceFromId(gateId string) Gate {
-	for regex, handler := range gateRegexHandlers {
+// A gate the native verifier can evaluate out of circuit, which all the gates of this repo are.
+// Gates implementing only Gate are verified in circuit, but rejected by native.NewVerifier.
+type NativeGate interface {
+	Gate
+	// Evaluates the gate's constraints out of circuit, for the native verifier.
+	EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension
+}
+
+// Creates a gate from the named subexpressions its ID regex matched, e.g. "numOps" for
+// "ArithmeticGate { num_ops: (?P<numOps>[0-9]+) }".
+type GateConstructor func(parameters map[string]string) Gate
+
+type gateHandler struct {
+	regex       *regexp.Regexp
+	constructor GateConstructor
+}
+
+// The gates a verifier can deserialize from the plonky2 gate IDs of CommonCircuitData.GateIds.
+type GateRegistry struct {
+	mu       sync.RWMutex
+	handlers []gateHandler
+}
+
+// Creates a registry of the gates supported by this repo.
+func NewGateRegistry() *GateRegistry {
+	registry := &GateRegistry{}
+	registry.RegisterGate(arithmeticGateRegex, deserializeArithmeticGate)
+	registry.RegisterGate(arithmeticExtensionGateRegex, deserializeExtensionArithmeticGate)
+	registry.RegisterGate(baseSumGateRegex, deserializeBaseSumGate)
+	registry.RegisterGate(constantGateRegex, deserializeConstantGate)
+	registry.RegisterGate(cosetInterpolationGateRegex, deserializeCosetInterpolationGate)
+	registry.RegisterGate(exponentiationGateRegex, deserializeExponentiationGate)
+	registry.RegisterGate(lookupGateRegex, deserializeLookupGate)
+	registry.RegisterGate(lookupTableGateRegex, deserializeLookupTableGate)
+	registry.RegisterGate(mulExtensionGateRegex, deserializeMulExtensionGate)
+	registry.RegisterGate(noopGateRegex, deserializeNoopGate)
+	registry.RegisterGate(poseidonGateRegex, deserializePoseidonGate)
+	registry.RegisterGate(poseidonMdsGateRegex, deserializePoseidonMdsGate)
+	registry.RegisterGate(publicInputGateRegex, deserializePublicInputGate)
+	registry.RegisterGate(randomAccessGateRegex, deserializeRandomAccessGate)
+	registry.RegisterGate(reducingExtensionGateRegex, deserializeReducingExtensionGate)
+	registry.RegisterGate(reducingGateRegex, deserializeReducingGate)
+	registry.RegisterGate(poseidon2GateRegex, deserializePoseidon2Gate)
+	return registry
+}
+
+// The registry used when none is given, e.g. by verifier.VerifierCircuit and native.NewVerifier.
+var DefaultGateRegistry = NewGateRegistry()
+
+// Registers a custom gate in DefaultGateRegistry, see GateRegistry.RegisterGate.
+func RegisterGate(regex *regexp.Regexp, constructor GateConstructor) {
+	DefaultGateRegistry.RegisterGate(regex, constructor)
+}
+
+// Registers constructor for the gate IDs matching regex. A gate registered later takes precedence
+// over the gates registered before whose regex also matches, so built-in gates can be overridden.
+func (r *GateRegistry) RegisterGate(regex *regexp.Regexp, constructor GateConstructor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, gateHandler{regex, constructor})
+}
+
+// Creates the gate with ID gateId, or returns an error if no registered gate matches it.
+func (r *GateRegistry) GateInstanceFromId(gateId string) (Gate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.handlers) - 1; i >= 0; i-- {
+		regex := r.handlers[i].regex
 		matches := regex.FindStringSubmatch(gateId)
-		if matches != nil {
-			parameters := make(map[string]string)
-			for i, name := range regex.SubexpNames() {
-				if i != 0 && name != "" {
-					parameters[name] = matches[i]
-				}
-			}
+		if matches == nil {
+			continue
+		}
 
-			if matches != nil {
-				return handler(parameters)
+		parameters := make(map[string]string)
+		for j, name := range regex.SubexpNames() {
+			if j != 0 && name != "" {
+				parameters[name] = matches[j]
 			}
 		}
+		return r.handlers[i].constructor(parameters), nil
+	}
+	return nil, fmt.Errorf("unsupported gate %s", gateId)
+}
+
+// Creates the gates of gateIds, or returns an error listing all the unsupported ones.
+func (r *GateRegistry) GatesFromIds(gateIds []string) ([]Gate, error) {
+	createdGates := make([]Gate, 0, len(gateIds))
+	unsupported := []string{}
+	for _, gateId := range gateIds {
+		gate, err := r.GateInstanceFromId(gateId)
+		if err != nil {
+			unsupported = append(unsupported, gateId)
+			continue
+		}
+		createdGates = append(createdGates, gate)
+	}
+
+	if len(unsupported) != 0 {
+		return nil, fmt.Errorf("unsupported gates: %s", strings.Join(unsupported, "; "))
 	}
-	panic(fmt.Sprintf("Unknown gate ID %s", gateId))
+	return createdGates, nil
+}
+
+// Creates the gate with ID gateId from DefaultGateRegistry.
+func GateInstanceFromId(gateId string) (Gate, error) {
+	return DefaultGateRegistry.GateInstanceFromId(gateId)
 }
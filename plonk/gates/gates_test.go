@@ -2,6 +2,7 @@ package gates_test
 
 import (
 	"errors"
+	"regexp"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -709,22 +710,13 @@ func (circuit *TestGateCircuit) Define(api frontend.API) error {
 	return nil
 }
 
-func TestGates(t *testing.T) {
-	assert := test.NewAssert(t)
-
-	testCase := func(testGate gates.Gate, expectedConstraints []gl.QuadraticExtensionVariable) {
-		circuit := &TestGateCircuit{testGate: testGate, ExpectedConstraints: expectedConstraints}
-		witness := &TestGateCircuit{testGate: testGate, ExpectedConstraints: expectedConstraints}
-		err := test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
-		assert.NoError(err)
-	}
-
-	type gateTest struct {
-		testGate            gates.Gate
-		expectedConstraints []gl.QuadraticExtensionVariable
-	}
+type gateTest struct {
+	testGate            gates.Gate
+	expectedConstraints []gl.QuadraticExtensionVariable
+}
 
-	gateTests := []gateTest{
+func gateTests() []gateTest {
+	return []gateTest{
 		{gates.NewPublicInputGate(), publicInputGateExpectedConstraints},
 		{gates.NewBaseSumGate(63, 2), baseSumGateExpectedConstraints},
 		{gates.NewArithmeticGate(20), arithmeticGateExpectedConstraints},
@@ -757,12 +749,116 @@ func TestGates(t *testing.T) {
 			},
 		), cosetInterpolationGateExpectedConstraints},
 		{&gates.PoseidonMdsGate{}, poseidonMdsGateExpectedConstraints},
+		{gates.NewLookupGate(40, lutHash), []gl.QuadraticExtensionVariable{}},
+		{gates.NewLookupTableGate(26, lutHash, 11), []gl.QuadraticExtensionVariable{}},
 	}
+}
 
-	for _, test := range gateTests {
+func TestGates(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	testCase := func(testGate gates.Gate, expectedConstraints []gl.QuadraticExtensionVariable) {
+		circuit := &TestGateCircuit{testGate: testGate, ExpectedConstraints: expectedConstraints}
+		witness := &TestGateCircuit{testGate: testGate, ExpectedConstraints: expectedConstraints}
+		err := test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+		assert.NoError(err)
+	}
+
+	for _, test := range gateTests() {
 		testCase(
 			test.testGate,
 			test.expectedConstraints,
 		)
 	}
 }
+
+func toNativeExtensions(values []gl.QuadraticExtensionVariable) []gl.QuadraticExtension {
+	res := make([]gl.QuadraticExtension, len(values))
+	for i, value := range values {
+		for j := range value {
+			if _, err := res[i][j].SetInterface(value[j].Limb); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return res
+}
+
+func TestGatesNative(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData("../../testdata/decode_block/common_circuit_data.json")
+	numSelectors := commonCircuitData.SelectorsInfo.NumSelectors()
+
+	var nativePublicInputsHash poseidon.GoldilocksHashOutNative
+	vars := gates.NewNativeEvaluationVars(
+		toNativeExtensions(localConstants[numSelectors:]),
+		toNativeExtensions(localWires),
+		nativePublicInputsHash,
+	)
+
+	for _, test := range gateTests() {
+		nativeGate, ok := test.testGate.(gates.NativeGate)
+		assert.True(ok, test.testGate.Id())
+		constraints := nativeGate.EvalUnfilteredNative(*vars)
+		assert.Equal(toNativeExtensions(test.expectedConstraints), constraints, test.testGate.Id())
+	}
+}
+
+var lutHash = [32]uint8{117, 15, 190, 208, 94, 116, 239, 26, 208, 133, 21, 51, 231, 241, 37, 89, 110, 252, 78, 48, 38, 205, 144, 193, 1, 100, 12, 168, 91, 70, 217, 217}
+
+func TestLookupGateIds(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	gateIds := []string{
+		"LookupGate {num_slots: 40, lut_hash: [117, 15, 190, 208, 94, 116, 239, 26, 208, 133, 21, 51, 231, 241, 37, 89, 110, 252, 78, 48, 38, 205, 144, 193, 1, 100, 12, 168, 91, 70, 217, 217]}",
+		"LookupTableGate {num_slots: 26, lut_hash: [117, 15, 190, 208, 94, 116, 239, 26, 208, 133, 21, 51, 231, 241, 37, 89, 110, 252, 78, 48, 38, 205, 144, 193, 1, 100, 12, 168, 91, 70, 217, 217], last_lut_row: 11}",
+	}
+	expectedGates := []gates.Gate{
+		gates.NewLookupGate(40, lutHash),
+		gates.NewLookupTableGate(26, lutHash, 11),
+	}
+
+	for i, gateId := range gateIds {
+		gate, err := gates.GateInstanceFromId(gateId)
+		assert.NoError(err)
+		assert.Equal(expectedGates[i], gate)
+		assert.Equal(gateId, gate.Id())
+	}
+}
+
+// A custom gate without constraints, identified by its number of wires.
+type customGate struct {
+	*gates.NoopGate
+	numWires string
+}
+
+func (g *customGate) Id() string {
+	return "CustomGate { num_wires: " + g.numWires + " }"
+}
+
+func TestGateRegistry(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	registry := gates.NewGateRegistry()
+	customGateId := "CustomGate { num_wires: 3 }"
+
+	_, err := registry.GatesFromIds([]string{"NoopGate", customGateId, "OtherGate"})
+	assert.ErrorContains(err, customGateId)
+	assert.ErrorContains(err, "OtherGate")
+
+	registry.RegisterGate(
+		regexp.MustCompile(`CustomGate { num_wires: (?P<numWires>[0-9]+) }`),
+		func(parameters map[string]string) gates.Gate {
+			return &customGate{gates.NewNoopGate(), parameters["numWires"]}
+		},
+	)
+	createdGates, err := registry.GatesFromIds([]string{"NoopGate", customGateId})
+	assert.NoError(err)
+	assert.Equal(gates.NewNoopGate(), createdGates[0])
+	assert.Equal(customGateId, createdGates[1].Id())
+
+	// The custom gate is only registered in its registry.
+	_, err = gates.GateInstanceFromId(customGateId)
+	assert.ErrorContains(err, customGateId)
+}
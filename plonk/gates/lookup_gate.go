@@ -0,0 +1,101 @@
+package gates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+var lookupGateRegex = regexp.MustCompile(`LookupGate \{\s*num_slots: (?P<numSlots>[0-9]+), lut_hash: \[(?P<lutHash>[0-9, ]+)\]\s*\}`)
+
+func deserializeLookupGate(parameters map[string]string) Gate {
+	// Has the format "LookupGate {num_slots: 40, lut_hash: [117, 15, 190, 208, 94, 116, 239, 26, 208, 133, 21, 51, 231, 241, 37, 89, 110, 252, 78, 48, 38, 205, 144, 193, 1, 100, 12, 168, 91, 70, 217, 217]}"
+	numSlots, hasNumSlots := parameters["numSlots"]
+	lutHash, hasLutHash := parameters["lutHash"]
+	if !hasNumSlots || !hasLutHash {
+		panic("missing numSlots or lutHash in LookupGate")
+	}
+
+	numSlotsInt, err := strconv.ParseUint(numSlots, 10, 64)
+	if err != nil {
+		panic("invalid numSlots in LookupGate")
+	}
+
+	return NewLookupGate(numSlotsInt, deserializeLutHash(lutHash))
+}
+
+func deserializeLutHash(lutHash string) [32]uint8 {
+	bytesStr := strings.Split(lutHash, ",")
+	if len(bytesStr) != 32 {
+		panic("lut_hash should be 32 bytes long")
+	}
+
+	var hash [32]uint8
+	for i, byteStr := range bytesStr {
+		byteInt, err := strconv.ParseUint(strings.TrimSpace(byteStr), 10, 8)
+		if err != nil {
+			panic("invalid byte in lut_hash")
+		}
+		hash[i] = uint8(byteInt)
+	}
+
+	return hash
+}
+
+func lutHashString(lutHash [32]uint8) string {
+	bytesStr := make([]string, len(lutHash))
+	for i, b := range lutHash {
+		bytesStr[i] = strconv.FormatUint(uint64(b), 10)
+	}
+	return "[" + strings.Join(bytesStr, ", ") + "]"
+}
+
+// A gate which stores (input, output) lookup pairs made into a lookup table. The lookup argument
+// itself is checked by the lookup vanishing terms, so the gate has no constraints of its own.
+type LookupGate struct {
+	numSlots uint64
+	lutHash  [32]uint8
+}
+
+func NewLookupGate(numSlots uint64, lutHash [32]uint8) *LookupGate {
+	return &LookupGate{
+		numSlots: numSlots,
+		lutHash:  lutHash,
+	}
+}
+
+func (g *LookupGate) Id() string {
+	return fmt.Sprintf("LookupGate {num_slots: %d, lut_hash: %s}", g.numSlots, lutHashString(g.lutHash))
+}
+
+// Returns the number of lookups a LookupGate holds for a circuit with numRoutedWires routed wires.
+func LookupGateNumSlots(numRoutedWires uint64) uint64 {
+	wiresPerLookup := uint64(2)
+	return numRoutedWires / wiresPerLookup
+}
+
+func LookupGateWireIthLookingInp(i uint64) uint64 {
+	return 2 * i
+}
+
+func LookupGateWireIthLookingOut(i uint64) uint64 {
+	return 2*i + 1
+}
+
+func (g *LookupGate) EvalUnfiltered(
+	api frontend.API,
+	glApi *gl.Chip,
+	vars EvaluationVars,
+) []gl.QuadraticExtensionVariable {
+	// No main trace constraints for lookups.
+	return []gl.QuadraticExtensionVariable{}
+}
+
+func (g *LookupGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	// No main trace constraints for lookups.
+	return []gl.QuadraticExtension{}
+}
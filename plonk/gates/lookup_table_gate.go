@@ -0,0 +1,92 @@
+package gates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+var lookupTableGateRegex = regexp.MustCompile(`LookupTableGate \{\s*num_slots: (?P<numSlots>[0-9]+), lut_hash: \[(?P<lutHash>[0-9, ]+)\], last_lut_row: (?P<lastLutRow>[0-9]+)\s*\}`)
+
+func deserializeLookupTableGate(parameters map[string]string) Gate {
+	// Has the format "LookupTableGate {num_slots: 26, lut_hash: [117, 15, 190, 208, 94, 116, 239, 26, 208, 133, 21, 51, 231, 241, 37, 89, 110, 252, 78, 48, 38, 205, 144, 193, 1, 100, 12, 168, 91, 70, 217, 217], last_lut_row: 11}"
+	numSlots, hasNumSlots := parameters["numSlots"]
+	lutHash, hasLutHash := parameters["lutHash"]
+	lastLutRow, hasLastLutRow := parameters["lastLutRow"]
+	if !hasNumSlots || !hasLutHash || !hasLastLutRow {
+		panic("missing numSlots, lutHash or lastLutRow in LookupTableGate")
+	}
+
+	numSlotsInt, err := strconv.ParseUint(numSlots, 10, 64)
+	if err != nil {
+		panic("invalid numSlots in LookupTableGate")
+	}
+
+	lastLutRowInt, err := strconv.ParseUint(lastLutRow, 10, 64)
+	if err != nil {
+		panic("invalid lastLutRow in LookupTableGate")
+	}
+
+	return NewLookupTableGate(numSlotsInt, deserializeLutHash(lutHash), lastLutRowInt)
+}
+
+// A gate which stores the (input, output) pairs of a lookup table along with their
+// multiplicities. Like LookupGate, it has no constraints of its own.
+type LookupTableGate struct {
+	numSlots   uint64
+	lutHash    [32]uint8
+	lastLutRow uint64
+}
+
+func NewLookupTableGate(numSlots uint64, lutHash [32]uint8, lastLutRow uint64) *LookupTableGate {
+	return &LookupTableGate{
+		numSlots:   numSlots,
+		lutHash:    lutHash,
+		lastLutRow: lastLutRow,
+	}
+}
+
+func (g *LookupTableGate) Id() string {
+	return fmt.Sprintf(
+		"LookupTableGate {num_slots: %d, lut_hash: %s, last_lut_row: %d}",
+		g.numSlots,
+		lutHashString(g.lutHash),
+		g.lastLutRow,
+	)
+}
+
+// Returns the number of table entries a LookupTableGate holds for a circuit with numRoutedWires
+// routed wires.
+func LookupTableGateNumSlots(numRoutedWires uint64) uint64 {
+	wiresPerEntry := uint64(3)
+	return numRoutedWires / wiresPerEntry
+}
+
+func LookupTableGateWireIthLookedInp(i uint64) uint64 {
+	return 3 * i
+}
+
+func LookupTableGateWireIthLookedOut(i uint64) uint64 {
+	return 3*i + 1
+}
+
+func LookupTableGateWireIthMultiplicity(i uint64) uint64 {
+	return 3*i + 2
+}
+
+func (g *LookupTableGate) EvalUnfiltered(
+	api frontend.API,
+	glApi *gl.Chip,
+	vars EvaluationVars,
+) []gl.QuadraticExtensionVariable {
+	// No main trace constraints for the lookup table.
+	return []gl.QuadraticExtensionVariable{}
+}
+
+func (g *LookupTableGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	// No main trace constraints for the lookup table.
+	return []gl.QuadraticExtension{}
+}
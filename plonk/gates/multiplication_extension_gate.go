@@ -74,3 +74,20 @@ func (g *MultiplicationExtensionGate) EvalUnfiltered(
 	}
 	return constraints
 }
+
+func (g *MultiplicationExtensionGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	const0 := vars.localConstants[0]
+	constraints := []gl.QuadraticExtension{}
+	for i := uint64(0); i < g.numOps; i++ {
+		multiplicand0 := vars.GetLocalExtAlgebra(g.wiresIthMultiplicand0(i))
+		multiplicand1 := vars.GetLocalExtAlgebra(g.wiresIthMultiplicand1(i))
+		output := vars.GetLocalExtAlgebra(g.wiresIthOutput(i))
+
+		mul := gl.MulExtensionAlgebraNative(multiplicand0, multiplicand1)
+		computedOutput := gl.ScalarMulExtensionAlgebraNative(const0, mul)
+
+		diff := gl.SubExtensionAlgebraNative(output, computedOutput)
+		constraints = append(constraints, diff[:]...)
+	}
+	return constraints
+}
@@ -32,3 +32,7 @@ func (g *NoopGate) EvalUnfiltered(
 ) []gl.QuadraticExtensionVariable {
 	return []gl.QuadraticExtensionVariable{}
 }
+
+func (g *NoopGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	return []gl.QuadraticExtension{}
+}
@@ -180,3 +180,74 @@ func (g *Poseidon2Gate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *Poseidon2Gate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	constraints := []gl.QuadraticExtension{}
+
+	// Assert that `swap` is binary.
+	swap := vars.localWires[g.WireSwap()]
+	constraints = append(constraints, swap.Mul(swap.Sub(gl.OneExtensionNative())))
+
+	// Assert that each delta wire is set properly: `delta_i = swap * (rhs - lhs)`.
+	for i := uint64(0); i < 4; i++ {
+		inputLhs := vars.localWires[g.WireInput(i)]
+		inputRhs := vars.localWires[g.WireInput(i+4)]
+		deltaI := vars.localWires[g.WireDelta(i)]
+		constraints = append(constraints, swap.Mul(inputRhs.Sub(inputLhs)).Sub(deltaI))
+	}
+
+	// Compute the possibly-swapped input layer.
+	var state poseidon2.GoldilocksStateExtensionNative
+	for i := uint64(0); i < 4; i++ {
+		deltaI := vars.localWires[g.WireDelta(i)]
+		state[i] = vars.localWires[g.WireInput(i)].Add(deltaI)
+		state[i+4] = vars.localWires[g.WireInput(i+4)].Sub(deltaI)
+	}
+	for i := uint64(8); i < poseidon2.WIDTH; i++ {
+		state[i] = vars.localWires[g.WireInput(i)]
+	}
+
+	// The initial linear layer.
+	state = poseidon2.ExternalLinearLayerExtensionNative(state)
+
+	// The first half of the external rounds.
+	for r := 0; r < poseidon2.ROUNDS_F_HALF; r++ {
+		state = poseidon2.AddRCExtensionNative(state, r)
+		if r != 0 {
+			for i := uint64(0); i < poseidon2.WIDTH; i++ {
+				sBoxIn := vars.localWires[g.WireFullSBox0(uint64(r), i)]
+				constraints = append(constraints, state[i].Sub(sBoxIn))
+				state[i] = sBoxIn
+			}
+		}
+		state = poseidon2.SBoxLayerExtensionNative(state)
+		state = poseidon2.ExternalLinearLayerExtensionNative(state)
+	}
+
+	// The internal rounds.
+	for r := 0; r < poseidon2.ROUNDS_P; r++ {
+		state[0] = poseidon2.AddInternalConstantExtensionNative(state[0], r)
+		sBoxIn := vars.localWires[g.WirePartialSBox(uint64(r))]
+		constraints = append(constraints, state[0].Sub(sBoxIn))
+		state[0] = poseidon2.SBoxPExtensionNative(sBoxIn)
+		state = poseidon2.InternalLinearLayerExtensionNative(state)
+	}
+
+	// The second half of the external rounds.
+	for r := poseidon2.ROUNDS_F_HALF; r < poseidon2.ROUNDS_F; r++ {
+		state = poseidon2.AddRCExtensionNative(state, r)
+		for i := uint64(0); i < poseidon2.WIDTH; i++ {
+			sBoxIn := vars.localWires[g.WireFullSBox1(uint64(r-poseidon2.ROUNDS_F_HALF), i)]
+			constraints = append(constraints, state[i].Sub(sBoxIn))
+			state[i] = sBoxIn
+		}
+		state = poseidon2.SBoxLayerExtensionNative(state)
+		state = poseidon2.ExternalLinearLayerExtensionNative(state)
+	}
+
+	for i := uint64(0); i < poseidon2.WIDTH; i++ {
+		constraints = append(constraints, state[i].Sub(vars.localWires[g.WireOutput(i)]))
+	}
+
+	return constraints
+}
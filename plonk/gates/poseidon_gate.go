@@ -179,3 +179,83 @@ func (g *PoseidonGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *PoseidonGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	constraints := []gl.QuadraticExtension{}
+
+	// Assert that `swap` is binary.
+	swap := vars.localWires[g.WireSwap()]
+	constraints = append(constraints, swap.Mul(swap.Sub(gl.OneExtensionNative())))
+
+	// Assert that each delta wire is set properly: `delta_i = swap * (rhs - lhs)`.
+	for i := uint64(0); i < 4; i++ {
+		inputLhs := vars.localWires[g.WireInput(i)]
+		inputRhs := vars.localWires[g.WireInput(i+4)]
+		deltaI := vars.localWires[g.WireDelta(i)]
+		constraints = append(constraints, swap.Mul(inputRhs.Sub(inputLhs)).Sub(deltaI))
+	}
+
+	// Compute the possibly-swapped input layer.
+	var state poseidon.GoldilocksStateExtensionNative
+	for i := uint64(0); i < 4; i++ {
+		deltaI := vars.localWires[g.WireDelta(i)]
+		state[i] = vars.localWires[g.WireInput(i)].Add(deltaI)
+		state[i+4] = vars.localWires[g.WireInput(i+4)].Sub(deltaI)
+	}
+	for i := uint64(8); i < poseidon.SPONGE_WIDTH; i++ {
+		state[i] = vars.localWires[g.WireInput(i)]
+	}
+
+	roundCounter := 0
+
+	// First set of full rounds.
+	for r := uint64(0); r < poseidon.HALF_N_FULL_ROUNDS; r++ {
+		state = poseidon.ConstantLayerExtensionNative(state, &roundCounter)
+		if r != 0 {
+			for i := uint64(0); i < poseidon.SPONGE_WIDTH; i++ {
+				sBoxIn := vars.localWires[g.WireFullSBox0(r, i)]
+				constraints = append(constraints, state[i].Sub(sBoxIn))
+				state[i] = sBoxIn
+			}
+		}
+		state = poseidon.SBoxLayerExtensionNative(state)
+		state = poseidon.MdsLayerExtensionNative(state)
+		roundCounter++
+	}
+
+	// Partial rounds.
+	state = poseidon.PartialFirstConstantLayerExtensionNative(state)
+	state = poseidon.MdsPartialLayerInitExtensionNative(state)
+
+	for r := uint64(0); r < poseidon.N_PARTIAL_ROUNDS-1; r++ {
+		sBoxIn := vars.localWires[g.WirePartialSBox(r)]
+		constraints = append(constraints, state[0].Sub(sBoxIn))
+		state[0] = poseidon.SBoxMonomialExtensionNative(sBoxIn)
+		state[0] = state[0].Add(gl.NewQuadraticExtensionUint64(poseidon.FAST_PARTIAL_ROUND_CONSTANTS[r].(uint64), 0))
+		state = poseidon.MdsPartialLayerFastExtensionNative(state, int(r))
+	}
+	sBoxIn := vars.localWires[g.WirePartialSBox(poseidon.N_PARTIAL_ROUNDS-1)]
+	constraints = append(constraints, state[0].Sub(sBoxIn))
+	state[0] = poseidon.SBoxMonomialExtensionNative(sBoxIn)
+	state = poseidon.MdsPartialLayerFastExtensionNative(state, poseidon.N_PARTIAL_ROUNDS-1)
+	roundCounter += poseidon.N_PARTIAL_ROUNDS
+
+	// Second set of full rounds.
+	for r := uint64(0); r < poseidon.HALF_N_FULL_ROUNDS; r++ {
+		state = poseidon.ConstantLayerExtensionNative(state, &roundCounter)
+		for i := uint64(0); i < poseidon.SPONGE_WIDTH; i++ {
+			sBoxIn := vars.localWires[g.WireFullSBox1(r, i)]
+			constraints = append(constraints, state[i].Sub(sBoxIn))
+			state[i] = sBoxIn
+		}
+		state = poseidon.SBoxLayerExtensionNative(state)
+		state = poseidon.MdsLayerExtensionNative(state)
+		roundCounter++
+	}
+
+	for i := uint64(0); i < poseidon.SPONGE_WIDTH; i++ {
+		constraints = append(constraints, state[i].Sub(vars.localWires[g.WireOutput(i)]))
+	}
+
+	return constraints
+}
@@ -97,3 +97,38 @@ func (g *PoseidonMdsGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *PoseidonMdsGate) mdsRowShfAlgebraNative(
+	r uint64,
+	v [poseidon.SPONGE_WIDTH]gl.QuadraticExtensionAlgebra,
+) gl.QuadraticExtensionAlgebra {
+	if r >= poseidon.SPONGE_WIDTH {
+		panic("MDS row index out of range")
+	}
+
+	res := gl.QuadraticExtensionAlgebra{gl.ZeroExtensionNative(), gl.ZeroExtensionNative()}
+	for i := uint64(0); i < poseidon.SPONGE_WIDTH; i++ {
+		coeff := gl.NewQuadraticExtensionUint64(poseidon.MDS_MATRIX_CIRC[i].(uint64), 0)
+		res = gl.AddExtensionAlgebraNative(res, gl.ScalarMulExtensionAlgebraNative(coeff, v[(i+r)%poseidon.SPONGE_WIDTH]))
+	}
+
+	coeff := gl.NewQuadraticExtensionUint64(poseidon.MDS_MATRIX_DIAG[r].(uint64), 0)
+	return gl.AddExtensionAlgebraNative(res, gl.ScalarMulExtensionAlgebraNative(coeff, v[r]))
+}
+
+func (g *PoseidonMdsGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	constraints := []gl.QuadraticExtension{}
+
+	var inputs [poseidon.SPONGE_WIDTH]gl.QuadraticExtensionAlgebra
+	for i := uint64(0); i < poseidon.SPONGE_WIDTH; i++ {
+		inputs[i] = vars.GetLocalExtAlgebra(g.WireInput(i))
+	}
+
+	for r := uint64(0); r < poseidon.SPONGE_WIDTH; r++ {
+		output := vars.GetLocalExtAlgebra(g.WireOutput(r))
+		diff := gl.SubExtensionAlgebraNative(output, g.mdsRowShfAlgebraNative(r, inputs))
+		constraints = append(constraints, diff[:]...)
+	}
+
+	return constraints
+}
@@ -49,3 +49,15 @@ func (g *PublicInputGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *PublicInputGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	constraints := []gl.QuadraticExtension{}
+
+	wires := g.WiresPublicInputsHash()
+	for i, wire := range wires {
+		hashPart := gl.ToQuadraticExtension(vars.publicInputsHash[i])
+		constraints = append(constraints, vars.localWires[wire].Sub(hashPart))
+	}
+
+	return constraints
+}
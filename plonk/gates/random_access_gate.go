@@ -188,3 +188,55 @@ func (g *RandomAccessGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *RandomAccessGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	two := gl.NewQuadraticExtensionUint64(2, 0)
+	constraints := []gl.QuadraticExtension{}
+
+	for copy := uint64(0); copy < g.numCopies; copy++ {
+		accessIndex := vars.localWires[g.WireAccessIndex(copy)]
+		listItems := []gl.QuadraticExtension{}
+		for i := uint64(0); i < g.vecSize(); i++ {
+			listItems = append(listItems, vars.localWires[g.WireListItem(i, copy)])
+		}
+		claimedElement := vars.localWires[g.WireClaimedElement(copy)]
+		bits := []gl.QuadraticExtension{}
+		for i := uint64(0); i < g.bits; i++ {
+			bits = append(bits, vars.localWires[g.WireBit(i, copy)])
+		}
+
+		// Assert that each bit wire value is indeed boolean.
+		for _, b := range bits {
+			constraints = append(constraints, b.Mul(b).Sub(b))
+		}
+
+		// Assert that the binary decomposition was correct.
+		reconstructedIndex := gl.ReduceWithPowersNative(bits, two)
+		constraints = append(constraints, reconstructedIndex.Sub(accessIndex))
+
+		for _, b := range bits {
+			listItemsTmp := []gl.QuadraticExtension{}
+			for i := 0; i < len(listItems); i += 2 {
+				x := listItems[i]
+				y := listItems[i+1]
+
+				// This is computing `if b { x } else { y }`
+				// i.e. `x + b(y - x)`.
+				listItemsTmp = append(listItemsTmp, x.Add(b.Mul(y.Sub(x))))
+			}
+			listItems = listItemsTmp
+		}
+
+		if len(listItems) != 1 {
+			panic("listItems(len) != 1")
+		}
+
+		constraints = append(constraints, listItems[0].Sub(claimedElement))
+	}
+
+	for i := uint64(0); i < g.numExtraConstants; i++ {
+		constraints = append(constraints, vars.localConstants[i].Sub(vars.localWires[g.wireExtraConstant(i)]))
+	}
+
+	return constraints
+}
@@ -107,3 +107,21 @@ func (g *ReducingExtensionGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *ReducingExtensionGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	alpha := vars.GetLocalExtAlgebra(g.wiresAlpha())
+	acc := vars.GetLocalExtAlgebra(g.wiresOldAcc())
+
+	constraints := []gl.QuadraticExtension{}
+	for i := uint64(0); i < g.numCoeffs; i++ {
+		coeff := vars.GetLocalExtAlgebra(g.wiresCoeff(i))
+		nextAcc := vars.GetLocalExtAlgebra(g.wiresAccs(i))
+		tmp := gl.MulExtensionAlgebraNative(acc, alpha)
+		tmp = gl.AddExtensionAlgebraNative(tmp, coeff)
+		tmp = gl.SubExtensionAlgebraNative(tmp, nextAcc)
+		constraints = append(constraints, tmp[:]...)
+		acc = nextAcc
+	}
+
+	return constraints
+}
@@ -108,3 +108,22 @@ func (g *ReducingGate) EvalUnfiltered(
 
 	return constraints
 }
+
+func (g *ReducingGate) EvalUnfilteredNative(vars NativeEvaluationVars) []gl.QuadraticExtension {
+	alpha := vars.GetLocalExtAlgebra(g.wiresAlpha())
+	acc := vars.GetLocalExtAlgebra(g.wiresOldAcc())
+	coeffsRange := g.wiresCoeff()
+
+	constraints := []gl.QuadraticExtension{}
+	for i := uint64(0); i < g.numCoeffs; i++ {
+		coeff := gl.ToQuadraticExtensionAlgebra(vars.localWires[coeffsRange.start+i])
+		nextAcc := vars.GetLocalExtAlgebra(g.wiresAccs(i))
+		tmp := gl.MulExtensionAlgebraNative(acc, alpha)
+		tmp = gl.AddExtensionAlgebraNative(tmp, coeff)
+		tmp = gl.SubExtensionAlgebraNative(tmp, nextAcc)
+		constraints = append(constraints, tmp[:]...)
+		acc = nextAcc
+	}
+
+	return constraints
+}
@@ -10,9 +10,12 @@ type Range struct {
 type SelectorsInfo struct {
 	selectorIndices []uint64
 	groups          []Range
+
+	// The lookup selectors are stored in the constant polynomials right after the gate selectors.
+	numLookupSelectors uint64
 }
 
-func NewSelectorsInfo(selectorIndices []uint64, groupStarts []uint64, groupEnds []uint64) *SelectorsInfo {
+func NewSelectorsInfo(selectorIndices []uint64, groupStarts []uint64, groupEnds []uint64, numLookupSelectors uint64) *SelectorsInfo {
 	if len(groupStarts) != len(groupEnds) {
 		panic("groupStarts and groupEnds must have the same length")
 	}
@@ -28,9 +31,15 @@ func NewSelectorsInfo(selectorIndices []uint64, groupStarts []uint64, groupEnds
 	return &SelectorsInfo{
 		selectorIndices: selectorIndices,
 		groups:          groups,
+
+		numLookupSelectors: numLookupSelectors,
 	}
 }
 
 func (s *SelectorsInfo) NumSelectors() uint64 {
 	return uint64(len(s.groups))
 }
+
+func (s *SelectorsInfo) NumLookupSelectors() uint64 {
+	return s.numLookupSelectors
+}
@@ -0,0 +1,241 @@
+package plonk
+
+import (
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// The number of lookup challenges drawn per challenge round.
+const NUM_COINS_LOOKUP = 4
+
+// The index of each lookup challenge within a challenge round's deltas.
+const (
+	// Used for the linear combination of input and output pairs in Sum and LDC.
+	LOOKUP_CHALLENGE_A = iota
+	// Used for the linear combination of input and output pairs in RE.
+	LOOKUP_CHALLENGE_B
+	// Used for the running sums: 1/(alpha - combo_i).
+	LOOKUP_CHALLENGE_ALPHA
+	// The point at which the interpolated LUT functions are evaluated.
+	LOOKUP_CHALLENGE_DELTA
+)
+
+// The index of each lookup selector, which follow the gate selectors in the constant polynomials.
+const (
+	// Selector for the LDC transitions, enabled on LookupGate rows.
+	LOOKUP_SELECTOR_TRANS_LDC = iota
+	// Selector for the Sum and RE transitions, enabled on LookupTableGate rows.
+	LOOKUP_SELECTOR_TRANS_SRE
+	// Selector for the initial values of Sum and RE.
+	LOOKUP_SELECTOR_INIT_SRE
+	// Selector for the last LDC value.
+	LOOKUP_SELECTOR_LAST_LDC
+	// Index of the first of the per-LUT selectors marking the end of each LUT.
+	LOOKUP_SELECTOR_START_END
+)
+
+// Evaluates the interpolated LUT function of the lutIndex-th lookup table at the delta challenge,
+// i.e. sum_i (input_i + b * output_i) * delta^(degree - 1 - i) with the table padded with zeros to degree entries.
+func (p *PlonkChip) evalLutPoly(lutIndex int, deltas []gl.Variable, degree uint64) gl.Variable {
+	glApi := gl.New(p.api)
+	lut := p.commonData.Luts[lutIndex]
+
+	inputsEval := gl.Zero()
+	outputsEval := gl.Zero()
+	delta := deltas[LOOKUP_CHALLENGE_DELTA]
+	for i := uint64(0); i < degree; i++ {
+		if i < uint64(len(lut)) {
+			inputsEval = glApi.MulAdd(inputsEval, delta, gl.NewVariableUint64(uint64(lut[i][0])))
+			outputsEval = glApi.MulAdd(outputsEval, delta, gl.NewVariableUint64(uint64(lut[i][1])))
+		} else {
+			inputsEval = glApi.Mul(inputsEval, delta)
+			outputsEval = glApi.Mul(outputsEval, delta)
+		}
+	}
+
+	return glApi.MulAdd(deltas[LOOKUP_CHALLENGE_B], outputsEval, inputsEval)
+}
+
+// Returns prod_{j in [start, end), j != skip} (alpha - combos[j]). Pass skip >= end to include every term.
+func (p *PlonkChip) lookupProduct(
+	alpha gl.QuadraticExtensionVariable,
+	combos []gl.QuadraticExtensionVariable,
+	start uint64,
+	end uint64,
+	skip uint64,
+) gl.QuadraticExtensionVariable {
+	glApi := gl.New(p.api)
+	product := gl.OneExtension()
+	for j := start; j < end; j++ {
+		if j == skip {
+			continue
+		}
+		product = glApi.MulExtension(product, glApi.SubExtension(alpha, combos[j]))
+	}
+	return product
+}
+
+// Evaluates all the lookup constraints of the challengeNum-th challenge round, based on the
+// logarithmic derivatives paper (https://eprint.iacr.org/2022/1530.pdf). It's a port of
+// check_lookup_constraints from plonk::vanishing_poly in plonky2.
+//
+// There are three polynomials to check:
+//   - RE ensures the well formation of lookup tables;
+//   - Sum is a running sum of m_i/(alpha - (input_i + a * output_i)) over the LUT entries;
+//   - LDC is a running sum of 1/(alpha - (input_i + a * output_i)) over the looked up pairs.
+//
+// Sum and LDC share the SLDC polynomials, on LookupTableGate and LookupGate rows respectively.
+func (p *PlonkChip) checkLookupConstraints(
+	challengeNum uint64,
+	openings variables.OpeningSet,
+	deltas []gl.Variable,
+) []gl.QuadraticExtensionVariable {
+	glApi := gl.New(p.api)
+
+	numRoutedWires := p.commonData.Config.NumRoutedWires
+	numLuSlots := gates.LookupGateNumSlots(numRoutedWires)
+	numLutSlots := gates.LookupTableGateNumSlots(numRoutedWires)
+	luDegree := p.commonData.QuotientDegreeFactor - 1
+	numLookupPolys := p.commonData.NumLookupPolys
+	numSldcPolys := numLookupPolys - 1
+	lutDegree := (numLutSlots + numSldcPolys - 1) / numSldcPolys
+
+	numSelectors := p.commonData.SelectorsInfo.NumSelectors()
+	numLookupSelectors := p.commonData.SelectorsInfo.NumLookupSelectors()
+	lookupSelectors := openings.Constants[numSelectors : numSelectors+numLookupSelectors]
+
+	localLookupZs := openings.LookupZs[challengeNum*numLookupPolys : (challengeNum+1)*numLookupPolys]
+	nextLookupZs := openings.LookupZsNext[challengeNum*numLookupPolys : (challengeNum+1)*numLookupPolys]
+
+	// RE is the first polynomial stored, the partial Sums and LDCs are stored in the remaining SLDC polynomials.
+	zRe := localLookupZs[0]
+	nextZRe := nextLookupZs[0]
+	zXLookupSldcs := localLookupZs[1:]
+	zGXLookupSldcs := nextLookupZs[1:]
+
+	challengeA := deltas[LOOKUP_CHALLENGE_A]
+	challengeB := deltas[LOOKUP_CHALLENGE_B]
+	challengeAlpha := gl.NewQuadraticExtensionVariable(deltas[LOOKUP_CHALLENGE_ALPHA], gl.Zero())
+	challengeDelta := deltas[LOOKUP_CHALLENGE_DELTA]
+
+	wires := openings.Wires
+
+	// Compute all current looked and looking combos, i.e. the combos we need for the SLDC polynomials.
+	currentLookedCombos := make([]gl.QuadraticExtensionVariable, numLutSlots)
+	for s := uint64(0); s < numLutSlots; s++ {
+		inputWire := wires[gates.LookupTableGateWireIthLookedInp(s)]
+		outputWire := wires[gates.LookupTableGateWireIthLookedOut(s)]
+		currentLookedCombos[s] = glApi.AddExtension(inputWire, glApi.ScalarMulExtension(outputWire, challengeA))
+	}
+
+	currentLookingCombos := make([]gl.QuadraticExtensionVariable, numLuSlots)
+	for s := uint64(0); s < numLuSlots; s++ {
+		inputWire := wires[gates.LookupGateWireIthLookingInp(s)]
+		outputWire := wires[gates.LookupGateWireIthLookingOut(s)]
+		currentLookingCombos[s] = glApi.AddExtension(inputWire, glApi.ScalarMulExtension(outputWire, challengeA))
+	}
+
+	// Compute all current lookup combos, i.e. the combos used to check that the LUT is correct.
+	currentLookupCombos := make([]gl.QuadraticExtensionVariable, numLutSlots)
+	for s := uint64(0); s < numLutSlots; s++ {
+		inputWire := wires[gates.LookupTableGateWireIthLookedInp(s)]
+		outputWire := wires[gates.LookupTableGateWireIthLookedOut(s)]
+		currentLookupCombos[s] = glApi.AddExtension(inputWire, glApi.ScalarMulExtension(outputWire, challengeB))
+	}
+
+	constraints := make([]gl.QuadraticExtensionVariable, 0, 4+uint64(len(p.commonData.Luts))+2*numSldcPolys)
+
+	// Check last LDC constraint.
+	constraints = append(constraints, glApi.MulExtension(
+		lookupSelectors[LOOKUP_SELECTOR_LAST_LDC],
+		zXLookupSldcs[numSldcPolys-1],
+	))
+
+	// Check initial Sum constraint.
+	constraints = append(constraints, glApi.MulExtension(
+		lookupSelectors[LOOKUP_SELECTOR_INIT_SRE],
+		zXLookupSldcs[0],
+	))
+
+	// Check initial RE constraint.
+	constraints = append(constraints, glApi.MulExtension(
+		lookupSelectors[LOOKUP_SELECTOR_INIT_SRE],
+		zRe,
+	))
+
+	// Check final RE constraints for each different LUT.
+	for r := uint64(LOOKUP_SELECTOR_START_END); r < numLookupSelectors; r++ {
+		lutIndex := int(r - LOOKUP_SELECTOR_START_END)
+		lutRowNumber := (uint64(len(p.commonData.Luts[lutIndex])) + numLutSlots - 1) / numLutSlots
+		curFunctionEval := p.evalLutPoly(lutIndex, deltas, numLutSlots*lutRowNumber)
+
+		constraints = append(constraints, glApi.MulExtension(
+			lookupSelectors[r],
+			glApi.SubExtension(zRe, curFunctionEval.ToQuadraticExtension()),
+		))
+	}
+
+	// Check RE row transition constraint.
+	curSum := nextZRe
+	for _, combo := range currentLookupCombos {
+		curSum = glApi.AddExtension(glApi.ScalarMulExtension(curSum, challengeDelta), combo)
+	}
+	constraints = append(constraints, glApi.MulExtension(
+		lookupSelectors[LOOKUP_SELECTOR_TRANS_SRE],
+		glApi.SubExtension(zRe, curSum),
+	))
+
+	for poly := uint64(0); poly < numSldcPolys; poly++ {
+		lutStart := poly * lutDegree
+		lutEnd := min((poly+1)*lutDegree, numLutSlots)
+		luStart := poly * luDegree
+		luEnd := min((poly+1)*luDegree, numLuSlots)
+
+		// Compute prod(alpha - combo) for the current slot for Sum and LDC.
+		lutProd := p.lookupProduct(challengeAlpha, currentLookedCombos, lutStart, lutEnd, lutEnd)
+		luProd := p.lookupProduct(challengeAlpha, currentLookingCombos, luStart, luEnd, luEnd)
+
+		// Compute sum_i(prod_{j!=i}(alpha - combo_j)) for LDC.
+		luSumProds := gl.ZeroExtension()
+		for i := luStart; i < luEnd; i++ {
+			luSumProds = glApi.AddExtension(
+				luSumProds,
+				p.lookupProduct(challengeAlpha, currentLookingCombos, luStart, luEnd, i),
+			)
+		}
+
+		// Compute sum_i(mul_i.prod_{j!=i}(alpha - combo_j)) for Sum.
+		lutSumProdsWithMul := gl.ZeroExtension()
+		for i := lutStart; i < lutEnd; i++ {
+			lutSumProdsWithMul = glApi.MulAddExtension(
+				wires[gates.LookupTableGateWireIthMultiplicity(i)],
+				p.lookupProduct(challengeAlpha, currentLookedCombos, lutStart, lutEnd, i),
+				lutSumProdsWithMul,
+			)
+		}
+
+		// The previous element is the previous poly of the current row or the last poly of the next row.
+		prev := zGXLookupSldcs[numSldcPolys-1]
+		if poly != 0 {
+			prev = zXLookupSldcs[poly-1]
+		}
+		diff := glApi.SubExtension(zXLookupSldcs[poly], prev)
+
+		// Check Sum row and col transitions. It's the same constraint, with a row transition happening for slot == 0.
+		unfilteredSumTransition := glApi.SubExtension(glApi.MulExtension(lutProd, diff), lutSumProdsWithMul)
+		constraints = append(constraints, glApi.MulExtension(
+			lookupSelectors[LOOKUP_SELECTOR_TRANS_SRE],
+			unfilteredSumTransition,
+		))
+
+		// Check LDC row and col transitions. It's the same constraint, with a row transition happening for slot == 0.
+		unfilteredLdcTransition := glApi.AddExtension(glApi.MulExtension(luProd, diff), luSumProds)
+		constraints = append(constraints, glApi.MulExtension(
+			lookupSelectors[LOOKUP_SELECTOR_TRANS_LDC],
+			unfilteredLdcTransition,
+		))
+	}
+
+	return constraints
+}
@@ -5,6 +5,7 @@ import (
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
 	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
 	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/profiler"
 	"github.com/elliottech/gnark-plonky2-verifier/types"
 	"github.com/elliottech/gnark-plonky2-verifier/variables"
 )
@@ -24,11 +25,13 @@ type PlonkChip struct {
 	evaluateGatesChip *gates.EvaluateGatesChip
 }
 
-func NewPlonkChip(api frontend.API, commonData types.CommonCircuitData) *PlonkChip {
-	// Create the gates based on commonData GateIds
-	createdGates := []gates.Gate{}
-	for _, gateId := range commonData.GateIds {
-		createdGates = append(createdGates, gates.GateInstanceFromId(gateId))
+// Creates the chip of commonData. Unless commonData.Gates was decoded from plonky2's binary
+// serialization, the gates are parsed from GateIds with gateRegistry, or with
+// gates.DefaultGateRegistry if nil. Returns an error listing the gates the registry doesn't support.
+func NewPlonkChip(api frontend.API, commonData types.CommonCircuitData, gateRegistry *gates.GateRegistry) (*PlonkChip, error) {
+	createdGates, err := commonData.CreateGates(gateRegistry)
+	if err != nil {
+		return nil, err
 	}
 
 	evaluateGatesChip := gates.NewEvaluateGatesChip(
@@ -49,7 +52,7 @@ func NewPlonkChip(api frontend.API, commonData types.CommonCircuitData) *PlonkCh
 		commonDataKIs: gl.Uint64ArrayToVariableArray(commonData.KIs),
 
 		evaluateGatesChip: evaluateGatesChip,
-	}
+	}, nil
 }
 
 func (p *PlonkChip) expPowerOf2Extension(x gl.QuadraticExtensionVariable) gl.QuadraticExtensionVariable {
@@ -139,6 +142,7 @@ func (p *PlonkChip) evalVanishingPoly(
 
 	vanishingZ1Terms := make([]gl.QuadraticExtensionVariable, 0, p.commonData.Config.NumChallenges)
 	vanishingPartialProductsTerms := make([]gl.QuadraticExtensionVariable, 0, p.commonData.Config.NumChallenges*p.commonData.NumPartialProducts)
+	vanishingAllLookupTerms := []gl.QuadraticExtensionVariable{}
 	for i := uint64(0); i < p.commonData.Config.NumChallenges; i++ {
 		// L_0(zeta) (Z(zeta) - 1) = 0
 		z1_term := glApi.MulExtension(
@@ -146,6 +150,16 @@ func (p *PlonkChip) evalVanishingPoly(
 			glApi.SubExtension(openings.PlonkZs[i], gl.OneExtension()))
 		vanishingZ1Terms = append(vanishingZ1Terms, z1_term)
 
+		if p.commonData.NumLookupPolys != 0 {
+			curDeltas := proofChallenges.PlonkDeltas[NUM_COINS_LOOKUP*i : NUM_COINS_LOOKUP*(i+1)]
+			stop := profiler.Start("lookups")
+			vanishingAllLookupTerms = append(
+				vanishingAllLookupTerms,
+				p.checkLookupConstraints(i, openings, curDeltas)...,
+			)
+			stop()
+		}
+
 		numeratorValues := make([]gl.QuadraticExtensionVariable, 0, p.commonData.Config.NumRoutedWires)
 		denominatorValues := make([]gl.QuadraticExtensionVariable, 0, p.commonData.Config.NumRoutedWires)
 		for j := uint64(0); j < p.commonData.Config.NumRoutedWires; j++ {
@@ -176,13 +190,16 @@ func (p *PlonkChip) evalVanishingPoly(
 			denominatorValues = append(denominatorValues, denominator)
 		}
 
+		stop := profiler.Start("partial products")
 		vanishingPartialProductsTerms = append(
 			vanishingPartialProductsTerms,
 			p.checkPartialProducts(numeratorValues, denominatorValues, i, openings)...,
 		)
+		stop()
 	}
 
 	vanishingTerms := append(vanishingZ1Terms, vanishingPartialProductsTerms...)
+	vanishingTerms = append(vanishingTerms, vanishingAllLookupTerms...)
 	vanishingTerms = append(vanishingTerms, constraintTerms...)
 
 	reducedValues := make([]gl.QuadraticExtensionVariable, p.commonData.Config.NumChallenges)
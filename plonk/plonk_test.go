@@ -23,14 +23,21 @@ func (circuit *TestPlonkCircuit) Define(api frontend.API) error {
 	verifierOnlyCircuitData := circuit.VerifierOnlyCircuitData
 	proofWithPis := circuit.ProofWithPis
 
-	verifierChip := verifier.NewVerifierChip(api, commonCircuitData)
+	verifierChip, err := verifier.NewVerifierChip(api, commonCircuitData, nil)
+	if err != nil {
+		return err
+	}
 	publicInputsHash := verifierChip.GetPublicInputsHash(proofWithPis.PublicInputs)
 	proofChallenges := verifierChip.GetChallenges(proofWithPis.Proof, publicInputsHash, verifierOnlyCircuitData)
 
-	plonkChip := plonk.NewPlonkChip(
+	plonkChip, err := plonk.NewPlonkChip(
 		api,
 		commonCircuitData,
+		nil,
 	)
+	if err != nil {
+		return err
+	}
 
 	plonkChip.Verify(proofChallenges, proofWithPis.Proof.Openings, publicInputsHash)
 	return nil
@@ -45,6 +52,8 @@ func TestPlonkDecodeBlock(t *testing.T) {
 
 	proofWithPis := variables.DeserializeProofWithPublicInputs(types.ReadProofWithPublicInputs(proofWithPIsFilename))
 	commonCircuitData := types.ReadCommonCircuitData(commonCircuitDataFilename)
+	// The proof's transcript predates the observation of the FRI parameters.
+	commonCircuitData.LegacyTranscript = true
 	verifierOnlyCircuitData := variables.DeserializeVerifierOnlyCircuitData(types.ReadVerifierOnlyCircuitData(verifierOnlyCircuitDataFilename))
 
 	testCase := func() {
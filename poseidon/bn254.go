@@ -30,7 +30,7 @@ type BN254HashOut = frontend.Variable
 
 func NewBN254Chip(api frontend.API) *BN254Chip {
 	if api.Compiler().Field().Cmp(bn254.ID.ScalarField()) != 0 {
-		panic("Gnark compiler not set to BN254 scalar field")
+		panic("Gnark compiler not set to BN254 scalar field, use NewBN254EmulatedChip for other fields")
 	}
 
 	return &BN254Chip{api: api, gl: gl.New(api)}
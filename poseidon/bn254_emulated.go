@@ -0,0 +1,195 @@
+package poseidon
+
+// The BN254 Poseidon hash of BN254Chip, computed with emulated BN254 arithmetic so that it can be
+// used in circuits over other scalar fields, e.g. BLS12-377 or BLS12-381, whose native elements
+// can't hold a BN254 element.
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/emulated/emparams"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+type BN254EmulatedChip struct {
+	api   frontend.API                      `gnark:"-"`
+	field *emulated.Field[emparams.BN254Fr] `gnark:"-"`
+}
+
+type BN254EmulatedElement = *emulated.Element[emparams.BN254Fr]
+type BN254EmulatedState = [BN254_SPONGE_WIDTH]BN254EmulatedElement
+type BN254EmulatedHashOut = BN254EmulatedElement
+
+func NewBN254EmulatedChip(api frontend.API) *BN254EmulatedChip {
+	field, err := emulated.NewField[emparams.BN254Fr](api)
+	if err != nil {
+		panic(err)
+	}
+
+	return &BN254EmulatedChip{api: api, field: field}
+}
+
+// Returns the emulated BN254 field the chip computes in.
+func (c *BN254EmulatedChip) Field() *emulated.Field[emparams.BN254Fr] {
+	return c.field
+}
+
+func (c *BN254EmulatedChip) Poseidon(state BN254EmulatedState) BN254EmulatedState {
+	state = c.ark(state, 0)
+	state = c.fullRounds(state, true)
+	state = c.partialRounds(state)
+	state = c.fullRounds(state, false)
+	return state
+}
+
+// Packs up to three Goldilocks elements in a BN254 element, i.e. sum_k input[k] * 2^(64k). The
+// emulated limbs are 64 bits wide, so the Goldilocks elements are the limbs themselves.
+func (c *BN254EmulatedChip) pack(input []gl.Variable) BN254EmulatedElement {
+	limbs := make([]frontend.Variable, 4)
+	for k := range limbs {
+		if k < len(input) {
+			limbs[k] = input[k].Limb
+		} else {
+			limbs[k] = frontend.Variable(0)
+		}
+	}
+	return c.field.NewElement(limbs)
+}
+
+func (c *BN254EmulatedChip) HashNoPad(input []gl.Variable) BN254EmulatedHashOut {
+	var state BN254EmulatedState
+	for i := range state {
+		state[i] = c.field.Zero()
+	}
+
+	for i := 0; i < len(input); i += BN254_SPONGE_RATE * 3 {
+		endI := min(len(input), i+BN254_SPONGE_RATE*3)
+		rateChunk := input[i:endI]
+		for j, stateIdx := 0, 0; j < len(rateChunk); j, stateIdx = j+3, stateIdx+1 {
+			endJ := min(len(rateChunk), j+3)
+			state[stateIdx+1] = c.pack(rateChunk[j:endJ])
+		}
+
+		state = c.Poseidon(state)
+	}
+
+	return state[0]
+}
+
+func (c *BN254EmulatedChip) HashOrNoop(input []gl.Variable) BN254EmulatedHashOut {
+	if len(input) <= 3 {
+		return c.pack(input)
+	}
+	return c.HashNoPad(input)
+}
+
+func (c *BN254EmulatedChip) TwoToOne(left BN254EmulatedHashOut, right BN254EmulatedHashOut) BN254EmulatedHashOut {
+	inputs := BN254EmulatedState{c.field.Zero(), c.field.Zero(), left, right}
+	state := c.Poseidon(inputs)
+	return state[0]
+}
+
+// Splits the canonical representation of the hash into 7 byte chunks, like BN254Chip.ToVec.
+func (c *BN254EmulatedChip) ToVec(hash BN254EmulatedHashOut) []gl.Variable {
+	bits := c.field.ToBitsCanonical(hash)
+
+	returnElements := []gl.Variable{}
+
+	// Split into 7 byte chunks, since 8 byte chunks can result in collisions
+	chunkSize := 56
+	for i := 0; i < len(bits); i += chunkSize {
+		maxIdx := min(len(bits), i+chunkSize)
+		bitChunk := bits[i:maxIdx]
+		returnElements = append(returnElements, gl.NewVariable(c.api.FromBinary(bitChunk...)))
+	}
+
+	return returnElements
+}
+
+func (c *BN254EmulatedChip) fullRounds(state BN254EmulatedState, isFirst bool) BN254EmulatedState {
+	for i := 0; i < BN254_FULL_ROUNDS/2-1; i++ {
+		state = c.exp5state(state)
+		if isFirst {
+			state = c.ark(state, (i+1)*BN254_SPONGE_WIDTH)
+		} else {
+			state = c.ark(state, (BN254_FULL_ROUNDS/2+1)*BN254_SPONGE_WIDTH+BN254_PARTIAL_ROUNDS+i*BN254_SPONGE_WIDTH)
+		}
+		state = c.mix(state, mMatrix)
+	}
+
+	state = c.exp5state(state)
+	if isFirst {
+		state = c.ark(state, (BN254_FULL_ROUNDS/2)*BN254_SPONGE_WIDTH)
+		state = c.mix(state, pMatrix)
+	} else {
+		state = c.mix(state, mMatrix)
+	}
+
+	return state
+}
+
+func (c *BN254EmulatedChip) partialRounds(state BN254EmulatedState) BN254EmulatedState {
+	for i := 0; i < BN254_PARTIAL_ROUNDS; i++ {
+		state[0] = c.exp5(state[0])
+		state[0] = c.field.Add(state[0], c.constant(cConstants[(BN254_FULL_ROUNDS/2+1)*BN254_SPONGE_WIDTH+i]))
+
+		terms := make([]BN254EmulatedElement, BN254_SPONGE_WIDTH)
+		for j := 0; j < BN254_SPONGE_WIDTH; j++ {
+			terms[j] = c.field.MulNoReduce(c.constant(sConstants[(BN254_SPONGE_WIDTH*2-1)*i+j]), state[j])
+		}
+		newState0 := c.field.Reduce(c.field.Sum(terms...))
+
+		for k := 1; k < BN254_SPONGE_WIDTH; k++ {
+			product := c.field.MulNoReduce(c.constant(sConstants[(BN254_SPONGE_WIDTH*2-1)*i+BN254_SPONGE_WIDTH+k-1]), state[0])
+			state[k] = c.field.Reduce(c.field.Sum(state[k], product))
+		}
+		state[0] = newState0
+	}
+
+	return state
+}
+
+func (c *BN254EmulatedChip) ark(state BN254EmulatedState, it int) BN254EmulatedState {
+	var result BN254EmulatedState
+
+	for i := 0; i < len(state); i++ {
+		result[i] = c.field.Add(state[i], c.constant(cConstants[it+i]))
+	}
+
+	return result
+}
+
+func (c *BN254EmulatedChip) exp5(x BN254EmulatedElement) BN254EmulatedElement {
+	x2 := c.field.Mul(x, x)
+	x4 := c.field.Mul(x2, x2)
+	return c.field.Mul(x4, x)
+}
+
+func (c *BN254EmulatedChip) exp5state(state BN254EmulatedState) BN254EmulatedState {
+	for i := 0; i < BN254_SPONGE_WIDTH; i++ {
+		state[i] = c.exp5(state[i])
+	}
+	return state
+}
+
+// Multiplies the state by the constant matrix, accumulating the products of each output before
+// reducing it once.
+func (c *BN254EmulatedChip) mix(state_ BN254EmulatedState, constantMatrix [][]*big.Int) BN254EmulatedState {
+	var result BN254EmulatedState
+
+	for i := 0; i < BN254_SPONGE_WIDTH; i++ {
+		terms := make([]BN254EmulatedElement, BN254_SPONGE_WIDTH)
+		for j := 0; j < BN254_SPONGE_WIDTH; j++ {
+			terms[j] = c.field.MulNoReduce(c.constant(constantMatrix[j][i]), state_[j])
+		}
+		result[i] = c.field.Reduce(c.field.Sum(terms...))
+	}
+
+	return result
+}
+
+func (c *BN254EmulatedChip) constant(x *big.Int) BN254EmulatedElement {
+	return c.field.NewElement(x)
+}
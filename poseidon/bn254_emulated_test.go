@@ -0,0 +1,93 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/emulated/emparams"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+type TestPoseidonBN254EmulatedCircuit struct {
+	In        [BN254_SPONGE_WIDTH]emulated.Element[emparams.BN254Fr]
+	Out       [BN254_SPONGE_WIDTH]emulated.Element[emparams.BN254Fr]
+	Preimage  []gl.Variable
+	Hash      emulated.Element[emparams.BN254Fr]
+	HashAsVec []gl.Variable
+}
+
+func (circuit *TestPoseidonBN254EmulatedCircuit) Define(api frontend.API) error {
+	poseidonChip := NewBN254EmulatedChip(api)
+	field := poseidonChip.Field()
+
+	var in BN254EmulatedState
+	for i := range in {
+		in[i] = &circuit.In[i]
+	}
+	output := poseidonChip.Poseidon(in)
+	for i := 0; i < BN254_SPONGE_WIDTH; i++ {
+		field.AssertIsEqual(output[i], &circuit.Out[i])
+	}
+
+	hash := poseidonChip.HashNoPad(circuit.Preimage)
+	field.AssertIsEqual(hash, &circuit.Hash)
+	hashAsVec := poseidonChip.ToVec(hash)
+	for i := range hashAsVec {
+		api.AssertIsEqual(hashAsVec[i].Limb, circuit.HashAsVec[i].Limb)
+	}
+
+	return nil
+}
+
+func TestPoseidonBN254Emulated(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// The first two test vectors of TestPoseidonBN254.
+	in := []string{"0", "1", "2", "3"}
+	out := []string{
+		"6542985608222806190361240322586112750744169038454362455181422643027100751666",
+		"3478427836468552423396868478117894008061261013954248157992395910462939736589",
+		"1904980799580062506738911865015687096398867595589699208837816975692422464009",
+		"11971464497515232077059236682405357499403220967704831154657374522418385384151",
+	}
+
+	preimage := make([]goldilocks.Element, 11)
+	for i := range preimage {
+		preimage[i] = goldilocks.NewElement(uint64(i) * 0x9e3779b97f4a7c15)
+	}
+	hash := HashNoPadBN254Native(preimage)
+	hashAsVec := ToVecBN254Native(hash)
+
+	var witness TestPoseidonBN254EmulatedCircuit
+	for i := range in {
+		inValue, _ := new(big.Int).SetString(in[i], 10)
+		outValue, _ := new(big.Int).SetString(out[i], 10)
+		witness.In[i] = emulated.ValueOf[emparams.BN254Fr](inValue)
+		witness.Out[i] = emulated.ValueOf[emparams.BN254Fr](outValue)
+	}
+	witness.Preimage = make([]gl.Variable, len(preimage))
+	for i := range preimage {
+		witness.Preimage[i] = gl.NewVariable(preimage[i].Uint64())
+	}
+	var hashValue big.Int
+	hash.BigInt(&hashValue)
+	witness.Hash = emulated.ValueOf[emparams.BN254Fr](&hashValue)
+	witness.HashAsVec = make([]gl.Variable, len(hashAsVec))
+	for i := range hashAsVec {
+		witness.HashAsVec[i] = gl.NewVariable(hashAsVec[i].Uint64())
+	}
+
+	circuit := TestPoseidonBN254EmulatedCircuit{
+		Preimage:  make([]gl.Variable, len(preimage)),
+		HashAsVec: make([]gl.Variable, len(hashAsVec)),
+	}
+	for _, curve := range []ecc.ID{ecc.BLS12_377, ecc.BLS12_381, ecc.BN254} {
+		err := test.IsSolved(&circuit, &witness, curve.ScalarField())
+		assert.NoError(err, curve.String())
+	}
+}
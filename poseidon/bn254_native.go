@@ -0,0 +1,172 @@
+package poseidon
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+)
+
+// Out of circuit counterparts of the BN254Chip functions, used by the native verifier.
+
+type BN254StateNative = [BN254_SPONGE_WIDTH]fr.Element
+type BN254HashOutNative = fr.Element
+
+func PoseidonBN254Native(state BN254StateNative) BN254StateNative {
+	state = arkNative(state, 0)
+	state = fullRoundsBN254Native(state, true)
+	state = partialRoundsBN254Native(state)
+	state = fullRoundsBN254Native(state, false)
+	return state
+}
+
+// Packs up to three Goldilocks elements in a BN254 element, i.e. sum_k input[k] * 2^(64k).
+func packGoldilocksNative(input []goldilocks.Element) fr.Element {
+	packed := new(big.Int)
+	for k := len(input) - 1; k >= 0; k-- {
+		packed.Lsh(packed, 64)
+		packed.Add(packed, new(big.Int).SetUint64(input[k].Uint64()))
+	}
+
+	var res fr.Element
+	res.SetBigInt(packed)
+	return res
+}
+
+func HashNoPadBN254Native(input []goldilocks.Element) BN254HashOutNative {
+	var state BN254StateNative
+
+	for i := 0; i < len(input); i += BN254_SPONGE_RATE * 3 {
+		endI := min(len(input), i+BN254_SPONGE_RATE*3)
+		rateChunk := input[i:endI]
+		for j, stateIdx := 0, 0; j < len(rateChunk); j, stateIdx = j+3, stateIdx+1 {
+			endJ := min(len(rateChunk), j+3)
+			state[stateIdx+1] = packGoldilocksNative(rateChunk[j:endJ])
+		}
+
+		state = PoseidonBN254Native(state)
+	}
+
+	return state[0]
+}
+
+func HashOrNoopBN254Native(input []goldilocks.Element) BN254HashOutNative {
+	if len(input) <= 3 {
+		return packGoldilocksNative(input)
+	}
+	return HashNoPadBN254Native(input)
+}
+
+func TwoToOneBN254Native(left BN254HashOutNative, right BN254HashOutNative) BN254HashOutNative {
+	var state BN254StateNative
+	state[2] = left
+	state[3] = right
+	state = PoseidonBN254Native(state)
+	return state[0]
+}
+
+func ToVecBN254Native(hash BN254HashOutNative) []goldilocks.Element {
+	var hashBig big.Int
+	hash.BigInt(&hashBig)
+
+	returnElements := []goldilocks.Element{}
+
+	// Split into 7 byte chunks, since 8 byte chunks can result in collisions
+	chunkSize := 56
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(chunkSize)), big.NewInt(1))
+	for i := 0; i < fr.Bits; i += chunkSize {
+		chunk := new(big.Int).Rsh(&hashBig, uint(i))
+		chunk.And(chunk, mask)
+		returnElements = append(returnElements, goldilocks.NewElement(chunk.Uint64()))
+	}
+
+	return returnElements
+}
+
+func fullRoundsBN254Native(state BN254StateNative, isFirst bool) BN254StateNative {
+	for i := 0; i < BN254_FULL_ROUNDS/2-1; i++ {
+		state = exp5stateNative(state)
+		if isFirst {
+			state = arkNative(state, (i+1)*BN254_SPONGE_WIDTH)
+		} else {
+			state = arkNative(state, (BN254_FULL_ROUNDS/2+1)*BN254_SPONGE_WIDTH+BN254_PARTIAL_ROUNDS+i*BN254_SPONGE_WIDTH)
+		}
+		state = mixNative(state, mMatrix)
+	}
+
+	state = exp5stateNative(state)
+	if isFirst {
+		state = arkNative(state, (BN254_FULL_ROUNDS/2)*BN254_SPONGE_WIDTH)
+		state = mixNative(state, pMatrix)
+	} else {
+		state = mixNative(state, mMatrix)
+	}
+
+	return state
+}
+
+func partialRoundsBN254Native(state BN254StateNative) BN254StateNative {
+	for i := 0; i < BN254_PARTIAL_ROUNDS; i++ {
+		state[0] = exp5Native(state[0])
+		c := frConstant(cConstants[(BN254_FULL_ROUNDS/2+1)*BN254_SPONGE_WIDTH+i])
+		state[0].Add(&state[0], &c)
+
+		var newState0 fr.Element
+		for j := 0; j < BN254_SPONGE_WIDTH; j++ {
+			var term fr.Element
+			s := frConstant(sConstants[(BN254_SPONGE_WIDTH*2-1)*i+j])
+			term.Mul(&s, &state[j])
+			newState0.Add(&newState0, &term)
+		}
+
+		for k := 1; k < BN254_SPONGE_WIDTH; k++ {
+			var term fr.Element
+			s := frConstant(sConstants[(BN254_SPONGE_WIDTH*2-1)*i+BN254_SPONGE_WIDTH+k-1])
+			term.Mul(&state[0], &s)
+			state[k].Add(&state[k], &term)
+		}
+		state[0] = newState0
+	}
+
+	return state
+}
+
+func frConstant(c *big.Int) fr.Element {
+	var res fr.Element
+	res.SetBigInt(c)
+	return res
+}
+
+func arkNative(state BN254StateNative, it int) BN254StateNative {
+	for i := 0; i < len(state); i++ {
+		c := frConstant(cConstants[it+i])
+		state[i].Add(&state[i], &c)
+	}
+	return state
+}
+
+func exp5Native(x fr.Element) fr.Element {
+	var x4 fr.Element
+	x4.Square(&x).Square(&x4)
+	return *x4.Mul(&x4, &x)
+}
+
+func exp5stateNative(state BN254StateNative) BN254StateNative {
+	for i := 0; i < BN254_SPONGE_WIDTH; i++ {
+		state[i] = exp5Native(state[i])
+	}
+	return state
+}
+
+func mixNative(state BN254StateNative, constantMatrix [][]*big.Int) BN254StateNative {
+	var result BN254StateNative
+	for i := 0; i < BN254_SPONGE_WIDTH; i++ {
+		for j := 0; j < BN254_SPONGE_WIDTH; j++ {
+			var term fr.Element
+			c := frConstant(constantMatrix[j][i])
+			term.Mul(&c, &state[j])
+			result[i].Add(&result[i], &term)
+		}
+	}
+	return result
+}
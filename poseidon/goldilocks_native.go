@@ -0,0 +1,226 @@
+package poseidon
+
+import (
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+// Out of circuit counterparts of the GoldilocksChip functions, used by the native verifier.
+
+type GoldilocksStateNative = [SPONGE_WIDTH]goldilocks.Element
+type GoldilocksStateExtensionNative = [SPONGE_WIDTH]gl.QuadraticExtension
+type GoldilocksHashOutNative = [POSEIDON_GL_HASH_SIZE]goldilocks.Element
+
+// Converts one of the uint64 constants of this package to a field element.
+func constantElement(c frontend.Variable) goldilocks.Element {
+	return goldilocks.NewElement(c.(uint64))
+}
+
+func constantExtension(c frontend.Variable) gl.QuadraticExtension {
+	return gl.ToQuadraticExtension(constantElement(c))
+}
+
+// The permutation function, out of circuit.
+func PoseidonNative(input GoldilocksStateNative) GoldilocksStateNative {
+	state := input
+	roundCounter := 0
+	state = fullRoundsNative(state, &roundCounter)
+	state = partialRoundsNative(state, &roundCounter)
+	state = fullRoundsNative(state, &roundCounter)
+	return state
+}
+
+func HashNToMNoPadNative(input []goldilocks.Element, nbOutputs int) []goldilocks.Element {
+	var state GoldilocksStateNative
+
+	for i := 0; i < len(input); i += SPONGE_RATE {
+		for j := 0; j < SPONGE_RATE; j++ {
+			if i+j < len(input) {
+				state[j] = input[i+j]
+			}
+		}
+		state = PoseidonNative(state)
+	}
+
+	var outputs []goldilocks.Element
+
+	for {
+		for i := 0; i < SPONGE_RATE; i++ {
+			outputs = append(outputs, state[i])
+			if len(outputs) == nbOutputs {
+				return outputs
+			}
+		}
+		state = PoseidonNative(state)
+	}
+}
+
+func HashNoPadNative(input []goldilocks.Element) GoldilocksHashOutNative {
+	var hash GoldilocksHashOutNative
+	copy(hash[:], HashNToMNoPadNative(input, len(hash)))
+	return hash
+}
+
+func fullRoundsNative(state GoldilocksStateNative, roundCounter *int) GoldilocksStateNative {
+	for i := 0; i < HALF_N_FULL_ROUNDS; i++ {
+		for j := 0; j < SPONGE_WIDTH; j++ {
+			roundConstant := constantElement(ALL_ROUND_CONSTANTS[j+SPONGE_WIDTH*(*roundCounter)])
+			state[j].Add(&state[j], &roundConstant)
+			state[j] = sBoxMonomialNative(state[j])
+		}
+		state = mdsLayerNative(state)
+		*roundCounter += 1
+	}
+	return state
+}
+
+func partialRoundsNative(state GoldilocksStateNative, roundCounter *int) GoldilocksStateNative {
+	for i := 0; i < SPONGE_WIDTH; i++ {
+		roundConstant := constantElement(FAST_PARTIAL_FIRST_ROUND_CONSTANT[i])
+		state[i].Add(&state[i], &roundConstant)
+	}
+
+	var result GoldilocksStateNative
+	result[0] = state[0]
+	for r := 1; r < SPONGE_WIDTH; r++ {
+		for d := 1; d < SPONGE_WIDTH; d++ {
+			var term goldilocks.Element
+			t := constantElement(FAST_PARTIAL_ROUND_INITIAL_MATRIX[r-1][d-1])
+			term.Mul(&state[r], &t)
+			result[d].Add(&result[d], &term)
+		}
+	}
+	state = result
+
+	for i := 0; i < N_PARTIAL_ROUNDS; i++ {
+		roundConstant := constantElement(FAST_PARTIAL_ROUND_CONSTANTS[i])
+		state[0] = sBoxMonomialNative(state[0])
+		state[0].Add(&state[0], &roundConstant)
+		state = mdsPartialLayerFastNative(state, i)
+	}
+
+	*roundCounter += N_PARTIAL_ROUNDS
+
+	return state
+}
+
+func sBoxMonomialNative(x goldilocks.Element) goldilocks.Element {
+	var x2, x3, x4 goldilocks.Element
+	x2.Square(&x)
+	x4.Square(&x2)
+	x3.Mul(&x, &x2)
+	return *x3.Mul(&x3, &x4)
+}
+
+func mdsLayerNative(state GoldilocksStateNative) GoldilocksStateNative {
+	var result GoldilocksStateNative
+	for r := 0; r < SPONGE_WIDTH; r++ {
+		for i := 0; i < SPONGE_WIDTH; i++ {
+			var term goldilocks.Element
+			c := constantElement(MDS_MATRIX_CIRC[i])
+			term.Mul(&state[(i+r)%SPONGE_WIDTH], &c)
+			result[r].Add(&result[r], &term)
+		}
+		var term goldilocks.Element
+		c := constantElement(MDS_MATRIX_DIAG[r])
+		term.Mul(&state[r], &c)
+		result[r].Add(&result[r], &term)
+	}
+	return result
+}
+
+func mdsPartialLayerFastNative(state GoldilocksStateNative, r int) GoldilocksStateNative {
+	mds0to0 := constantElement(MDS0TO0)
+	var d goldilocks.Element
+	d.Mul(&state[0], &mds0to0)
+	for i := 1; i < SPONGE_WIDTH; i++ {
+		var term goldilocks.Element
+		t := constantElement(FAST_PARTIAL_ROUND_W_HATS[r][i-1])
+		term.Mul(&state[i], &t)
+		d.Add(&d, &term)
+	}
+
+	var result GoldilocksStateNative
+	result[0] = d
+	for i := 1; i < SPONGE_WIDTH; i++ {
+		t := constantElement(FAST_PARTIAL_ROUND_VS[r][i-1])
+		result[i].Mul(&state[0], &t)
+		result[i].Add(&result[i], &state[i])
+	}
+	return result
+}
+
+func ConstantLayerExtensionNative(state GoldilocksStateExtensionNative, roundCounter *int) GoldilocksStateExtensionNative {
+	for i := 0; i < SPONGE_WIDTH; i++ {
+		state[i] = state[i].Add(constantExtension(ALL_ROUND_CONSTANTS[i+SPONGE_WIDTH*(*roundCounter)]))
+	}
+	return state
+}
+
+func SBoxMonomialExtensionNative(x gl.QuadraticExtension) gl.QuadraticExtension {
+	x2 := x.Mul(x)
+	x4 := x2.Mul(x2)
+	x3 := x.Mul(x2)
+	return x4.Mul(x3)
+}
+
+func SBoxLayerExtensionNative(state GoldilocksStateExtensionNative) GoldilocksStateExtensionNative {
+	for i := 0; i < SPONGE_WIDTH; i++ {
+		state[i] = SBoxMonomialExtensionNative(state[i])
+	}
+	return state
+}
+
+func MdsRowShfExtensionNative(r int, v GoldilocksStateExtensionNative) gl.QuadraticExtension {
+	res := gl.ZeroExtensionNative()
+	for i := 0; i < SPONGE_WIDTH; i++ {
+		res = res.Add(v[(i+r)%SPONGE_WIDTH].Mul(constantExtension(MDS_MATRIX_CIRC[i])))
+	}
+	return res.Add(v[r].Mul(constantExtension(MDS_MATRIX_DIAG[r])))
+}
+
+func MdsLayerExtensionNative(state GoldilocksStateExtensionNative) GoldilocksStateExtensionNative {
+	var result GoldilocksStateExtensionNative
+	for r := 0; r < SPONGE_WIDTH; r++ {
+		result[r] = MdsRowShfExtensionNative(r, state)
+	}
+	return result
+}
+
+func PartialFirstConstantLayerExtensionNative(state GoldilocksStateExtensionNative) GoldilocksStateExtensionNative {
+	for i := 0; i < SPONGE_WIDTH; i++ {
+		state[i] = state[i].Add(constantExtension(FAST_PARTIAL_FIRST_ROUND_CONSTANT[i]))
+	}
+	return state
+}
+
+func MdsPartialLayerInitExtensionNative(state GoldilocksStateExtensionNative) GoldilocksStateExtensionNative {
+	var result GoldilocksStateExtensionNative
+	for i := 0; i < SPONGE_WIDTH; i++ {
+		result[i] = gl.ZeroExtensionNative()
+	}
+
+	result[0] = state[0]
+	for r := 1; r < SPONGE_WIDTH; r++ {
+		for d := 1; d < SPONGE_WIDTH; d++ {
+			t := constantExtension(FAST_PARTIAL_ROUND_INITIAL_MATRIX[r-1][d-1])
+			result[d] = result[d].Add(state[r].Mul(t))
+		}
+	}
+	return result
+}
+
+func MdsPartialLayerFastExtensionNative(state GoldilocksStateExtensionNative, r int) GoldilocksStateExtensionNative {
+	d := state[0].Mul(constantExtension(MDS0TO0))
+	for i := 1; i < SPONGE_WIDTH; i++ {
+		d = d.Add(state[i].Mul(constantExtension(FAST_PARTIAL_ROUND_W_HATS[r][i-1])))
+	}
+
+	var result GoldilocksStateExtensionNative
+	result[0] = d
+	for i := 1; i < SPONGE_WIDTH; i++ {
+		result[i] = state[0].Mul(constantExtension(FAST_PARTIAL_ROUND_VS[r][i-1])).Add(state[i])
+	}
+	return result
+}
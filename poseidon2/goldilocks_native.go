@@ -0,0 +1,91 @@
+package poseidon2
+
+import (
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+// Out of circuit counterparts of the GoldilocksChip functions, used by the native verifier.
+
+type GoldilocksStateExtensionNative = [WIDTH]gl.QuadraticExtension
+
+func ExternalLinearLayerExtensionNative(state GoldilocksStateExtensionNative) GoldilocksStateExtensionNative {
+	for i := 0; i < 3; i++ {
+		state4 := [4]gl.QuadraticExtension{state[4*i], state[4*i+1], state[4*i+2], state[4*i+3]}
+		result4 := ApplyMat4MutExtensionNative(state4)
+		copy(state[4*i:4*i+4], result4[:])
+	}
+
+	var sums [4]gl.QuadraticExtension
+	for i := 0; i < 4; i++ {
+		sums[i] = state[i].Add(state[i+4]).Add(state[i+8])
+	}
+
+	for i := 0; i < WIDTH; i++ {
+		state[i] = state[i].Add(sums[i%4])
+	}
+
+	return state
+}
+
+func ApplyMat4MutExtensionNative(x [4]gl.QuadraticExtension) [4]gl.QuadraticExtension {
+	t01 := x[0].Add(x[1])
+	t23 := x[2].Add(x[3])
+	t0123 := t01.Add(t23)
+	t01123 := t0123.Add(x[1])
+	t01233 := t0123.Add(x[3])
+
+	return [4]gl.QuadraticExtension{
+		t01123.Add(t01),
+		t01123.Add(x[2].Add(x[2])),
+		t01233.Add(t23),
+		t01233.Add(x[0].Add(x[0])),
+	}
+}
+
+func AddRCExtensionNative(state GoldilocksStateExtensionNative, round int) GoldilocksStateExtensionNative {
+	if round >= len(EXTERNAL_CONSTANTS) {
+		panic("round index out of range in AddRCExtensionNative")
+	}
+
+	for i := 0; i < WIDTH; i++ {
+		state[i] = state[i].Add(gl.NewQuadraticExtensionUint64(EXTERNAL_CONSTANTS[round][i], 0))
+	}
+
+	return state
+}
+
+func InternalLinearLayerExtensionNative(state GoldilocksStateExtensionNative) GoldilocksStateExtensionNative {
+	sum := gl.ZeroExtensionNative()
+	for i := 0; i < WIDTH; i++ {
+		sum = sum.Add(state[i])
+	}
+
+	for i := 0; i < WIDTH; i++ {
+		m := gl.NewQuadraticExtensionUint64(MATRIX_DIAG_12_U64[i], 0)
+		state[i] = sum.Add(state[i].Mul(m))
+	}
+
+	return state
+}
+
+func AddInternalConstantExtensionNative(x gl.QuadraticExtension, round int) gl.QuadraticExtension {
+	if round >= len(INTERNAL_CONSTANTS) {
+		panic("round index out of range in AddInternalConstantExtensionNative")
+	}
+
+	return x.Add(gl.NewQuadraticExtensionUint64(INTERNAL_CONSTANTS[round], 0))
+}
+
+func SBoxPExtensionNative(x gl.QuadraticExtension) gl.QuadraticExtension {
+	x2 := x.Mul(x)
+	x4 := x2.Mul(x2)
+	x3 := x.Mul(x2)
+	return x4.Mul(x3)
+}
+
+func SBoxLayerExtensionNative(state GoldilocksStateExtensionNative) GoldilocksStateExtensionNative {
+	for i := 0; i < WIDTH; i++ {
+		state[i] = SBoxPExtensionNative(state[i])
+	}
+	return state
+}
@@ -0,0 +1,181 @@
+// Package profiler counts the constraints of the verifier circuit per subsystem.
+//
+// The chips tag their subsystems with Start, which is a no-op unless a circuit is being compiled
+// by Record:
+//
+//	defer profiler.Start("fri")()
+//
+// Sections nest: the constraints of a section include those of the sections started inside it.
+// Sections started several times with the same name under the same parent are summed.
+package profiler
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+	"github.com/consensys/gnark/profile"
+)
+
+// Section is the number of constraints of a tagged subsystem of a circuit.
+type Section struct {
+	Name          string
+	NbConstraints int
+	Sections      []*Section
+}
+
+// Profile is the number of constraints of the sections of a circuit, see Record.
+type Profile struct {
+	// All the constraints of the circuit, including those added outside of any section, e.g. the
+	// range checks gnark defers to the end of the compilation.
+	NbConstraints int
+	Sections      []*Section
+}
+
+type recorder struct {
+	// The gnark profile of the whole compilation.
+	session *profile.Profile
+
+	root  Section
+	stack []*Section
+}
+
+// Returns the number of constraints recorded by the session so far. gnark records them
+// asynchronously, in the order they're added: starting and stopping another session waits for
+// those added before.
+func (r *recorder) nbConstraints() int {
+	profile.Start(profile.WithNoOutput()).Stop()
+	return r.session.NbConstraints()
+}
+
+// The recorder of the compilation run by Record, nil otherwise. Guarded by mu, as Start is called
+// by every compilation, including those running concurrently with Record.
+var (
+	mu     sync.Mutex
+	active *recorder
+)
+
+func (s *Section) child(name string) *Section {
+	for _, section := range s.Sections {
+		if section.Name == name {
+			return section
+		}
+	}
+	section := &Section{Name: name}
+	s.Sections = append(s.Sections, section)
+	return section
+}
+
+// Start tags the constraints added until the returned function is called with name, as a section of
+// the innermost section started before.
+func Start(name string) (stop func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	if active == nil {
+		return func() {}
+	}
+
+	recorder := active
+	section := recorder.stack[len(recorder.stack)-1].child(name)
+	recorder.stack = append(recorder.stack, section)
+	start := recorder.nbConstraints()
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		// The compilation run by Record returned before the section was stopped.
+		if active != recorder {
+			return
+		}
+		section.NbConstraints += recorder.nbConstraints() - start
+		recorder.stack = recorder.stack[:len(recorder.stack)-1]
+	}
+}
+
+// Record compiles circuit with newBuilder, counting the constraints of the sections started during
+// the compilation. If pprofPath isn't empty, the gnark pprof profile of the whole compilation is
+// written there, e.g. for `go tool pprof --png`.
+//
+// gnark profiles are global, so Record must not be called concurrently, and the sections of circuits
+// compiled concurrently with it are counted in its profile. The gnark logger is disabled during the
+// compilation, as Start logs a profiling session.
+func Record(field *big.Int, newBuilder frontend.NewBuilder, circuit frontend.Circuit, pprofPath string) (*Profile, constraint.ConstraintSystem, error) {
+	mu.Lock()
+	if active != nil {
+		mu.Unlock()
+		panic("a circuit is already being profiled")
+	}
+
+	previousLogger := logger.Logger()
+	logger.Disable()
+
+	options := []profile.Option{profile.WithNoOutput()}
+	if pprofPath != "" {
+		options = []profile.Option{profile.WithPath(pprofPath)}
+	}
+
+	recorder := &recorder{session: profile.Start(options...)}
+	recorder.stack = []*Section{&recorder.root}
+	active = recorder
+	mu.Unlock()
+
+	var sections []*Section
+	ccs, err := func() (constraint.ConstraintSystem, error) {
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			recorder.session.Stop()
+			active = nil
+			sections = recorder.root.Sections
+			logger.Set(previousLogger)
+		}()
+		return frontend.Compile(field, newBuilder, circuit)
+	}()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Profile{
+		NbConstraints: ccs.GetNbConstraints(),
+		Sections:      sections,
+	}, ccs, nil
+}
+
+// WriteTable writes the sections of the profile as a table, indented by nesting, and the constraints
+// outside of any section.
+func (p *Profile) WriteTable(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(table, "section\tconstraints\t%%\t\n")
+
+	row := func(name string, nbConstraints int) {
+		percentage := 0.0
+		if p.NbConstraints != 0 {
+			percentage = 100 * float64(nbConstraints) / float64(p.NbConstraints)
+		}
+		fmt.Fprintf(table, "%s\t%d\t%.2f\t\n", name, nbConstraints, percentage)
+	}
+
+	var writeSections func(sections []*Section, depth int)
+	writeSections = func(sections []*Section, depth int) {
+		for _, section := range sections {
+			row(strings.Repeat("  ", depth)+section.Name, section.NbConstraints)
+			writeSections(section.Sections, depth+1)
+		}
+	}
+	writeSections(p.Sections, 0)
+
+	untagged := p.NbConstraints
+	for _, section := range p.Sections {
+		untagged -= section.NbConstraints
+	}
+	row("other (deferred range checks, commitments)", untagged)
+	row("total", p.NbConstraints)
+
+	return table.Flush()
+}
@@ -0,0 +1,114 @@
+package profiler
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+// Multiplies X by itself in sections, each multiplication of variables being one R1CS constraint.
+type sectionsCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *sectionsCircuit) Define(api frontend.API) error {
+	acc := c.X
+	mul := func(n int) {
+		for i := 0; i < n; i++ {
+			acc = api.Mul(acc, c.X)
+		}
+	}
+
+	stop := Start("outer")
+	mul(2)
+	for i := 0; i < 2; i++ {
+		stop := Start("inner")
+		mul(3)
+		stop()
+	}
+	stop()
+
+	stop = Start("second")
+	mul(1)
+	stop()
+
+	api.AssertIsEqual(acc, c.Y)
+	return nil
+}
+
+func TestRecord(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	pprofPath := filepath.Join(t.TempDir(), "gnark.pprof")
+	profile, ccs, err := Record(ecc.BN254.ScalarField(), r1cs.NewBuilder, &sectionsCircuit{}, pprofPath)
+	assert.NoError(err)
+
+	assert.Equal(ccs.GetNbConstraints(), profile.NbConstraints)
+	assert.Equal(10, profile.NbConstraints)
+
+	assert.Equal(2, len(profile.Sections))
+	outer, second := profile.Sections[0], profile.Sections[1]
+	assert.Equal("outer", outer.Name)
+	assert.Equal(8, outer.NbConstraints)
+	assert.Equal(1, len(outer.Sections))
+	assert.Equal("inner", outer.Sections[0].Name)
+	assert.Equal(6, outer.Sections[0].NbConstraints)
+	assert.Equal("second", second.Name)
+	assert.Equal(1, second.NbConstraints)
+
+	var table bytes.Buffer
+	assert.NoError(profile.WriteTable(&table))
+	assert.Contains(table.String(), "  inner")
+	assert.Contains(table.String(), "total")
+
+	info, err := os.Stat(pprofPath)
+	assert.NoError(err)
+	assert.NotZero(info.Size())
+}
+
+func TestStartWithoutRecord(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Sections of a plain compilation are ignored.
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &sectionsCircuit{})
+	assert.NoError(err)
+	assert.Equal(10, ccs.GetNbConstraints())
+	assert.Nil(active)
+}
+
+func TestStartConcurrentWithRecord(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// Sections started by other goroutines while Record runs only race on the recorder without mu,
+	// which go test -race reports.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					Start("concurrent")()
+				}
+			}
+		}()
+	}
+	profile, _, err := Record(ecc.BN254.ScalarField(), r1cs.NewBuilder, &sectionsCircuit{}, "")
+	close(done)
+	wg.Wait()
+	assert.NoError(err)
+	assert.NotEmpty(profile.Sections)
+	assert.Nil(active)
+}
@@ -0,0 +1,75 @@
+package recursion
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra"
+	"github.com/consensys/gnark/std/math/emulated"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// An outer circuit verifying a Groth16 proof of an inner circuit, typically a
+// verifier.VerifierCircuit. The type parameters select the curve of the inner proof, e.g.
+// sw_bls12377 for a BW6-761 outer circuit or the emulated sw_bn254 for a BN254 one.
+//
+// The inner verifying key is a constant of the circuit, and the inner public witness (the plonky2
+// public inputs for a VerifierCircuit) is the public input of the outer circuit. The inner proof
+// must be generated with stdgroth16.GetNativeProverOptions for the outer field.
+type Groth16Circuit[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT] struct {
+	InnerWitness stdgroth16.Witness[FR] `gnark:",public"`
+	Proof        stdgroth16.Proof[G1El, G2El]
+
+	// This is configuration for the circuit, it is a constant not a variable
+	VerifyingKey stdgroth16.VerifyingKey[G1El, G2El, GtEl] `gnark:"-"`
+}
+
+// Creates the placeholder Groth16Circuit to compile, verifying proofs of innerCcs under innerVk.
+func NewGroth16Circuit[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT](
+	innerCcs constraint.ConstraintSystem,
+	innerVk groth16.VerifyingKey,
+) (*Groth16Circuit[FR, G1El, G2El, GtEl], error) {
+	verifyingKey, err := stdgroth16.ValueOfVerifyingKeyFixed[G1El, G2El, GtEl](innerVk)
+	if err != nil {
+		return nil, fmt.Errorf("inner verifying key: %w", err)
+	}
+
+	return &Groth16Circuit[FR, G1El, G2El, GtEl]{
+		InnerWitness: stdgroth16.PlaceholderWitness[FR](innerCcs),
+		Proof:        stdgroth16.PlaceholderProof[G1El, G2El](innerCcs),
+		VerifyingKey: verifyingKey,
+	}, nil
+}
+
+// Creates a Groth16Circuit witness assignment from an inner proof and its witness, of which only
+// the public part is used.
+func NewGroth16CircuitAssignment[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT](
+	innerProof groth16.Proof,
+	innerWitness witness.Witness,
+) (*Groth16Circuit[FR, G1El, G2El, GtEl], error) {
+	proof, err := stdgroth16.ValueOfProof[G1El, G2El](innerProof)
+	if err != nil {
+		return nil, fmt.Errorf("inner proof: %w", err)
+	}
+	publicWitness, err := stdgroth16.ValueOfWitness[FR](innerWitness)
+	if err != nil {
+		return nil, fmt.Errorf("inner witness: %w", err)
+	}
+
+	return &Groth16Circuit[FR, G1El, G2El, GtEl]{
+		InnerWitness: publicWitness,
+		Proof:        proof,
+	}, nil
+}
+
+func (c *Groth16Circuit[FR, G1El, G2El, GtEl]) Define(api frontend.API) error {
+	verifier, err := stdgroth16.NewVerifier[FR, G1El, G2El, GtEl](api)
+	if err != nil {
+		return fmt.Errorf("new verifier: %w", err)
+	}
+
+	return verifier.AssertProof(c.VerifyingKey, c.Proof, c.InnerWitness)
+}
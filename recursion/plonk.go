@@ -0,0 +1,71 @@
+package recursion
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra"
+	"github.com/consensys/gnark/std/math/emulated"
+	stdplonk "github.com/consensys/gnark/std/recursion/plonk"
+)
+
+// An outer circuit verifying a PLONK proof of an inner circuit, the PLONK counterpart of
+// Groth16Circuit. The inner proof must be generated with stdplonk.GetNativeProverOptions for the
+// outer field.
+type PlonkCircuit[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT] struct {
+	InnerWitness stdplonk.Witness[FR] `gnark:",public"`
+	Proof        stdplonk.Proof[FR, G1El, G2El]
+
+	// This is configuration for the circuit, it is a constant not a variable
+	VerifyingKey stdplonk.VerifyingKey[FR, G1El, G2El] `gnark:"-"`
+}
+
+// Creates the placeholder PlonkCircuit to compile, verifying proofs of innerCcs under innerVk.
+func NewPlonkCircuit[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT](
+	innerCcs constraint.ConstraintSystem,
+	innerVk plonk.VerifyingKey,
+) (*PlonkCircuit[FR, G1El, G2El, GtEl], error) {
+	verifyingKey, err := stdplonk.ValueOfVerifyingKey[FR, G1El, G2El](innerVk)
+	if err != nil {
+		return nil, fmt.Errorf("inner verifying key: %w", err)
+	}
+
+	return &PlonkCircuit[FR, G1El, G2El, GtEl]{
+		InnerWitness: stdplonk.PlaceholderWitness[FR](innerCcs),
+		Proof:        stdplonk.PlaceholderProof[FR, G1El, G2El](innerCcs),
+		VerifyingKey: verifyingKey,
+	}, nil
+}
+
+// Creates a PlonkCircuit witness assignment from an inner proof and its witness, of which only the
+// public part is used.
+func NewPlonkCircuitAssignment[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT](
+	innerProof plonk.Proof,
+	innerWitness witness.Witness,
+) (*PlonkCircuit[FR, G1El, G2El, GtEl], error) {
+	proof, err := stdplonk.ValueOfProof[FR, G1El, G2El](innerProof)
+	if err != nil {
+		return nil, fmt.Errorf("inner proof: %w", err)
+	}
+	publicWitness, err := stdplonk.ValueOfWitness[FR](innerWitness)
+	if err != nil {
+		return nil, fmt.Errorf("inner witness: %w", err)
+	}
+
+	return &PlonkCircuit[FR, G1El, G2El, GtEl]{
+		InnerWitness: publicWitness,
+		Proof:        proof,
+	}, nil
+}
+
+func (c *PlonkCircuit[FR, G1El, G2El, GtEl]) Define(api frontend.API) error {
+	verifier, err := stdplonk.NewVerifier[FR, G1El, G2El, GtEl](api)
+	if err != nil {
+		return fmt.Errorf("new verifier: %w", err)
+	}
+
+	return verifier.AssertProof(c.VerifyingKey, c.Proof, c.InnerWitness)
+}
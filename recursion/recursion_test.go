@@ -0,0 +1,180 @@
+package recursion_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	stdplonk "github.com/consensys/gnark/std/recursion/plonk"
+	"github.com/consensys/gnark/test"
+	"github.com/consensys/gnark/test/unsafekzg"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/recursion"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+	"github.com/elliottech/gnark-plonky2-verifier/verifier"
+)
+
+// A small stand-in for a plonky2 verifier circuit, whose public inputs are Goldilocks elements: it
+// proves the knowledge of a Poseidon preimage of PublicInputs.
+type innerCircuit struct {
+	PublicInputs poseidon.GoldilocksHashOut `gnark:",public"`
+	Preimage     [3]gl.Variable
+}
+
+func (c *innerCircuit) Define(api frontend.API) error {
+	glChip := gl.New(api)
+	hash := poseidon.NewGoldilocksChip(api).HashNoPad(c.Preimage[:])
+	for i := range hash {
+		glChip.AssertIsEqual(hash[i], c.PublicInputs[i])
+	}
+	return nil
+}
+
+func innerAssignment() *innerCircuit {
+	preimage := []goldilocks.Element{goldilocks.NewElement(0), goldilocks.NewElement(1), goldilocks.NewElement(3736710860384812976)}
+	hash := poseidon.HashNoPadNative(preimage)
+
+	var assignment innerCircuit
+	for i := range preimage {
+		assignment.Preimage[i] = gl.NewVariable(preimage[i].Uint64())
+	}
+	for i := range hash {
+		assignment.PublicInputs[i] = gl.NewVariable(hash[i].Uint64())
+	}
+	return &assignment
+}
+
+// Proves assignment to the inner circuit with Groth16 over innerField, for a circuit over outerField.
+func innerGroth16Proof(t *testing.T, innerField, outerField *big.Int, circuit, assignment frontend.Circuit) (constraint.ConstraintSystem, groth16.VerifyingKey, groth16.Proof, witness.Witness) {
+	ccs, err := frontend.Compile(innerField, r1cs.NewBuilder, circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fullWitness, err := frontend.NewWitness(assignment, innerField)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, fullWitness, stdgroth16.GetNativeProverOptions(outerField, innerField))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitness, err := fullWitness.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := groth16.Verify(proof, vk, publicWitness, stdgroth16.GetNativeVerifierOptions(outerField, innerField)); err != nil {
+		t.Fatal(err)
+	}
+	return ccs, vk, proof, publicWitness
+}
+
+func TestGroth16CircuitBLS12377InBW6761(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	innerCcs, innerVk, innerProof, innerWitness := innerGroth16Proof(t, ecc.BLS12_377.ScalarField(), ecc.BW6_761.ScalarField(), &innerCircuit{}, innerAssignment())
+
+	circuit, err := recursion.NewGroth16Circuit[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT](innerCcs, innerVk)
+	assert.NoError(err)
+	assignment, err := recursion.NewGroth16CircuitAssignment[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT](innerProof, innerWitness)
+	assert.NoError(err)
+
+	err = test.IsSolved(circuit, assignment, ecc.BW6_761.ScalarField())
+	assert.NoError(err)
+
+	// The outer circuit binds the inner public inputs.
+	assignment.InnerWitness.Public[0] = assignment.InnerWitness.Public[1]
+	err = test.IsSolved(circuit, assignment, ecc.BW6_761.ScalarField())
+	assert.Error(err)
+}
+
+func TestGroth16CircuitBN254InBN254(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	field := ecc.BN254.ScalarField()
+	innerCcs, innerVk, innerProof, innerWitness := innerGroth16Proof(t, field, field, &innerCircuit{}, innerAssignment())
+
+	circuit, err := recursion.NewGroth16Circuit[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](innerCcs, innerVk)
+	assert.NoError(err)
+	assignment, err := recursion.NewGroth16CircuitAssignment[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](innerProof, innerWitness)
+	assert.NoError(err)
+
+	err = test.IsSolved(circuit, assignment, field)
+	assert.NoError(err)
+
+	assignment.InnerWitness.Public[0] = assignment.InnerWitness.Public[1]
+	err = test.IsSolved(circuit, assignment, field)
+	assert.Error(err)
+}
+
+// Wraps a Groth16 proof of the plonky2 verifier circuit of step in a BN254 circuit. The Groth16
+// setup of the verifier circuit, of over 5 million constraints, needs more than 5 GB of memory.
+func TestGroth16CircuitVerifierCircuitBN254InBN254(t *testing.T) {
+	if testing.Short() {
+		t.Skip("proving the verifier circuit is slow")
+	}
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/step/common_circuit_data.json")
+	// The proof's transcript predates the observation of the FRI parameters.
+	commonCircuitData.LegacyTranscript = true
+	proofWithPis := variables.DeserializeProofWithPublicInputs(types.ReadProofWithPublicInputs("../testdata/step/proof_with_public_inputs.json"))
+	verifierOnlyCircuitData := variables.DeserializeVerifierOnlyCircuitData(types.ReadVerifierOnlyCircuitData("../testdata/step/verifier_only_circuit_data.json"))
+
+	field := ecc.BN254.ScalarField()
+	innerCcs, innerVk, innerProof, innerWitness := innerGroth16Proof(
+		t, field, field,
+		verifier.NewVerifierCircuit(commonCircuitData, verifierOnlyCircuitData),
+		verifier.NewVerifierCircuitAssignment(proofWithPis),
+	)
+
+	circuit, err := recursion.NewGroth16Circuit[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](innerCcs, innerVk)
+	assert.NoError(err)
+	assignment, err := recursion.NewGroth16CircuitAssignment[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](innerProof, innerWitness)
+	assert.NoError(err)
+
+	err = test.IsSolved(circuit, assignment, field)
+	assert.NoError(err)
+}
+
+func TestPlonkCircuitBN254InBN254(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	innerField := ecc.BN254.ScalarField()
+	outerField := ecc.BN254.ScalarField()
+
+	innerCcs, err := frontend.Compile(innerField, scs.NewBuilder, &innerCircuit{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(innerCcs)
+	assert.NoError(err)
+	innerPk, innerVk, err := plonk.Setup(innerCcs, srs, srsLagrange)
+	assert.NoError(err)
+	innerWitness, err := frontend.NewWitness(innerAssignment(), innerField)
+	assert.NoError(err)
+	innerProof, err := plonk.Prove(innerCcs, innerPk, innerWitness, stdplonk.GetNativeProverOptions(outerField, innerField))
+	assert.NoError(err)
+
+	circuit, err := recursion.NewPlonkCircuit[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](innerCcs, innerVk)
+	assert.NoError(err)
+	assignment, err := recursion.NewPlonkCircuitAssignment[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](innerProof, innerWitness)
+	assert.NoError(err)
+
+	err = test.IsSolved(circuit, assignment, outerField)
+	assert.NoError(err)
+}
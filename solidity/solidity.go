@@ -0,0 +1,145 @@
+// Package solidity exports the verifying key of the verifier circuit as a Solidity contract, and
+// encodes proofs of the verifier circuit with their plonky2 public inputs into calldata for it.
+//
+// Groth16 proofs of circuits with commitments, like the verifier circuit, only verify on chain if
+// they are proven with solidity.WithProverTargetSolidityVerifier(backend.GROTH16), which hashes
+// the commitments with keccak256 as the exported contract does.
+package solidity
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/backend/solidity"
+	"golang.org/x/crypto/sha3"
+)
+
+// The size of an ABI word.
+const wordSize = 32
+
+// ExportVerifyingKey writes the Solidity verifier contract of a Groth16 or PLONK verifying key.
+func ExportVerifyingKey(w io.Writer, vk solidity.VerifyingKey) error {
+	return vk.ExportSolidity(w)
+}
+
+// Calldata encodes a call to the contract exported by ExportVerifyingKey verifying proof, a BN254
+// Groth16 or PLONK proof of the verifier circuit, against the plonky2 public inputs.
+//
+// The public inputs of the verifier circuit are the plonky2 public inputs, one scalar field
+// element each, in the order of VerifierCircuit.PublicInputs. The circuit is bound to the verifier
+// only circuit data it's compiled with, so the contract only accepts proofs of that plonky2 circuit.
+func Calldata(proof any, publicInputs []uint64) ([]byte, error) {
+	return calldata(proof, publicInputsWords(publicInputs))
+}
+
+// UniversalCalldata is Calldata for a proof of verifier.UniversalVerifierCircuit, whose public
+// inputs are the plonky2 public inputs followed by the elements of the circuit digest.
+//
+// The contract exported for the universal verifier circuit accepts proofs of any plonky2 circuit
+// with its common circuit data: whoever calls it must check that the circuit digest inputs are
+// those of an accepted plonky2 circuit.
+func UniversalCalldata(proof any, publicInputs []uint64, circuitDigest []*big.Int) ([]byte, error) {
+	return calldata(proof, append(publicInputsWords(publicInputs), circuitDigest...))
+}
+
+func publicInputsWords(publicInputs []uint64) []*big.Int {
+	inputs := make([]*big.Int, len(publicInputs))
+	for i, publicInput := range publicInputs {
+		inputs[i] = new(big.Int).SetUint64(publicInput)
+	}
+	return inputs
+}
+
+func calldata(proof any, inputs []*big.Int) ([]byte, error) {
+	switch proof := proof.(type) {
+	case *groth16_bn254.Proof:
+		return groth16Calldata(proof, inputs), nil
+	case *plonk_bn254.Proof:
+		return plonkCalldata(proof, inputs), nil
+	default:
+		return nil, fmt.Errorf("unsupported proof type %T, expected a BN254 Groth16 or PLONK proof", proof)
+	}
+}
+
+// Encodes verifyProof(uint256[8] proof, uint256[2n] commitments, uint256[2] commitmentPok,
+// uint256[m] input), or verifyProof(uint256[8] proof, uint256[m] input) without commitments. The
+// arrays are static, so their words are inlined in order.
+func groth16Calldata(proof *groth16_bn254.Proof, inputs []*big.Int) []byte {
+	signature := "verifyProof(uint256[8],"
+	if len(proof.Commitments) > 0 {
+		signature += fmt.Sprintf("uint256[%d],uint256[2],", 2*len(proof.Commitments))
+	}
+	signature += fmt.Sprintf("uint256[%d])", len(inputs))
+
+	calldata := selector(signature)
+
+	// The raw encoding of the points is the EIP-197 one: x | y, with the imaginary part of G2
+	// coordinates first.
+	arBytes := proof.Ar.RawBytes()
+	bsBytes := proof.Bs.RawBytes()
+	krsBytes := proof.Krs.RawBytes()
+	calldata = append(calldata, arBytes[:]...)
+	calldata = append(calldata, bsBytes[:]...)
+	calldata = append(calldata, krsBytes[:]...)
+
+	if len(proof.Commitments) > 0 {
+		for _, commitment := range proof.Commitments {
+			commitmentBytes := commitment.RawBytes()
+			calldata = append(calldata, commitmentBytes[:]...)
+		}
+		pokBytes := proof.CommitmentPok.RawBytes()
+		calldata = append(calldata, pokBytes[:]...)
+	}
+
+	for _, input := range inputs {
+		calldata = append(calldata, word(input)...)
+	}
+
+	return calldata
+}
+
+// Encodes Verify(bytes proof, uint256[] public_inputs). Both arguments are dynamic: the head holds
+// their offsets, and the tail their lengths followed by their padded contents.
+func plonkCalldata(proof *plonk_bn254.Proof, inputs []*big.Int) []byte {
+	proofBytes := proof.MarshalSolidity()
+	paddedProofLen := (len(proofBytes) + wordSize - 1) / wordSize * wordSize
+
+	calldata := selector("Verify(bytes,uint256[])")
+
+	proofOffset := 2 * wordSize
+	inputsOffset := proofOffset + wordSize + paddedProofLen
+	calldata = append(calldata, uintWord(uint64(proofOffset))...)
+	calldata = append(calldata, uintWord(uint64(inputsOffset))...)
+
+	calldata = append(calldata, uintWord(uint64(len(proofBytes)))...)
+	calldata = append(calldata, proofBytes...)
+	calldata = append(calldata, make([]byte, paddedProofLen-len(proofBytes))...)
+
+	calldata = append(calldata, uintWord(uint64(len(inputs)))...)
+	for _, input := range inputs {
+		calldata = append(calldata, word(input)...)
+	}
+
+	return calldata
+}
+
+// Returns the first 4 bytes of the keccak256 hash of a function signature.
+func selector(signature string) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte(signature))
+	return hasher.Sum(nil)[:4]
+}
+
+func word(x *big.Int) []byte {
+	return x.FillBytes(make([]byte, wordSize))
+}
+
+func uintWord(x uint64) []byte {
+	w := make([]byte, wordSize)
+	binary.BigEndian.PutUint64(w[wordSize-8:], x)
+	return w
+}
@@ -0,0 +1,165 @@
+package solidity
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	"github.com/consensys/gnark/test/unsafekzg"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+// Exposes its public inputs like VerifierCircuit, and range checks them with the Goldilocks chip,
+// which commits to the range checked values.
+type publicInputsCircuit struct {
+	PublicInputs []gl.Variable `gnark:",public"`
+}
+
+func (c *publicInputsCircuit) Define(api frontend.API) error {
+	glApi := gl.New(api)
+	product := glApi.Mul(c.PublicInputs[0], c.PublicInputs[1])
+	glApi.AssertIsEqual(product, c.PublicInputs[2])
+	return nil
+}
+
+var publicInputs = []uint64{3, 0xffffffff00000000, 0xfffffffefffffffe}
+
+func newPublicInputsWitness() (*publicInputsCircuit, *publicInputsCircuit) {
+	circuit := publicInputsCircuit{PublicInputs: make([]gl.Variable, len(publicInputs))}
+	assignment := publicInputsCircuit{PublicInputs: make([]gl.Variable, len(publicInputs))}
+	for i, publicInput := range publicInputs {
+		assignment.PublicInputs[i] = gl.NewVariableUint64(publicInput)
+	}
+	return &circuit, &assignment
+}
+
+func readWords(assert *test.Assert, calldata []byte, n int) ([]*big.Int, []byte) {
+	assert.GreaterOrEqual(len(calldata), n*wordSize)
+	words := make([]*big.Int, n)
+	for i := range words {
+		words[i] = new(big.Int).SetBytes(calldata[i*wordSize : (i+1)*wordSize])
+	}
+	return words, calldata[n*wordSize:]
+}
+
+func assertPublicInputWords(assert *test.Assert, words []*big.Int) {
+	assert.Equal(len(publicInputs), len(words))
+	for i, publicInput := range publicInputs {
+		assert.Equal(new(big.Int).SetUint64(publicInput).String(), words[i].String(), "public input %d", i)
+	}
+}
+
+func TestGroth16Calldata(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit, assignment := newPublicInputsWitness()
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	var contract bytes.Buffer
+	assert.NoError(ExportVerifyingKey(&contract, vk))
+	assert.Contains(contract.String(), "function verifyProof(")
+
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, witness, solidity.WithProverTargetSolidityVerifier(backend.GROTH16))
+	assert.NoError(err)
+	publicWitness, err := witness.Public()
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness, solidity.WithVerifierTargetSolidityVerifier(backend.GROTH16)))
+
+	calldata, err := Calldata(proof, publicInputs)
+	assert.NoError(err)
+
+	bn254Proof := proof.(*groth16_bn254.Proof)
+	assert.Equal(1, len(bn254Proof.Commitments), "the range checks should be committed to")
+	assert.Equal(selector("verifyProof(uint256[8],uint256[2],uint256[2],uint256[3])"), calldata[:4])
+
+	// MarshalSolidity lays out the same points, with the length of the commitments in between.
+	marshalled := bn254Proof.MarshalSolidity()
+	assert.Equal(marshalled[:8*wordSize], calldata[4:4+8*wordSize])
+	assert.Equal(marshalled[8*wordSize+4:], calldata[4+8*wordSize:4+12*wordSize])
+
+	words, rest := readWords(assert, calldata[4+12*wordSize:], len(publicInputs))
+	assertPublicInputWords(assert, words)
+	assert.Empty(rest)
+}
+
+func TestPlonkCalldata(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit, assignment := newPublicInputsWitness()
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	assert.NoError(err)
+
+	var contract bytes.Buffer
+	assert.NoError(ExportVerifyingKey(&contract, vk))
+	assert.Contains(contract.String(), "function Verify(")
+
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	proof, err := plonk.Prove(ccs, pk, witness, solidity.WithProverTargetSolidityVerifier(backend.PLONK))
+	assert.NoError(err)
+	publicWitness, err := witness.Public()
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, publicWitness, solidity.WithVerifierTargetSolidityVerifier(backend.PLONK)))
+
+	calldata, err := Calldata(proof, publicInputs)
+	assert.NoError(err)
+	assert.Equal(selector("Verify(bytes,uint256[])"), calldata[:4])
+
+	head, _ := readWords(assert, calldata[4:], 2)
+	proofOffset, inputsOffset := int(head[0].Int64()), int(head[1].Int64())
+
+	proofLen, proofTail := readWords(assert, calldata[4+proofOffset:], 1)
+	marshalled := proof.(*plonk_bn254.Proof).MarshalSolidity()
+	assert.Equal(len(marshalled), int(proofLen[0].Int64()))
+	assert.Equal(marshalled, proofTail[:len(marshalled)])
+
+	inputsLen, inputsTail := readWords(assert, calldata[4+inputsOffset:], 1)
+	assert.Equal(len(publicInputs), int(inputsLen[0].Int64()))
+	words, rest := readWords(assert, inputsTail, len(publicInputs))
+	assertPublicInputWords(assert, words)
+	assert.Empty(rest)
+}
+
+func TestCalldataRejectsOtherCurves(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, err := Calldata(groth16.NewProof(ecc.BLS12_381), publicInputs)
+	assert.Error(err)
+}
+
+func TestUniversalCalldata(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	proof := groth16.NewProof(ecc.BN254)
+	circuitDigest := []*big.Int{big.NewInt(7), new(big.Int).Lsh(big.NewInt(1), 200)}
+	calldata, err := UniversalCalldata(proof, publicInputs, circuitDigest)
+	assert.NoError(err)
+	assert.Equal(selector("verifyProof(uint256[8],uint256[5])"), calldata[:4])
+
+	words, rest := readWords(assert, calldata[4+8*wordSize:], len(publicInputs)+len(circuitDigest))
+	assertPublicInputWords(assert, words[:len(publicInputs)])
+	for i := range circuitDigest {
+		assert.Equal(circuitDigest[i].String(), words[len(publicInputs)+i].String(), "circuit digest %d", i)
+	}
+	assert.Empty(rest)
+}
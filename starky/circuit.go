@@ -0,0 +1,45 @@
+package starky
+
+import (
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+// A circuit verifying a proof of Stark, whose public inputs are the circuit's public inputs.
+type VerifierCircuit struct {
+	PublicInputs []gl.Variable `gnark:",public"`
+	Proof        StarkProof
+
+	// This is configuration for the circuit, it is a constant not a variable
+	Stark      Stark       `gnark:"-"`
+	Config     StarkConfig `gnark:"-"`
+	DegreeBits uint64      `gnark:"-"`
+}
+
+// Creates the placeholder VerifierCircuit to compile for the proofs of stark generated with config
+// for traces of 2^degreeBits rows.
+func NewVerifierCircuit(stark Stark, config StarkConfig, degreeBits uint64) *VerifierCircuit {
+	proofWithPis := NewStarkProofWithPublicInputs(stark, &config, degreeBits)
+	return &VerifierCircuit{
+		PublicInputs: proofWithPis.PublicInputs,
+		Proof:        proofWithPis.Proof,
+		Stark:        stark,
+		Config:       config,
+		DegreeBits:   degreeBits,
+	}
+}
+
+// Creates a VerifierCircuit witness assignment from a deserialized proof.
+func NewVerifierCircuitAssignment(proofWithPis StarkProofWithPublicInputs) *VerifierCircuit {
+	return &VerifierCircuit{
+		PublicInputs: proofWithPis.PublicInputs,
+		Proof:        proofWithPis.Proof,
+	}
+}
+
+func (c *VerifierCircuit) Define(api frontend.API) error {
+	verifierChip := NewVerifierChip(api, c.Stark, c.Config, c.DegreeBits)
+	verifierChip.Verify(StarkProofWithPublicInputs{Proof: c.Proof, PublicInputs: c.PublicInputs})
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package starky
+
+import (
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// Mirrors starky's StarkConfig, along with the hasher of the GenericConfig the proofs are generated
+// with.
+type StarkConfig struct {
+	Hasher types.HasherType
+	// The number of challenges the constraints are combined with, each giving its own quotient
+	// polynomials.
+	NumChallenges uint64
+	FriConfig     types.FriConfig
+	// Whether the challenger doesn't observe the FRI config and parameters before the public
+	// inputs, like types.CommonCircuitData.LegacyTranscript for plonky2 proofs. Set it for starky
+	// versions whose transcript starts with the public inputs.
+	LegacyTranscript bool
+}
+
+// Mirrors starky's StarkConfig::standard_fast_config.
+func StandardFastConfig(hasher types.HasherType) StarkConfig {
+	return StarkConfig{
+		Hasher:        hasher,
+		NumChallenges: 2,
+		FriConfig: types.FriConfig{
+			RateBits:        1,
+			CapHeight:       4,
+			ProofOfWorkBits: 16,
+			NumQueryRounds:  84,
+			ReductionStrategy: types.FriReductionStrategy{
+				Kind:              types.ConstantArityBitsReductionStrategy,
+				ConstantArityBits: []uint64{4, 5},
+			},
+		},
+	}
+}
+
+// Returns the parameters of the opening proofs of traces of 2^degreeBits rows. Starky proofs are
+// never hiding.
+func (c *StarkConfig) FriParams(degreeBits uint64) types.FriParams {
+	return c.FriConfig.FriParams(degreeBits, false)
+}
+
+// The number of quotient polynomials of a stark's proofs.
+func (c *StarkConfig) NumQuotientPolys(stark Stark) uint64 {
+	return QuotientDegreeFactor(stark) * c.NumChallenges
+}
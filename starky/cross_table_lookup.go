@@ -0,0 +1,193 @@
+package starky
+
+import (
+	"fmt"
+	"slices"
+
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+// A linear combination of the columns of a row and of the next one, plus a constant, like starky's
+// Column.
+type Column struct {
+	LinearCombination        []ColumnTerm
+	NextRowLinearCombination []ColumnTerm
+	Constant                 uint64
+}
+
+type ColumnTerm struct {
+	Column uint64
+	Coeff  uint64
+}
+
+func (c *Column) eval(glApi *gl.Chip, localValues, nextValues []gl.QuadraticExtensionVariable) gl.QuadraticExtensionVariable {
+	result := gl.NewVariableUint64(c.Constant).ToQuadraticExtension()
+	for _, term := range c.LinearCombination {
+		result = glApi.AddExtension(result, glApi.ScalarMulExtension(localValues[term.Column], gl.NewVariableUint64(term.Coeff)))
+	}
+	for _, term := range c.NextRowLinearCombination {
+		result = glApi.AddExtension(result, glApi.ScalarMulExtension(nextValues[term.Column], gl.NewVariableUint64(term.Coeff)))
+	}
+	return result
+}
+
+// Selects the rows of a table taking part in a cross-table lookup, like starky's Filter: the sum of
+// the products of pairs of columns and of the other columns. The filter of a looked table is the
+// multiplicity of its rows.
+type Filter struct {
+	Products  [][2]Column
+	Constants []Column
+}
+
+// Evaluates the filter, which is 1 for nil filters selecting every row.
+func (f *Filter) eval(glApi *gl.Chip, localValues, nextValues []gl.QuadraticExtensionVariable) gl.QuadraticExtensionVariable {
+	if f == nil {
+		return gl.OneExtension()
+	}
+	result := gl.ZeroExtension()
+	for _, product := range f.Products {
+		result = glApi.MulAddExtension(
+			product[0].eval(glApi, localValues, nextValues),
+			product[1].eval(glApi, localValues, nextValues),
+			result,
+		)
+	}
+	for _, constant := range f.Constants {
+		result = glApi.AddExtension(result, constant.eval(glApi, localValues, nextValues))
+	}
+	return result
+}
+
+// The columns of a table taking part in a cross-table lookup, like starky's TableWithColumns. Table
+// is the index of the table's proof in MultiTableVerifierChip.Verify.
+type TableWithColumns struct {
+	Table   uint64
+	Columns []Column
+	Filter  *Filter
+}
+
+// Checks that the filtered rows of the looking tables' columns are rows of the looked table's
+// columns, like starky's CrossTableLookup.
+type CrossTableLookup struct {
+	LookingTables []TableWithColumns
+	LookedTable   TableWithColumns
+}
+
+// The challenges a CTL Z polynomial combines the columns with, like starky's GrandProductChallenge.
+type GrandProductChallenge struct {
+	Beta  gl.Variable
+	Gamma gl.Variable
+}
+
+// Returns gamma + sum_i terms[i] * beta^i.
+func (c *GrandProductChallenge) combine(glApi *gl.Chip, terms []gl.QuadraticExtensionVariable) gl.QuadraticExtensionVariable {
+	return glApi.AddExtension(
+		glApi.ReduceWithPowers(terms, c.Beta.ToQuadraticExtension()),
+		c.Gamma.ToQuadraticExtension(),
+	)
+}
+
+// A CTL Z polynomial of a table, like starky's CtlZData. Z(g^i) sums filter / combine(columns) over
+// the rows i to n-1 of the lookups of columns and filters, so Z(1) sums them over the whole table.
+type ctlZData struct {
+	columns   [][]Column
+	filters   []*Filter
+	challenge uint64
+}
+
+// Returns the CTL Z polynomials of each of numTables tables. For each lookup and challenge, the
+// looking tables have one Z polynomial for their lookup, in increasing table order, and the looked
+// table has one for its columns. Each table's auxiliary polynomials are its CTL Z polynomials in
+// this order.
+//
+// Tables looking more than once into the same lookup, whose Z polynomials starky combines or splits
+// with helper columns, aren't supported: their layout hasn't been checked against starky's.
+func ctlZDataByTable(numTables int, crossTableLookups []CrossTableLookup, numChallenges uint64) [][]ctlZData {
+	zDataByTable := make([][]ctlZData, numTables)
+	for i, crossTableLookup := range crossTableLookups {
+		lookingTables := lookingTableIndices(crossTableLookup)
+		for _, table := range append(slices.Clone(lookingTables), crossTableLookup.LookedTable.Table) {
+			if table >= uint64(numTables) {
+				panic(fmt.Sprintf("cross-table lookup %d uses table %d of %d", i, table, numTables))
+			}
+		}
+
+		for challenge := uint64(0); challenge < numChallenges; challenge++ {
+			for _, table := range lookingTables {
+				zData := ctlZData{challenge: challenge}
+				for _, lookingTable := range crossTableLookup.LookingTables {
+					if lookingTable.Table == table {
+						zData.columns = append(zData.columns, lookingTable.Columns)
+						zData.filters = append(zData.filters, lookingTable.Filter)
+					}
+				}
+				if len(zData.columns) > 1 {
+					panic(fmt.Sprintf("table %d looks more than once into cross-table lookup %d, which isn't supported", table, i))
+				}
+				zDataByTable[table] = append(zDataByTable[table], zData)
+			}
+
+			lookedTable := crossTableLookup.LookedTable
+			zDataByTable[lookedTable.Table] = append(zDataByTable[lookedTable.Table], ctlZData{
+				columns:   [][]Column{lookedTable.Columns},
+				filters:   []*Filter{lookedTable.Filter},
+				challenge: challenge,
+			})
+		}
+	}
+	return zDataByTable
+}
+
+// Returns the degree of the constraints of CTL Z polynomials, with filters of degree 2: 3 if the
+// table has any.
+func ctlConstraintDegree(zData []ctlZData) uint64 {
+	if len(zData) == 0 {
+		return 0
+	}
+	return 3
+}
+
+// Returns the distinct tables looking into a lookup, in increasing order.
+func lookingTableIndices(crossTableLookup CrossTableLookup) []uint64 {
+	var tables []uint64
+	for _, lookingTable := range crossTableLookup.LookingTables {
+		tables = append(tables, lookingTable.Table)
+	}
+	slices.Sort(tables)
+	return slices.Compact(tables)
+}
+
+// Adds the constraints of a table's CTL Z polynomials, like starky's
+// eval_cross_table_lookup_checks_circuit. With the lookup's combined columns c and its filter f,
+// they are
+//
+//	Z(g^(n-1)) * c = f
+//	(Z(x) - Z(g * x)) * c = f
+func evalCrossTableLookupChecks(
+	glApi *gl.Chip,
+	vars EvaluationFrame,
+	openings StarkOpeningSet,
+	zData []ctlZData,
+	ctlChallenges []GrandProductChallenge,
+	consumer *ConstraintConsumer,
+) {
+	for i, data := range zData {
+		challenge := ctlChallenges[data.challenge]
+		localZ := openings.AuxiliaryPolys[i]
+		nextZ := openings.AuxiliaryPolysNext[i]
+
+		columns := data.columns[0]
+		evals := make([]gl.QuadraticExtensionVariable, len(columns))
+		for k := range columns {
+			evals[k] = columns[k].eval(glApi, vars.LocalValues, vars.NextValues)
+		}
+		combined := challenge.combine(glApi, evals)
+		filter := data.filters[0].eval(glApi, vars.LocalValues, vars.NextValues)
+
+		consumer.ConstraintLastRow(glApi.SubExtension(glApi.MulExtension(combined, localZ), filter))
+		consumer.ConstraintTransition(glApi.SubExtension(
+			glApi.MulExtension(combined, glApi.SubExtension(localZ, nextZ)),
+			filter,
+		))
+	}
+}
@@ -0,0 +1,330 @@
+package starky
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// A table without constraints of its own, whose columns are only constrained by lookups.
+type lookupStark struct {
+	constraintDegree uint64
+}
+
+func (s lookupStark) Columns() uint64          { return 2 }
+func (s lookupStark) PublicInputs() uint64     { return 0 }
+func (s lookupStark) ConstraintDegree() uint64 { return s.constraintDegree }
+
+func (s lookupStark) EvalConstraints(api frontend.API, glApi *gl.Chip, vars EvaluationFrame, consumer *ConstraintConsumer) {
+}
+
+func linearColumn(column uint64, constant uint64) Column {
+	return Column{LinearCombination: []ColumnTerm{{Column: column, Coeff: 1}}, Constant: constant}
+}
+
+// Table 0 looks up its first column plus 2 on the rows its second column selects, with a filter of
+// degree 2. Table 1 holds the looked values in its first column and their multiplicities in its
+// second one.
+var testCrossTableLookups = []CrossTableLookup{{
+	LookingTables: []TableWithColumns{
+		{Table: 0, Columns: []Column{linearColumn(0, 2)}, Filter: &Filter{Products: [][2]Column{{linearColumn(1, 0), linearColumn(1, 0)}}}},
+	},
+	LookedTable: TableWithColumns{Table: 1, Columns: []Column{linearColumn(0, 0)}, Filter: &Filter{Constants: []Column{linearColumn(1, 0)}}},
+}}
+
+var testLookupStarks = []Stark{lookupStark{constraintDegree: 3}, lookupStark{constraintDegree: 3}}
+
+// The values a native table lookup combines, and their filter, on each row.
+type nativeLookup func(row []gl.QuadraticExtension) ([]gl.QuadraticExtension, gl.QuadraticExtension)
+
+// A trace whose columns are extended with the CTL Z polynomials of its lookups.
+type ctlTrace struct {
+	domain  []gl.QuadraticExtension
+	columns [][]gl.QuadraticExtension
+	lookups [][]nativeLookup // The lookups of each Z polynomial.
+	zs      [][]gl.QuadraticExtension
+}
+
+func newCtlTrace(degreeBits uint64, columns [][]uint64, lookups [][]nativeLookup, challenges [][2]goldilocks.Element) ctlTrace {
+	var trace ctlTrace
+	for _, h := range gl.TwoAdicSubgroup(degreeBits) {
+		trace.domain = append(trace.domain, gl.ToQuadraticExtension(h))
+	}
+	for _, column := range columns {
+		values := make([]gl.QuadraticExtension, len(column))
+		for i, value := range column {
+			values[i] = gl.NewQuadraticExtensionUint64(value, 0)
+		}
+		trace.columns = append(trace.columns, values)
+	}
+
+	for _, challenge := range challenges {
+		for _, zLookups := range lookups {
+			trace.lookups = append(trace.lookups, zLookups)
+			z := make([]gl.QuadraticExtension, len(trace.domain))
+			sum := gl.ZeroExtensionNative()
+			for i := len(trace.domain) - 1; i >= 0; i-- {
+				row := []gl.QuadraticExtension{trace.columns[0][i], trace.columns[1][i]}
+				for _, lookup := range zLookups {
+					values, filter := lookup(row)
+					sum = sum.Add(filter.Div(combineNative(challenge, values)))
+				}
+				z[i] = sum
+			}
+			trace.zs = append(trace.zs, z)
+		}
+	}
+	return trace
+}
+
+func combineNative(challenge [2]goldilocks.Element, terms []gl.QuadraticExtension) gl.QuadraticExtension {
+	return gl.ReduceWithPowersNative(terms, gl.ToQuadraticExtension(challenge[0])).Add(gl.ToQuadraticExtension(challenge[1]))
+}
+
+// Returns the trace columns and the Z polynomials at x.
+func (t *ctlTrace) row(x gl.QuadraticExtension) ([]gl.QuadraticExtension, []gl.QuadraticExtension) {
+	var columns, zs []gl.QuadraticExtension
+	for _, column := range t.columns {
+		columns = append(columns, interpolateNative(t.domain, column, x))
+	}
+	for _, z := range t.zs {
+		zs = append(zs, interpolateNative(t.domain, z, x))
+	}
+	return columns, zs
+}
+
+// Evaluates the CTL constraints combined with alpha at x, see fibonacciTrace.combinedConstraints.
+func (t *ctlTrace) combinedConstraints(x gl.QuadraticExtension, alpha goldilocks.Element, challenges [][2]goldilocks.Element) gl.QuadraticExtension {
+	n := len(t.domain)
+	local, localZs := t.row(x)
+	_, nextZs := t.row(x.Mul(t.domain[1]))
+	lLast := lagrangeBasisNative(t.domain, n-1, x)
+	zLast := x.Sub(t.domain[n-1])
+
+	acc := gl.ZeroExtensionNative()
+	for i, zLookups := range t.lookups {
+		challenge := challenges[i/(len(t.lookups)/len(challenges))]
+		sum := gl.ZeroExtensionNative()
+		denominator := gl.OneExtensionNative()
+		for _, lookup := range zLookups {
+			values, filter := lookup(local)
+			combined := combineNative(challenge, values)
+			sum = sum.Mul(combined).Add(filter.Mul(denominator))
+			denominator = denominator.Mul(combined)
+		}
+		acc = acc.ScalarMul(alpha).Add(denominator.Mul(localZs[i]).Sub(sum).Mul(lLast))
+		acc = acc.ScalarMul(alpha).Add(denominator.Mul(localZs[i].Sub(nextZs[i])).Sub(sum).Mul(zLast))
+	}
+	return acc
+}
+
+// Evaluates the quotient of the combined constraints at x, from its values on a coset of four times
+// the trace domain's size, see fibonacciTrace.quotient.
+func (t *ctlTrace) quotient(x gl.QuadraticExtension, alpha goldilocks.Element, challenges [][2]goldilocks.Element, degreeBits uint64) gl.QuadraticExtension {
+	n := uint64(len(t.domain))
+	shift := gl.ToQuadraticExtension(gl.MULTIPLICATIVE_GROUP_GENERATOR)
+	var coset, values []gl.QuadraticExtension
+	for _, h := range gl.TwoAdicSubgroup(degreeBits + 2) {
+		point := shift.Mul(gl.ToQuadraticExtension(h))
+		zH := point.Exp(n).Sub(gl.OneExtensionNative())
+		coset = append(coset, point)
+		values = append(values, t.combinedConstraints(point, alpha, challenges).Div(zH))
+	}
+	return interpolateNative(coset, values, x)
+}
+
+// The openings of a table at zeta, where the quotient polynomials are t_0 = t and t_i = 0 for the
+// others.
+func (t *ctlTrace) openings(stark Stark, zeta gl.QuadraticExtension, alphas []goldilocks.Element, challenges [][2]goldilocks.Element, degreeBits uint64) StarkOpeningSet {
+	local, localZs := t.row(zeta)
+	next, nextZs := t.row(zeta.Mul(t.domain[1]))
+	openings := StarkOpeningSet{
+		LocalValues:        toExtensionVariables(local),
+		NextValues:         toExtensionVariables(next),
+		AuxiliaryPolys:     toExtensionVariables(localZs),
+		AuxiliaryPolysNext: toExtensionVariables(nextZs),
+	}
+	for _, z := range t.zs {
+		openings.CtlZsFirst = append(openings.CtlZsFirst, gl.NewVariableUint64(z[0][0].Uint64()))
+	}
+	for _, alpha := range alphas {
+		openings.QuotientPolys = append(openings.QuotientPolys, toExtensionVariable(t.quotient(zeta, alpha, challenges, degreeBits)))
+		for range QuotientDegreeFactor(stark) - 1 {
+			openings.QuotientPolys = append(openings.QuotientPolys, gl.ZeroExtension())
+		}
+	}
+	return openings
+}
+
+type testCrossTableLookupCircuit struct {
+	StarkAlphas   []gl.Variable
+	StarkZeta     gl.QuadraticExtensionVariable
+	CtlChallenges []GrandProductChallenge
+	Openings      []StarkOpeningSet
+
+	DegreeBits uint64 `gnark:"-"`
+}
+
+func (c *testCrossTableLookupCircuit) Define(api frontend.API) error {
+	config := StandardFastConfig(types.PoseidonGoldilocksHash)
+	verifierChip := NewMultiTableVerifierChip(api, testLookupStarks, []uint64{c.DegreeBits, c.DegreeBits}, config, testCrossTableLookups)
+
+	proofs := make([]StarkProofWithPublicInputs, len(c.Openings))
+	for i, table := range verifierChip.tables {
+		proofs[i].Proof.Openings = c.Openings[i]
+		challenges := StarkProofChallenges{StarkAlphas: c.StarkAlphas, StarkZeta: c.StarkZeta}
+		table.verifyVanishingPolys(challenges, c.Openings[i], nil, c.CtlChallenges)
+	}
+	verifierChip.verifyCrossTableLookups(proofs)
+	return nil
+}
+
+func TestCrossTableLookups(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	degreeBits := uint64(2)
+	zeta := gl.NewQuadraticExtensionUint64(0x1234567890abcdef, 0xfedcba0987654321)
+	alphas := []goldilocks.Element{goldilocks.NewElement(0x0123456789abcdef), goldilocks.NewElement(0xabcdef0123456789)}
+	challenges := [][2]goldilocks.Element{
+		{goldilocks.NewElement(0x1111111122222222), goldilocks.NewElement(0x3333333344444444)},
+		{goldilocks.NewElement(0x5555555566666666), goldilocks.NewElement(0x7777777788888888)},
+	}
+
+	looking := []nativeLookup{
+		func(row []gl.QuadraticExtension) ([]gl.QuadraticExtension, gl.QuadraticExtension) {
+			return []gl.QuadraticExtension{row[0].Add(gl.NewQuadraticExtensionUint64(2, 0))}, row[1].Mul(row[1])
+		},
+	}
+	looked := []nativeLookup{
+		func(row []gl.QuadraticExtension) ([]gl.QuadraticExtension, gl.QuadraticExtension) {
+			return row[:1], row[1]
+		},
+	}
+
+	newAssignment := func(multiplicities []uint64) *testCrossTableLookupCircuit {
+		// Table 0 looks up 7, 9 and 7.
+		traces := []ctlTrace{
+			newCtlTrace(degreeBits, [][]uint64{{5, 7, 5, 9}, {1, 1, 1, 0}}, [][]nativeLookup{looking}, challenges),
+			newCtlTrace(degreeBits, [][]uint64{{5, 7, 9, 11}, multiplicities}, [][]nativeLookup{looked}, challenges),
+		}
+
+		assignment := testCrossTableLookupCircuit{StarkZeta: toExtensionVariable(zeta)}
+		for _, alpha := range alphas {
+			assignment.StarkAlphas = append(assignment.StarkAlphas, gl.NewVariableUint64(alpha.Uint64()))
+		}
+		for _, challenge := range challenges {
+			assignment.CtlChallenges = append(assignment.CtlChallenges, GrandProductChallenge{
+				Beta:  gl.NewVariableUint64(challenge[0].Uint64()),
+				Gamma: gl.NewVariableUint64(challenge[1].Uint64()),
+			})
+		}
+		for i, trace := range traces {
+			assignment.Openings = append(assignment.Openings, trace.openings(testLookupStarks[i], zeta, alphas, challenges, degreeBits))
+		}
+		return &assignment
+	}
+
+	circuit := testCrossTableLookupCircuit{
+		StarkAlphas:   make([]gl.Variable, len(alphas)),
+		CtlChallenges: make([]GrandProductChallenge, len(challenges)),
+		DegreeBits:    degreeBits,
+	}
+	for _, stark := range testLookupStarks {
+		circuit.Openings = append(circuit.Openings, StarkOpeningSet{
+			LocalValues:        make([]gl.QuadraticExtensionVariable, 2),
+			NextValues:         make([]gl.QuadraticExtensionVariable, 2),
+			AuxiliaryPolys:     make([]gl.QuadraticExtensionVariable, 2),
+			AuxiliaryPolysNext: make([]gl.QuadraticExtensionVariable, 2),
+			CtlZsFirst:         make([]gl.Variable, 2),
+			QuotientPolys:      make([]gl.QuadraticExtensionVariable, QuotientDegreeFactor(stark)*2),
+		})
+	}
+
+	assert.NoError(test.IsSolved(&circuit, newAssignment([]uint64{0, 2, 1, 0}), ecc.BN254.ScalarField()))
+
+	// The looked table's Z polynomials are consistent with its multiplicities, but the lookups don't
+	// balance.
+	assert.Error(test.IsSolved(&circuit, newAssignment([]uint64{0, 2, 1, 1}), ecc.BN254.ScalarField()))
+}
+
+func TestCtlZDataByTable(t *testing.T) {
+	zDataByTable := ctlZDataByTable(2, testCrossTableLookups, 2)
+	if len(zDataByTable[0]) != 2 || len(zDataByTable[1]) != 2 {
+		t.Fatalf("expected two CTL Z polynomials per table, got %d and %d", len(zDataByTable[0]), len(zDataByTable[1]))
+	}
+	if len(zDataByTable[0][0].columns) != 1 || zDataByTable[0][1].challenge != 1 {
+		t.Fatal("expected table 0's lookup to have one Z polynomial per challenge")
+	}
+
+	// Starky's layout of the Z polynomials of a table looking more than once into a lookup isn't
+	// supported.
+	assert := test.NewAssert(t)
+	twoLookups := []CrossTableLookup{{
+		LookingTables: []TableWithColumns{{Table: 0}, {Table: 0}},
+		LookedTable:   TableWithColumns{Table: 1},
+	}}
+	assert.Panics(func() { ctlZDataByTable(2, twoLookups, 2) })
+}
+
+func TestMultiTableVerifierCircuitCompiles(t *testing.T) {
+	config := StandardFastConfig(types.PoseidonGoldilocksHash)
+	config.FriConfig.NumQueryRounds = 2
+	circuit := NewMultiTableVerifierCircuit(testLookupStarks, []uint64{8, 6}, config, testCrossTableLookups)
+	if len(circuit.Proofs[1].Openings.CtlZsFirst) != 2 {
+		t.Fatalf("expected 2 CTL Z polynomials, got %d", len(circuit.Proofs[1].Openings.CtlZsFirst))
+	}
+
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The proofs of the tables of testLookupStarks connected by testCrossTableLookups, generated with
+// StandardFastConfig by a multi-table prover built on starky and serialized with serde_json.
+const starkyCtlProofs = "../testdata/starky/ctl"
+
+// Verifies the tables' proofs generated by starky, checking the shared transcript and the CTL Z
+// polynomials against starky's.
+func TestMultiTableVerifierCircuitFile(t *testing.T) {
+	if _, err := os.Stat(filepath.Join(starkyCtlProofs, "table_0.json")); errors.Is(err, os.ErrNotExist) {
+		t.Skipf("no starky proofs in %s", starkyCtlProofs)
+	}
+	assert := test.NewAssert(t)
+
+	config := StandardFastConfig(types.PoseidonGoldilocksHash)
+	var raws []StarkProofWithPublicInputsRaw
+	var degreeBits []uint64
+	for i := range testLookupStarks {
+		raw, err := ReadStarkProofWithPublicInputs(filepath.Join(starkyCtlProofs, fmt.Sprintf("table_%d.json", i)))
+		assert.NoError(err)
+		raws = append(raws, raw)
+		degreeBits = append(degreeBits, proofDegreeBits(raw, &config))
+	}
+	newAssignment := func() *MultiTableVerifierCircuit {
+		var proofs []StarkProofWithPublicInputs
+		for _, raw := range raws {
+			proofWithPis, err := DeserializeStarkProofWithPublicInputs(raw)
+			assert.NoError(err)
+			proofs = append(proofs, proofWithPis)
+		}
+		return NewMultiTableVerifierCircuitAssignment(proofs)
+	}
+
+	circuit := NewMultiTableVerifierCircuit(testLookupStarks, degreeBits, config, testCrossTableLookups)
+	assert.NoError(test.IsSolved(circuit, newAssignment(), ecc.BN254.ScalarField()))
+
+	// The lookups don't balance with a wrong sum of the looked table's rows.
+	raws[1].Proof.Openings.CtlZsFirst[0]++
+	assert.Error(test.IsSolved(circuit, newAssignment(), ecc.BN254.ScalarField()))
+}
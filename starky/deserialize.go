@@ -0,0 +1,69 @@
+package starky
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// Reads a starky StarkProofWithPublicInputs serialized with serde_json.
+func ReadStarkProofWithPublicInputs(path string) (StarkProofWithPublicInputsRaw, error) {
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		return StarkProofWithPublicInputsRaw{}, err
+	}
+
+	var raw StarkProofWithPublicInputsRaw
+	if err := json.Unmarshal(rawBytes, &raw); err != nil {
+		return StarkProofWithPublicInputsRaw{}, err
+	}
+	return raw, nil
+}
+
+func DeserializeStarkProofWithPublicInputs(raw StarkProofWithPublicInputsRaw) (StarkProofWithPublicInputs, error) {
+	openings := raw.Proof.Openings
+	if raw.Proof.QuotientPolysCap == nil || openings.QuotientPolys == nil {
+		return StarkProofWithPublicInputs{}, errors.New("starky proofs without quotient polynomials are not supported")
+	}
+	if (raw.Proof.AuxiliaryPolysCap == nil) != (openings.CtlZsFirst == nil) {
+		return StarkProofWithPublicInputs{}, errors.New("starky proofs with auxiliary polynomials are only supported for cross-table lookups")
+	}
+
+	var proofWithPis StarkProofWithPublicInputs
+	proofWithPis.Proof.TraceCap = variables.DeserializeMerkleCap(raw.Proof.TraceCap)
+	if raw.Proof.AuxiliaryPolysCap != nil {
+		proofWithPis.Proof.AuxiliaryPolysCap = variables.DeserializeMerkleCap(raw.Proof.AuxiliaryPolysCap)
+	}
+	proofWithPis.Proof.QuotientPolysCap = variables.DeserializeMerkleCap(raw.Proof.QuotientPolysCap)
+	proofWithPis.Proof.Openings = StarkOpeningSet{
+		LocalValues:        gl.Uint64ArrayToQuadraticExtensionArray(openings.LocalValues),
+		NextValues:         gl.Uint64ArrayToQuadraticExtensionArray(openings.NextValues),
+		AuxiliaryPolys:     gl.Uint64ArrayToQuadraticExtensionArray(openings.AuxiliaryPolys),
+		AuxiliaryPolysNext: gl.Uint64ArrayToQuadraticExtensionArray(openings.AuxiliaryPolysNext),
+		CtlZsFirst:         gl.Uint64ArrayToVariableArray(openings.CtlZsFirst),
+		QuotientPolys:      gl.Uint64ArrayToQuadraticExtensionArray(openings.QuotientPolys),
+	}
+	proofWithPis.Proof.OpeningProof = variables.DeserializeFriProof(struct {
+		CommitPhaseMerkleCaps [][]types.HashOutRaw
+		QueryRoundProofs      []struct {
+			InitialTreesProof struct {
+				EvalsProofs []types.EvalProofRaw
+			}
+			Steps []struct {
+				Evals       [][]uint64
+				MerkleProof struct {
+					Siblings []types.HashOutRaw
+				}
+			}
+		}
+		FinalPoly  struct{ Coeffs [][]uint64 }
+		PowWitness uint64
+	}(raw.Proof.OpeningProof))
+	proofWithPis.PublicInputs = gl.Uint64ArrayToVariableArray(raw.PublicInputs)
+
+	return proofWithPis, nil
+}
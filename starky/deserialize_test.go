@@ -0,0 +1,58 @@
+package starky
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark/test"
+)
+
+func TestDeserializeStarkProofWithPublicInputs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	const proofJson = `{
+		"proof": {
+			"trace_cap": [{"elements": [1, 2, 3, 4]}],
+			"auxiliary_polys_cap": null,
+			"quotient_polys_cap": [{"elements": [5, 6, 7, 8]}],
+			"openings": {
+				"local_values": [[1, 0], [2, 0]],
+				"next_values": [[2, 0], [3, 0]],
+				"auxiliary_polys": null,
+				"auxiliary_polys_next": null,
+				"ctl_zs_first": null,
+				"quotient_polys": [[4, 5], [6, 7]]
+			},
+			"opening_proof": {
+				"commit_phase_merkle_caps": [],
+				"query_round_proofs": [],
+				"final_poly": {"coeffs": [[1, 2]]},
+				"pow_witness": 9
+			}
+		},
+		"public_inputs": [1, 2, 3]
+	}`
+
+	var raw StarkProofWithPublicInputsRaw
+	assert.NoError(json.Unmarshal([]byte(proofJson), &raw))
+	proofWithPis, err := DeserializeStarkProofWithPublicInputs(raw)
+	assert.NoError(err)
+	assert.Equal(3, len(proofWithPis.PublicInputs))
+	assert.Equal(2, len(proofWithPis.Proof.Openings.QuotientPolys))
+	assert.Equal(1, len(proofWithPis.Proof.OpeningProof.FinalPoly.Coeffs))
+
+	// The auxiliary polynomials are the CTL Z polynomials, and can't be the lookups' of the stark.
+	auxiliary := raw
+	auxiliary.Proof.AuxiliaryPolysCap = raw.Proof.TraceCap
+	_, err = DeserializeStarkProofWithPublicInputs(auxiliary)
+	assert.ErrorContains(err, "only supported for cross-table lookups")
+
+	ctl := auxiliary
+	ctl.Proof.Openings.AuxiliaryPolys = [][]uint64{{1, 2}}
+	ctl.Proof.Openings.AuxiliaryPolysNext = [][]uint64{{3, 4}}
+	ctl.Proof.Openings.CtlZsFirst = []uint64{5}
+	proofWithPis, err = DeserializeStarkProofWithPublicInputs(ctl)
+	assert.NoError(err)
+	assert.Equal(1, len(proofWithPis.Proof.AuxiliaryPolysCap))
+	assert.Equal(1, len(proofWithPis.Proof.Openings.CtlZsFirst))
+}
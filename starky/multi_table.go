@@ -0,0 +1,211 @@
+package starky
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/elliottech/gnark-plonky2-verifier/challenger"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/hasher"
+	"github.com/elliottech/gnark-plonky2-verifier/profiler"
+)
+
+// Verifies the proofs of several STARK tables connected by cross-table lookups, sharing one
+// transcript like the multi-table provers built on starky. Each table's proof opens its CTL Z
+// polynomials as auxiliary polynomials, see ctlZDataByTable for their order, and the lookups
+// balance when the looking tables' Z polynomials sum to the looked table's on the first row.
+type MultiTableVerifierChip struct {
+	api               frontend.API       `gnark:"-"`
+	hasher            hasher.Hasher      `gnark:"-"`
+	config            StarkConfig        `gnark:"-"`
+	crossTableLookups []CrossTableLookup `gnark:"-"`
+	tables            []*VerifierChip    `gnark:"-"`
+}
+
+// Creates the chip verifying the proofs of starks generated with config for traces of
+// 2^degreeBits[i] rows, with the tables indexed in the order of starks.
+func NewMultiTableVerifierChip(
+	api frontend.API,
+	starks []Stark,
+	degreeBits []uint64,
+	config StarkConfig,
+	crossTableLookups []CrossTableLookup,
+) *MultiTableVerifierChip {
+	if len(starks) != len(degreeBits) {
+		panic("number of starks doesn't match the number of trace degrees")
+	}
+
+	zDataByTable := ctlZDataByTable(len(starks), crossTableLookups, config.NumChallenges)
+	tables := make([]*VerifierChip, len(starks))
+	for i, stark := range starks {
+		if degree := ctlConstraintDegree(zDataByTable[i]); stark.ConstraintDegree() < degree {
+			panic(fmt.Sprintf("table %d has constraint degree %d, but its cross-table lookups need %d", i, stark.ConstraintDegree(), degree))
+		}
+		tables[i] = NewVerifierChip(api, stark, config, degreeBits[i])
+		tables[i].ctlZData = zDataByTable[i]
+	}
+
+	return &MultiTableVerifierChip{
+		api:               api,
+		hasher:            hasher.New(api, config.Hasher),
+		config:            config,
+		crossTableLookups: crossTableLookups,
+		tables:            tables,
+	}
+}
+
+// Replays the shared transcript. The challenger observes each table's FRI parameters and public
+// inputs, then each trace cap, before drawing the CTL challenges. Then each table's challenges are
+// drawn in turn from the compacted challenger, like starky's StarkProof::get_challenges with the CTL
+// challenges and without observing the trace cap again.
+func (c *MultiTableVerifierChip) GetChallenges(proofs []StarkProofWithPublicInputs) ([]GrandProductChallenge, []StarkProofChallenges) {
+	challenger := challenger.NewChip(c.api, c.hasher)
+
+	for i, table := range c.tables {
+		table.observeFriParams(challenger)
+		challenger.ObserveElements(proofs[i].PublicInputs)
+	}
+	for _, proof := range proofs {
+		challenger.ObserveCap(proof.Proof.TraceCap)
+	}
+
+	ctlChallenges := make([]GrandProductChallenge, c.config.NumChallenges)
+	for i := range ctlChallenges {
+		ctlChallenges[i].Beta = challenger.GetChallenge()
+		ctlChallenges[i].Gamma = challenger.GetChallenge()
+	}
+
+	starkChallenges := make([]StarkProofChallenges, len(c.tables))
+	for i, table := range c.tables {
+		challenger.Compact()
+		starkChallenges[i] = table.getProofChallenges(challenger, proofs[i].Proof)
+	}
+
+	return ctlChallenges, starkChallenges
+}
+
+// Checks that the lookups balance, like starky's verify_cross_table_lookups: for each lookup and
+// challenge, the looking tables' CTL Z polynomials at 1 sum to the looked table's.
+func (c *MultiTableVerifierChip) verifyCrossTableLookups(proofs []StarkProofWithPublicInputs) {
+	glApi := gl.New(c.api)
+
+	// The next CTL Z polynomial of each table, in the order of ctlZDataByTable.
+	nextZ := make([]int, len(proofs))
+	ctlZFirst := func(table uint64) gl.Variable {
+		z := proofs[table].Proof.Openings.CtlZsFirst[nextZ[table]]
+		nextZ[table]++
+		return z
+	}
+
+	for _, crossTableLookup := range c.crossTableLookups {
+		lookingTables := lookingTableIndices(crossTableLookup)
+		for challenge := uint64(0); challenge < c.config.NumChallenges; challenge++ {
+			lookingZsSum := gl.Zero()
+			for _, table := range lookingTables {
+				lookingZsSum = glApi.Add(lookingZsSum, ctlZFirst(table))
+			}
+			lookedZ := ctlZFirst(crossTableLookup.LookedTable.Table)
+			glApi.AssertIsEqual(lookingZsSum, lookedZ)
+		}
+	}
+}
+
+func (c *MultiTableVerifierChip) Verify(proofs []StarkProofWithPublicInputs) {
+	if len(proofs) != len(c.tables) {
+		panic("number of proofs doesn't match the number of tables")
+	}
+	for i, table := range c.tables {
+		table.validateProofShape(&proofs[i])
+	}
+
+	stop := profiler.Start("range checks")
+	for i, table := range c.tables {
+		table.rangeCheckProof(proofs[i])
+	}
+	stop()
+
+	stop = profiler.Start("challenger")
+	ctlChallenges, starkChallenges := c.GetChallenges(proofs)
+	stop()
+
+	stop = profiler.Start("cross-table lookups")
+	c.verifyCrossTableLookups(proofs)
+	stop()
+
+	for i, table := range c.tables {
+		stop = profiler.Start(fmt.Sprintf("table %d", i))
+		table.verifyProofWithChallenges(proofs[i], starkChallenges[i], ctlChallenges)
+		stop()
+	}
+}
+
+// Allocates the placeholder proofs of the tables of a MultiTableVerifierChip, see
+// NewStarkProofWithPublicInputs.
+func NewMultiTableProofsWithPublicInputs(
+	starks []Stark,
+	degreeBits []uint64,
+	config *StarkConfig,
+	crossTableLookups []CrossTableLookup,
+) []StarkProofWithPublicInputs {
+	zDataByTable := ctlZDataByTable(len(starks), crossTableLookups, config.NumChallenges)
+	proofs := make([]StarkProofWithPublicInputs, len(starks))
+	for i, stark := range starks {
+		proofs[i] = newStarkProofWithPublicInputs(stark, config, degreeBits[i], uint64(len(zDataByTable[i])))
+	}
+	return proofs
+}
+
+// A circuit verifying the proofs of the tables of a MultiTableVerifierChip, whose public inputs are
+// the tables' public inputs.
+type MultiTableVerifierCircuit struct {
+	PublicInputs [][]gl.Variable `gnark:",public"`
+	Proofs       []StarkProof
+
+	// This is configuration for the circuit, it is a constant not a variable
+	Starks            []Stark            `gnark:"-"`
+	DegreeBits        []uint64           `gnark:"-"`
+	Config            StarkConfig        `gnark:"-"`
+	CrossTableLookups []CrossTableLookup `gnark:"-"`
+}
+
+// Creates the placeholder MultiTableVerifierCircuit to compile, see NewMultiTableVerifierChip.
+func NewMultiTableVerifierCircuit(
+	starks []Stark,
+	degreeBits []uint64,
+	config StarkConfig,
+	crossTableLookups []CrossTableLookup,
+) *MultiTableVerifierCircuit {
+	circuit := &MultiTableVerifierCircuit{
+		Starks:            starks,
+		DegreeBits:        degreeBits,
+		Config:            config,
+		CrossTableLookups: crossTableLookups,
+	}
+	for _, proofWithPis := range NewMultiTableProofsWithPublicInputs(starks, degreeBits, &config, crossTableLookups) {
+		circuit.PublicInputs = append(circuit.PublicInputs, proofWithPis.PublicInputs)
+		circuit.Proofs = append(circuit.Proofs, proofWithPis.Proof)
+	}
+	return circuit
+}
+
+// Creates a MultiTableVerifierCircuit witness assignment from the deserialized proofs of its tables.
+func NewMultiTableVerifierCircuitAssignment(proofs []StarkProofWithPublicInputs) *MultiTableVerifierCircuit {
+	var circuit MultiTableVerifierCircuit
+	for _, proofWithPis := range proofs {
+		circuit.PublicInputs = append(circuit.PublicInputs, proofWithPis.PublicInputs)
+		circuit.Proofs = append(circuit.Proofs, proofWithPis.Proof)
+	}
+	return &circuit
+}
+
+func (c *MultiTableVerifierCircuit) Define(api frontend.API) error {
+	proofs := make([]StarkProofWithPublicInputs, len(c.Proofs))
+	for i := range proofs {
+		proofs[i] = StarkProofWithPublicInputs{Proof: c.Proofs[i], PublicInputs: c.PublicInputs[i]}
+	}
+
+	verifierChip := NewMultiTableVerifierChip(api, c.Starks, c.DegreeBits, c.Config, c.CrossTableLookups)
+	verifierChip.Verify(proofs)
+
+	return nil
+}
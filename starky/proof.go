@@ -0,0 +1,142 @@
+package starky
+
+import (
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+type StarkOpeningSet struct {
+	LocalValues []gl.QuadraticExtensionVariable // Length = Stark.Columns()
+	NextValues  []gl.QuadraticExtensionVariable // Length = Stark.Columns()
+	// The CTL Z polynomials at zeta and zeta * g, and at 1 (the first row), see
+	// MultiTableVerifierChip. They are empty for proofs without cross-table lookups.
+	AuxiliaryPolys     []gl.QuadraticExtensionVariable
+	AuxiliaryPolysNext []gl.QuadraticExtensionVariable
+	CtlZsFirst         []gl.Variable
+	QuotientPolys      []gl.QuadraticExtensionVariable // Length = StarkConfig.NumQuotientPolys(stark)
+}
+
+type StarkProof struct {
+	TraceCap variables.FriMerkleCap
+	// Nil for proofs without cross-table lookups.
+	AuxiliaryPolysCap variables.FriMerkleCap
+	QuotientPolysCap  variables.FriMerkleCap
+	Openings          StarkOpeningSet
+	OpeningProof      variables.FriProof
+}
+
+type StarkProofWithPublicInputs struct {
+	Proof        StarkProof
+	PublicInputs []gl.Variable // Length = Stark.PublicInputs()
+}
+
+type StarkProofChallenges struct {
+	StarkAlphas   []gl.Variable
+	StarkZeta     gl.QuadraticExtensionVariable
+	FriChallenges variables.FriChallenges
+}
+
+// Allocates a StarkProofWithPublicInputs whose shape matches the proofs of stark generated with
+// config for traces of 2^degreeBits rows. All of its variables are left unassigned, so it can be
+// used as the placeholder when compiling a circuit.
+func NewStarkProofWithPublicInputs(stark Stark, config *StarkConfig, degreeBits uint64) StarkProofWithPublicInputs {
+	return newStarkProofWithPublicInputs(stark, config, degreeBits, 0)
+}
+
+// Allocates a proof whose auxiliary polynomials are numCtlZs CTL Z polynomials.
+func newStarkProofWithPublicInputs(stark Stark, config *StarkConfig, degreeBits uint64, numCtlZs uint64) StarkProofWithPublicInputs {
+	friParams := config.FriParams(degreeBits)
+	numQuotientPolys := config.NumQuotientPolys(stark)
+	capHeight := config.FriConfig.CapHeight
+
+	proof := StarkProof{
+		TraceCap:         variables.NewFriMerkleCap(config.Hasher, capHeight),
+		QuotientPolysCap: variables.NewFriMerkleCap(config.Hasher, capHeight),
+		Openings: StarkOpeningSet{
+			LocalValues:   make([]gl.QuadraticExtensionVariable, stark.Columns()),
+			NextValues:    make([]gl.QuadraticExtensionVariable, stark.Columns()),
+			QuotientPolys: make([]gl.QuadraticExtensionVariable, numQuotientPolys),
+		},
+	}
+
+	oracleLeafSizes := []uint64{stark.Columns(), numQuotientPolys}
+	if numCtlZs != 0 {
+		proof.AuxiliaryPolysCap = variables.NewFriMerkleCap(config.Hasher, capHeight)
+		proof.Openings.AuxiliaryPolys = make([]gl.QuadraticExtensionVariable, numCtlZs)
+		proof.Openings.AuxiliaryPolysNext = make([]gl.QuadraticExtensionVariable, numCtlZs)
+		proof.Openings.CtlZsFirst = make([]gl.Variable, numCtlZs)
+		oracleLeafSizes = []uint64{stark.Columns(), numCtlZs, numQuotientPolys}
+	}
+	proof.OpeningProof = variables.NewFriProofWithOracles(config.Hasher, &friParams, oracleLeafSizes)
+
+	return StarkProofWithPublicInputs{
+		Proof:        proof,
+		PublicInputs: make([]gl.Variable, stark.PublicInputs()),
+	}
+}
+
+// Checks that a proof has the shape of the proofs of stark generated with config for traces of
+// 2^degreeBits rows, with numCtlZs CTL Z polynomials, panicking otherwise like the circuit shape
+// checks of the fri package.
+func validateStarkProofShape(proofWithPis *StarkProofWithPublicInputs, stark Stark, config *StarkConfig, degreeBits uint64, numCtlZs uint64) {
+	expected := newStarkProofWithPublicInputs(stark, config, degreeBits, numCtlZs)
+	proof := &proofWithPis.Proof
+	if len(proofWithPis.PublicInputs) != len(expected.PublicInputs) {
+		panic("number of public inputs doesn't match the stark")
+	}
+	if len(proof.TraceCap) != len(expected.Proof.TraceCap) || len(proof.QuotientPolysCap) != len(expected.Proof.QuotientPolysCap) {
+		panic("merkle cap length doesn't match the cap height")
+	}
+	if len(proof.Openings.LocalValues) != len(expected.Proof.Openings.LocalValues) ||
+		len(proof.Openings.NextValues) != len(expected.Proof.Openings.NextValues) {
+		panic("number of opened trace values doesn't match the stark's columns")
+	}
+	if len(proof.AuxiliaryPolysCap) != len(expected.Proof.AuxiliaryPolysCap) ||
+		len(proof.Openings.AuxiliaryPolys) != len(expected.Proof.Openings.AuxiliaryPolys) ||
+		len(proof.Openings.AuxiliaryPolysNext) != len(expected.Proof.Openings.AuxiliaryPolysNext) ||
+		len(proof.Openings.CtlZsFirst) != len(expected.Proof.Openings.CtlZsFirst) {
+		panic("auxiliary polys don't match the table's cross-table lookups")
+	}
+	if len(proof.Openings.QuotientPolys) != len(expected.Proof.Openings.QuotientPolys) {
+		panic("number of opened quotient polys doesn't match the stark's constraint degree")
+	}
+}
+
+// Mirrors the JSON serialization of starky's StarkProofWithPublicInputs. The auxiliary polynomials
+// are only supported for cross-table lookups in which each table looks at most once, see
+// MultiTableVerifierChip.
+type StarkProofWithPublicInputsRaw struct {
+	Proof struct {
+		TraceCap          []types.HashOutRaw `json:"trace_cap"`
+		AuxiliaryPolysCap []types.HashOutRaw `json:"auxiliary_polys_cap"`
+		QuotientPolysCap  []types.HashOutRaw `json:"quotient_polys_cap"`
+		Openings          struct {
+			LocalValues        [][]uint64 `json:"local_values"`
+			NextValues         [][]uint64 `json:"next_values"`
+			AuxiliaryPolys     [][]uint64 `json:"auxiliary_polys"`
+			AuxiliaryPolysNext [][]uint64 `json:"auxiliary_polys_next"`
+			CtlZsFirst         []uint64   `json:"ctl_zs_first"`
+			QuotientPolys      [][]uint64 `json:"quotient_polys"`
+		} `json:"openings"`
+		OpeningProof struct {
+			CommitPhaseMerkleCaps [][]types.HashOutRaw `json:"commit_phase_merkle_caps"`
+			QueryRoundProofs      []struct {
+				InitialTreesProof struct {
+					EvalsProofs []types.EvalProofRaw `json:"evals_proofs"`
+				} `json:"initial_trees_proof"`
+				Steps []struct {
+					Evals       [][]uint64 `json:"evals"`
+					MerkleProof struct {
+						Siblings []types.HashOutRaw `json:"siblings"`
+					} `json:"merkle_proof"`
+				} `json:"steps"`
+			} `json:"query_round_proofs"`
+			FinalPoly struct {
+				Coeffs [][]uint64 `json:"coeffs"`
+			} `json:"final_poly"`
+			PowWitness uint64 `json:"pow_witness"`
+		} `json:"opening_proof"`
+	} `json:"proof"`
+	PublicInputs []uint64 `json:"public_inputs"`
+}
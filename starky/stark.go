@@ -0,0 +1,99 @@
+package starky
+
+import (
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+)
+
+// The AIR of a STARK, like starky's Stark trait. Implement it for your own STARKs to verify their
+// proofs with a VerifierChip.
+type Stark interface {
+	// The number of columns of the trace.
+	Columns() uint64
+	// The number of public inputs.
+	PublicInputs() uint64
+	// The maximum degree of the constraints, which must be at least 1.
+	ConstraintDegree() uint64
+	// Evaluates the constraints at the opened rows of the trace and passes them to the consumer,
+	// like starky's Stark::eval_ext_circuit.
+	EvalConstraints(api frontend.API, glApi *gl.Chip, vars EvaluationFrame, consumer *ConstraintConsumer)
+}
+
+// The opened rows of the trace and the public inputs a Stark's constraints are evaluated at. The
+// next row is the row following the local one, i.e. the trace opened at zeta * g where g generates
+// the trace domain.
+type EvaluationFrame struct {
+	LocalValues  []gl.QuadraticExtensionVariable
+	NextValues   []gl.QuadraticExtensionVariable
+	PublicInputs []gl.QuadraticExtensionVariable
+}
+
+// The number of quotient polynomials per challenge, whose degree is at most the trace's.
+func QuotientDegreeFactor(stark Stark) uint64 {
+	return max(1, stark.ConstraintDegree()-1)
+}
+
+// Combines the constraints of a Stark into one accumulator per challenge, like starky's
+// RecursiveConstraintConsumer. The constraints are filtered by the Lagrange basis polynomials of the
+// first and last rows, or by the polynomial vanishing on the last row for transition constraints.
+type ConstraintConsumer struct {
+	glApi *gl.Chip
+
+	alphas         []gl.Variable
+	constraintAccs []gl.QuadraticExtensionVariable
+
+	zLast              gl.QuadraticExtensionVariable
+	lagrangeBasisFirst gl.QuadraticExtensionVariable
+	lagrangeBasisLast  gl.QuadraticExtensionVariable
+}
+
+func NewConstraintConsumer(
+	glApi *gl.Chip,
+	alphas []gl.Variable,
+	zLast gl.QuadraticExtensionVariable,
+	lagrangeBasisFirst gl.QuadraticExtensionVariable,
+	lagrangeBasisLast gl.QuadraticExtensionVariable,
+) *ConstraintConsumer {
+	constraintAccs := make([]gl.QuadraticExtensionVariable, len(alphas))
+	for i := range constraintAccs {
+		constraintAccs[i] = gl.ZeroExtension()
+	}
+	return &ConstraintConsumer{
+		glApi:              glApi,
+		alphas:             alphas,
+		constraintAccs:     constraintAccs,
+		zLast:              zLast,
+		lagrangeBasisFirst: lagrangeBasisFirst,
+		lagrangeBasisLast:  lagrangeBasisLast,
+	}
+}
+
+// Returns the accumulated constraints, one per alpha.
+func (c *ConstraintConsumer) Accumulators() []gl.QuadraticExtensionVariable {
+	return c.constraintAccs
+}
+
+// Adds a constraint holding on every row.
+func (c *ConstraintConsumer) Constraint(constraint gl.QuadraticExtensionVariable) {
+	for i, alpha := range c.alphas {
+		c.constraintAccs[i] = c.glApi.AddExtension(
+			c.glApi.ScalarMulExtension(c.constraintAccs[i], alpha),
+			constraint,
+		)
+	}
+}
+
+// Adds a constraint holding on every row but the last, e.g. between a row and the next one.
+func (c *ConstraintConsumer) ConstraintTransition(constraint gl.QuadraticExtensionVariable) {
+	c.Constraint(c.glApi.MulExtension(constraint, c.zLast))
+}
+
+// Adds a constraint holding on the first row.
+func (c *ConstraintConsumer) ConstraintFirstRow(constraint gl.QuadraticExtensionVariable) {
+	c.Constraint(c.glApi.MulExtension(constraint, c.lagrangeBasisFirst))
+}
+
+// Adds a constraint holding on the last row.
+func (c *ConstraintConsumer) ConstraintLastRow(constraint gl.QuadraticExtensionVariable) {
+	c.Constraint(c.glApi.MulExtension(constraint, c.lagrangeBasisLast))
+}
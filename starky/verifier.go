@@ -0,0 +1,333 @@
+package starky
+
+import (
+	"slices"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/elliottech/gnark-plonky2-verifier/challenger"
+	"github.com/elliottech/gnark-plonky2-verifier/fri"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/hasher"
+	"github.com/elliottech/gnark-plonky2-verifier/profiler"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// The indices of the oracles committed by starky proofs. The quotient oracle follows the auxiliary
+// one, which only proofs with cross-table lookups have.
+const (
+	TRACE_ORACLE_INDEX     = 0
+	AUXILIARY_ORACLE_INDEX = 1
+)
+
+type VerifierChip struct {
+	api        frontend.API     `gnark:"-"`
+	glChip     *gl.Chip         `gnark:"-"`
+	hasher     hasher.Hasher    `gnark:"-"`
+	friChip    *fri.Chip        `gnark:"-"`
+	stark      Stark            `gnark:"-"`
+	config     StarkConfig      `gnark:"-"`
+	friParams  *types.FriParams `gnark:"-"`
+	degreeBits uint64           `gnark:"-"`
+	// The table's CTL Z polynomials, set by MultiTableVerifierChip.
+	ctlZData []ctlZData `gnark:"-"`
+}
+
+// Creates the chip verifying proofs of stark generated with config for traces of 2^degreeBits rows.
+func NewVerifierChip(api frontend.API, stark Stark, config StarkConfig, degreeBits uint64) *VerifierChip {
+	friParams := config.FriParams(degreeBits)
+	return &VerifierChip{
+		api:        api,
+		glChip:     gl.New(api),
+		hasher:     hasher.New(api, config.Hasher),
+		friChip:    fri.NewChipWithParams(api, config.Hasher, &friParams),
+		stark:      stark,
+		config:     config,
+		friParams:  &friParams,
+		degreeBits: degreeBits,
+	}
+}
+
+// Replays the prover's transcript, like starky's StarkProofWithPublicInputs::get_challenges. Unless
+// the config's LegacyTranscript is set, the challenger observes the FRI parameters before the public
+// inputs, in the order of this repo's plonky2 verifier. That order hasn't been checked against
+// starky's until TestVerifierCircuitFile has a proof starky generated.
+func (c *VerifierChip) GetChallenges(proof StarkProof, publicInputs []gl.Variable) StarkProofChallenges {
+	challenger := challenger.NewChip(c.api, c.hasher)
+
+	c.observeFriParams(challenger)
+	challenger.ObserveElements(publicInputs)
+	challenger.ObserveCap(proof.TraceCap)
+
+	return c.getProofChallenges(challenger, proof)
+}
+
+func (c *VerifierChip) observeFriParams(challenger *challenger.Chip) {
+	if c.config.LegacyTranscript {
+		return
+	}
+	friConfig := c.config.FriConfig
+	challenger.ObserveElement(gl.NewVariable(friConfig.RateBits))
+	challenger.ObserveElement(gl.NewVariable(friConfig.CapHeight))
+	challenger.ObserveElement(gl.NewVariable(friConfig.ProofOfWorkBits))
+	challenger.ObserveFriReductionStrategy(friConfig.ReductionStrategy)
+	challenger.ObserveElement(gl.NewVariable(friConfig.NumQueryRounds))
+	challenger.ObserveElement(gl.Zero()) // Starky proofs are never hiding.
+	challenger.ObserveElement(gl.NewVariable(c.friParams.DegreeBits))
+	for _, bit := range c.friParams.ReductionArityBits {
+		challenger.ObserveElement(gl.NewVariable(bit))
+	}
+}
+
+// Draws the challenges of a proof whose trace cap has been observed, like starky's
+// StarkProof::get_challenges.
+func (c *VerifierChip) getProofChallenges(challenger *challenger.Chip, proof StarkProof) StarkProofChallenges {
+	if proof.AuxiliaryPolysCap != nil {
+		challenger.ObserveCap(proof.AuxiliaryPolysCap)
+	}
+	starkAlphas := challenger.GetNChallenges(c.config.NumChallenges)
+
+	challenger.ObserveCap(proof.QuotientPolysCap)
+	starkZeta := challenger.GetExtensionChallenge()
+
+	challenger.ObserveOpenings(c.ToOpenings(proof.Openings))
+
+	return StarkProofChallenges{
+		StarkAlphas: starkAlphas,
+		StarkZeta:   starkZeta,
+		FriChallenges: challenger.GetFriChallenges(
+			proof.OpeningProof.CommitPhaseMerkleCaps,
+			proof.OpeningProof.FinalPoly,
+			proof.OpeningProof.PowWitness,
+			c.config.FriConfig,
+		),
+	}
+}
+
+// Returns the polynomials opened by starky proofs: the trace, auxiliary and quotient polynomials at
+// zeta, the trace and auxiliary polynomials at zeta * g, where g generates the trace domain, and the
+// CTL Z polynomials at 1.
+func (c *VerifierChip) GetInstance(zeta gl.QuadraticExtensionVariable) fri.InstanceInfo {
+	numColumns := c.stark.Columns()
+	numCtlZs := uint64(len(c.ctlZData))
+	numQuotientPolys := c.config.NumQuotientPolys(c.stark)
+
+	oracles := []fri.OracleInfo{{NumPolys: numColumns, Blinding: false}}
+	if numCtlZs != 0 {
+		oracles = append(oracles, fri.OracleInfo{NumPolys: numCtlZs, Blinding: false})
+	}
+	quotientOracleIndex := uint64(len(oracles))
+	oracles = append(oracles, fri.OracleInfo{NumPolys: numQuotientPolys, Blinding: false})
+
+	nextPolys := polynomialInfos(TRACE_ORACLE_INDEX, numColumns)
+	ctlZsPolys := polynomialInfos(AUXILIARY_ORACLE_INDEX, numCtlZs)
+	nextPolys = append(nextPolys, ctlZsPolys...)
+	zetaPolys := append(slices.Clone(nextPolys), polynomialInfos(quotientOracleIndex, numQuotientPolys)...)
+
+	g := gl.PrimitiveRootOfUnity(c.degreeBits)
+	zetaNext := c.glChip.MulExtension(
+		gl.NewVariableUint64(g.Uint64()).ToQuadraticExtension(),
+		zeta,
+	)
+
+	batches := []fri.BatchInfo{
+		{Point: zeta, Polynomials: zetaPolys},
+		{Point: zetaNext, Polynomials: nextPolys},
+	}
+	if numCtlZs != 0 {
+		batches = append(batches, fri.BatchInfo{Point: gl.OneExtension(), Polynomials: ctlZsPolys})
+	}
+
+	return fri.InstanceInfo{Oracles: oracles, Batches: batches}
+}
+
+func polynomialInfos(oracleIndex uint64, numPolys uint64) []fri.PolynomialInfo {
+	polys := make([]fri.PolynomialInfo, numPolys)
+	for i := range polys {
+		polys[i] = fri.PolynomialInfo{OracleIndex: oracleIndex, PolynomialInfo: uint64(i)}
+	}
+	return polys
+}
+
+// Returns the opened values in the order of GetInstance's batches, like starky's
+// StarkOpeningSet::to_fri_openings.
+func (c *VerifierChip) ToOpenings(openings StarkOpeningSet) fri.Openings {
+	values := slices.Concat(openings.LocalValues, openings.AuxiliaryPolys, openings.QuotientPolys)
+	nextValues := slices.Concat(openings.NextValues, openings.AuxiliaryPolysNext)
+	batches := []fri.OpeningBatch{{Values: values}, {Values: nextValues}}
+
+	if len(openings.CtlZsFirst) != 0 {
+		ctlZsFirst := make([]gl.QuadraticExtensionVariable, len(openings.CtlZsFirst))
+		for i, ctlZFirst := range openings.CtlZsFirst {
+			ctlZsFirst[i] = ctlZFirst.ToQuadraticExtension()
+		}
+		batches = append(batches, fri.OpeningBatch{Values: ctlZsFirst})
+	}
+
+	return fri.Openings{Batches: batches}
+}
+
+func (c *VerifierChip) rangeCheckProof(proofWithPis StarkProofWithPublicInputs) {
+	proof := proofWithPis.Proof
+
+	// The public inputs are observed by the challenger, so they must be canonical.
+	for _, publicInput := range proofWithPis.PublicInputs {
+		c.glChip.RangeCheck(publicInput)
+	}
+
+	for _, localValue := range proof.Openings.LocalValues {
+		c.glChip.RangeCheckQE(localValue)
+	}
+
+	for _, nextValue := range proof.Openings.NextValues {
+		c.glChip.RangeCheckQE(nextValue)
+	}
+
+	for _, auxiliaryPoly := range slices.Concat(proof.Openings.AuxiliaryPolys, proof.Openings.AuxiliaryPolysNext) {
+		c.glChip.RangeCheckQE(auxiliaryPoly)
+	}
+
+	for _, ctlZFirst := range proof.Openings.CtlZsFirst {
+		c.glChip.RangeCheck(ctlZFirst)
+	}
+
+	for _, quotientPoly := range proof.Openings.QuotientPolys {
+		c.glChip.RangeCheckQE(quotientPoly)
+	}
+
+	for _, cap := range []variables.FriMerkleCap{proof.TraceCap, proof.AuxiliaryPolysCap, proof.QuotientPolysCap} {
+		for _, hash := range cap {
+			c.hasher.RangeCheck(hash)
+		}
+	}
+
+	c.friChip.RangeCheckFriProof(&proof.OpeningProof)
+}
+
+// Evaluates the Lagrange basis polynomials of the first and last rows of the trace domain at x,
+// given x^n - 1 where n is the number of rows:
+//
+//	L_0(x) = (x^n - 1) / (n * (x - 1))
+//	L_{n-1}(x) = (x^n - 1) / (n * (g * x - 1))
+func (c *VerifierChip) evalL0AndLLast(x gl.QuadraticExtensionVariable, zX gl.QuadraticExtensionVariable) (gl.QuadraticExtensionVariable, gl.QuadraticExtensionVariable) {
+	n := goldilocks.NewElement(uint64(1) << c.degreeBits)
+	g := gl.PrimitiveRootOfUnity(c.degreeBits)
+	var nG goldilocks.Element
+	nG.Mul(&n, &g)
+	nQE := gl.NewVariableUint64(n.Uint64()).ToQuadraticExtension()
+
+	l0, hasL0 := c.glChip.DivExtension(
+		zX,
+		c.glChip.SubExtension(c.glChip.ScalarMulExtension(x, gl.NewVariableUint64(n.Uint64())), nQE),
+	)
+	c.api.AssertIsEqual(hasL0, frontend.Variable(1))
+
+	lLast, hasLLast := c.glChip.DivExtension(
+		zX,
+		c.glChip.SubExtension(c.glChip.ScalarMulExtension(x, gl.NewVariableUint64(nG.Uint64())), nQE),
+	)
+	c.api.AssertIsEqual(hasLLast, frontend.Variable(1))
+
+	return l0, lLast
+}
+
+// Checks that the stark's constraints, followed by the constraints of its CTL Z polynomials,
+// combined with the alphas and evaluated at zeta, are the quotient polynomials times the trace
+// domain's vanishing polynomial, like starky's verify_stark_proof_with_challenges.
+func (c *VerifierChip) verifyVanishingPolys(
+	challenges StarkProofChallenges,
+	openings StarkOpeningSet,
+	publicInputs []gl.Variable,
+	ctlChallenges []GrandProductChallenge,
+) {
+	zeta := challenges.StarkZeta
+	zetaPowN := zeta
+	for i := uint64(0); i < c.degreeBits; i++ {
+		zetaPowN = c.glChip.MulExtension(zetaPowN, zetaPowN)
+	}
+	zHZeta := c.glChip.SubExtension(zetaPowN, gl.OneExtension())
+
+	l0, lLast := c.evalL0AndLLast(zeta, zHZeta)
+
+	// The transition constraints are filtered by the polynomial vanishing on the last row.
+	g := gl.PrimitiveRootOfUnity(c.degreeBits)
+	var gInv goldilocks.Element
+	gInv.Inverse(&g)
+	zLast := c.glChip.SubExtension(zeta, gl.NewVariableUint64(gInv.Uint64()).ToQuadraticExtension())
+
+	publicInputsQE := make([]gl.QuadraticExtensionVariable, len(publicInputs))
+	for i, publicInput := range publicInputs {
+		publicInputsQE[i] = publicInput.ToQuadraticExtension()
+	}
+	vars := EvaluationFrame{
+		LocalValues:  openings.LocalValues,
+		NextValues:   openings.NextValues,
+		PublicInputs: publicInputsQE,
+	}
+
+	consumer := NewConstraintConsumer(c.glChip, challenges.StarkAlphas, zLast, l0, lLast)
+	c.stark.EvalConstraints(c.api, c.glChip, vars, consumer)
+	evalCrossTableLookupChecks(c.glChip, vars, openings, c.ctlZData, ctlChallenges, consumer)
+	vanishingPolysZeta := consumer.Accumulators()
+
+	// Each chunk of QuotientDegreeFactor quotient polynomials holds the evaluations at zeta of
+	// t_0, ..., t_{QuotientDegreeFactor-1}, where the quotient is t(X) = t_0(X) + t_1(X)*X^n + ...
+	quotientDegreeFactor := QuotientDegreeFactor(c.stark)
+	for i, vanishingPolyZeta := range vanishingPolysZeta {
+		chunk := openings.QuotientPolys[uint64(i)*quotientDegreeFactor : uint64(i+1)*quotientDegreeFactor]
+		c.glChip.AssertIsEqualExtension(
+			vanishingPolyZeta,
+			c.glChip.MulExtension(zHZeta, c.glChip.ReduceWithPowers(chunk, zetaPowN)),
+		)
+	}
+}
+
+func (c *VerifierChip) Verify(proofWithPis StarkProofWithPublicInputs) {
+	if len(c.ctlZData) != 0 {
+		panic("tables with cross-table lookups are verified by MultiTableVerifierChip")
+	}
+	c.validateProofShape(&proofWithPis)
+
+	stop := profiler.Start("range checks")
+	c.rangeCheckProof(proofWithPis)
+	stop()
+
+	stop = profiler.Start("challenger")
+	challenges := c.GetChallenges(proofWithPis.Proof, proofWithPis.PublicInputs)
+	stop()
+
+	c.verifyProofWithChallenges(proofWithPis, challenges, nil)
+}
+
+func (c *VerifierChip) validateProofShape(proofWithPis *StarkProofWithPublicInputs) {
+	validateStarkProofShape(proofWithPis, c.stark, &c.config, c.degreeBits, uint64(len(c.ctlZData)))
+}
+
+func (c *VerifierChip) verifyProofWithChallenges(
+	proofWithPis StarkProofWithPublicInputs,
+	challenges StarkProofChallenges,
+	ctlChallenges []GrandProductChallenge,
+) {
+	proof := proofWithPis.Proof
+
+	stop := profiler.Start("constraints")
+	c.verifyVanishingPolys(challenges, proof.Openings, proofWithPis.PublicInputs, ctlChallenges)
+	stop()
+
+	initialMerkleCaps := []variables.FriMerkleCap{proof.TraceCap}
+	if proof.AuxiliaryPolysCap != nil {
+		initialMerkleCaps = append(initialMerkleCaps, proof.AuxiliaryPolysCap)
+	}
+	initialMerkleCaps = append(initialMerkleCaps, proof.QuotientPolysCap)
+
+	stop = profiler.Start("fri")
+	c.friChip.VerifyFriProof(
+		c.GetInstance(challenges.StarkZeta),
+		c.ToOpenings(proof.Openings),
+		&challenges.FriChallenges,
+		initialMerkleCaps,
+		&proof.OpeningProof,
+	)
+	stop()
+}
@@ -0,0 +1,239 @@
+package starky
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
+
+// Mirrors starky's FibonacciStark: the columns hold two consecutive Fibonacci numbers, starting with
+// the first two public inputs, and the last row's second column is the third public input.
+type fibonacciStark struct{}
+
+func (s fibonacciStark) Columns() uint64          { return 2 }
+func (s fibonacciStark) PublicInputs() uint64     { return 3 }
+func (s fibonacciStark) ConstraintDegree() uint64 { return 2 }
+
+func (s fibonacciStark) EvalConstraints(api frontend.API, glApi *gl.Chip, vars EvaluationFrame, consumer *ConstraintConsumer) {
+	local, next, pis := vars.LocalValues, vars.NextValues, vars.PublicInputs
+	consumer.ConstraintFirstRow(glApi.SubExtension(local[0], pis[0]))
+	consumer.ConstraintFirstRow(glApi.SubExtension(local[1], pis[1]))
+	consumer.ConstraintLastRow(glApi.SubExtension(local[1], pis[2]))
+	consumer.ConstraintTransition(glApi.SubExtension(next[0], local[1]))
+	consumer.ConstraintTransition(glApi.SubExtension(next[1], glApi.AddExtension(local[0], local[1])))
+}
+
+// Evaluates at x the polynomial taking the given values at points.
+func interpolateNative(points []gl.QuadraticExtension, values []gl.QuadraticExtension, x gl.QuadraticExtension) gl.QuadraticExtension {
+	sum := gl.ZeroExtensionNative()
+	for i := range points {
+		sum = sum.Add(values[i].Mul(lagrangeBasisNative(points, i, x)))
+	}
+	return sum
+}
+
+func lagrangeBasisNative(points []gl.QuadraticExtension, i int, x gl.QuadraticExtension) gl.QuadraticExtension {
+	basis := gl.OneExtensionNative()
+	for j := range points {
+		if j != i {
+			basis = basis.Mul(x.Sub(points[j])).Div(points[i].Sub(points[j]))
+		}
+	}
+	return basis
+}
+
+// A Fibonacci trace and the evaluations of its polynomials, which the verifier only gets as openings.
+type fibonacciTrace struct {
+	domain       []gl.QuadraticExtension
+	columns      [2][]gl.QuadraticExtension
+	publicInputs []gl.QuadraticExtension
+}
+
+func newFibonacciTrace(degreeBits uint64) fibonacciTrace {
+	var trace fibonacciTrace
+	for _, h := range gl.TwoAdicSubgroup(degreeBits) {
+		trace.domain = append(trace.domain, gl.ToQuadraticExtension(h))
+	}
+
+	a, b := gl.NewQuadraticExtensionUint64(0, 0), gl.NewQuadraticExtensionUint64(1, 0)
+	for range trace.domain {
+		trace.columns[0] = append(trace.columns[0], a)
+		trace.columns[1] = append(trace.columns[1], b)
+		a, b = b, a.Add(b)
+	}
+	trace.publicInputs = []gl.QuadraticExtension{trace.columns[0][0], trace.columns[1][0], trace.columns[1][len(trace.domain)-1]}
+	return trace
+}
+
+func (t *fibonacciTrace) row(x gl.QuadraticExtension) []gl.QuadraticExtension {
+	return []gl.QuadraticExtension{
+		interpolateNative(t.domain, t.columns[0], x),
+		interpolateNative(t.domain, t.columns[1], x),
+	}
+}
+
+// Evaluates the Fibonacci constraints combined with alpha at x, with the Lagrange basis and the
+// last row's vanishing polynomial computed from the trace domain's points.
+func (t *fibonacciTrace) combinedConstraints(x gl.QuadraticExtension, alpha goldilocks.Element) gl.QuadraticExtension {
+	n := len(t.domain)
+	local := t.row(x)
+	next := t.row(x.Mul(t.domain[1]))
+	lFirst := lagrangeBasisNative(t.domain, 0, x)
+	lLast := lagrangeBasisNative(t.domain, n-1, x)
+	zLast := x.Sub(t.domain[n-1])
+
+	constraints := []gl.QuadraticExtension{
+		local[0].Sub(t.publicInputs[0]).Mul(lFirst),
+		local[1].Sub(t.publicInputs[1]).Mul(lFirst),
+		local[1].Sub(t.publicInputs[2]).Mul(lLast),
+		next[0].Sub(local[1]).Mul(zLast),
+		next[1].Sub(local[0].Add(local[1])).Mul(zLast),
+	}
+	acc := gl.ZeroExtensionNative()
+	for _, constraint := range constraints {
+		acc = acc.ScalarMul(alpha).Add(constraint)
+	}
+	return acc
+}
+
+// Evaluates at x the quotient of the combined constraints by the trace domain's vanishing
+// polynomial, interpolated from its values on a coset of the trace domain. The quotient has a lower
+// degree than the trace only if the constraints vanish on the trace domain.
+func (t *fibonacciTrace) quotient(x gl.QuadraticExtension, alpha goldilocks.Element) gl.QuadraticExtension {
+	n := uint64(len(t.domain))
+	shift := gl.ToQuadraticExtension(gl.MULTIPLICATIVE_GROUP_GENERATOR)
+	coset := make([]gl.QuadraticExtension, n)
+	values := make([]gl.QuadraticExtension, n)
+	for i, h := range t.domain {
+		coset[i] = shift.Mul(h)
+		zH := coset[i].Exp(n).Sub(gl.OneExtensionNative())
+		values[i] = t.combinedConstraints(coset[i], alpha).Div(zH)
+	}
+	return interpolateNative(coset, values, x)
+}
+
+func toExtensionVariable(x gl.QuadraticExtension) gl.QuadraticExtensionVariable {
+	return gl.NewQuadraticExtensionVariable(gl.NewVariableUint64(x[0].Uint64()), gl.NewVariableUint64(x[1].Uint64()))
+}
+
+func toExtensionVariables(xs []gl.QuadraticExtension) []gl.QuadraticExtensionVariable {
+	variables := make([]gl.QuadraticExtensionVariable, len(xs))
+	for i, x := range xs {
+		variables[i] = toExtensionVariable(x)
+	}
+	return variables
+}
+
+type testVanishingPolysCircuit struct {
+	StarkAlphas  []gl.Variable
+	StarkZeta    gl.QuadraticExtensionVariable
+	Openings     StarkOpeningSet
+	PublicInputs []gl.Variable
+
+	DegreeBits uint64 `gnark:"-"`
+}
+
+func (c *testVanishingPolysCircuit) Define(api frontend.API) error {
+	verifierChip := NewVerifierChip(api, fibonacciStark{}, StandardFastConfig(types.PoseidonGoldilocksHash), c.DegreeBits)
+	challenges := StarkProofChallenges{StarkAlphas: c.StarkAlphas, StarkZeta: c.StarkZeta}
+	verifierChip.verifyVanishingPolys(challenges, c.Openings, c.PublicInputs, nil)
+	return nil
+}
+
+func TestVerifyVanishingPolys(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	degreeBits := uint64(3)
+	trace := newFibonacciTrace(degreeBits)
+	zeta := gl.NewQuadraticExtensionUint64(0x1234567890abcdef, 0xfedcba0987654321)
+	alphas := []goldilocks.Element{goldilocks.NewElement(0x0123456789abcdef), goldilocks.NewElement(0xabcdef0123456789)}
+
+	newAssignment := func(publicInputs []gl.QuadraticExtension) *testVanishingPolysCircuit {
+		var assignment testVanishingPolysCircuit
+		assignment.StarkZeta = toExtensionVariable(zeta)
+		for _, alpha := range alphas {
+			assignment.StarkAlphas = append(assignment.StarkAlphas, gl.NewVariableUint64(alpha.Uint64()))
+			assignment.Openings.QuotientPolys = append(assignment.Openings.QuotientPolys, toExtensionVariable(trace.quotient(zeta, alpha)))
+		}
+		assignment.Openings.LocalValues = toExtensionVariables(trace.row(zeta))
+		assignment.Openings.NextValues = toExtensionVariables(trace.row(zeta.Mul(trace.domain[1])))
+		for _, publicInput := range publicInputs {
+			assignment.PublicInputs = append(assignment.PublicInputs, gl.NewVariableUint64(publicInput[0].Uint64()))
+		}
+		return &assignment
+	}
+
+	circuit := testVanishingPolysCircuit{
+		StarkAlphas: make([]gl.Variable, len(alphas)),
+		Openings: StarkOpeningSet{
+			LocalValues:   make([]gl.QuadraticExtensionVariable, 2),
+			NextValues:    make([]gl.QuadraticExtensionVariable, 2),
+			QuotientPolys: make([]gl.QuadraticExtensionVariable, len(alphas)),
+		},
+		PublicInputs: make([]gl.Variable, 3),
+		DegreeBits:   degreeBits,
+	}
+
+	assert.NoError(test.IsSolved(&circuit, newAssignment(trace.publicInputs), ecc.BN254.ScalarField()))
+
+	// A wrong result doesn't satisfy the last row's constraint.
+	wrongPublicInputs := append([]gl.QuadraticExtension{}, trace.publicInputs...)
+	wrongPublicInputs[2] = wrongPublicInputs[2].Add(gl.OneExtensionNative())
+	assert.Error(test.IsSolved(&circuit, newAssignment(wrongPublicInputs), ecc.BN254.ScalarField()))
+}
+
+func TestVerifierCircuitCompiles(t *testing.T) {
+	config := StandardFastConfig(types.PoseidonGoldilocksHash)
+	config.FriConfig.NumQueryRounds = 2
+	circuit := NewVerifierCircuit(fibonacciStark{}, config, 10)
+	if len(circuit.Proof.OpeningProof.CommitPhaseMerkleCaps) != 1 {
+		t.Fatalf("expected one FRI reduction, got %d", len(circuit.Proof.OpeningProof.CommitPhaseMerkleCaps))
+	}
+
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A proof of starky's FibonacciStark generated with StandardFastConfig and serialized with
+// serde_json, e.g. the proof of starky's test_fibonacci_stark.
+const starkyProof = "../testdata/starky/fibonacci/proof_with_public_inputs.json"
+
+// Verifies a proof generated by starky with the default transcript, checking it against starky's.
+func TestVerifierCircuitFile(t *testing.T) {
+	if _, err := os.Stat(starkyProof); errors.Is(err, os.ErrNotExist) {
+		t.Skipf("no starky proof at %s", starkyProof)
+	}
+	assert := test.NewAssert(t)
+
+	raw, err := ReadStarkProofWithPublicInputs(starkyProof)
+	assert.NoError(err)
+	proofWithPis, err := DeserializeStarkProofWithPublicInputs(raw)
+	assert.NoError(err)
+
+	config := StandardFastConfig(types.PoseidonGoldilocksHash)
+	circuit := NewVerifierCircuit(fibonacciStark{}, config, proofDegreeBits(raw, &config))
+	assert.NoError(test.IsSolved(circuit, NewVerifierCircuitAssignment(proofWithPis), ecc.BN254.ScalarField()))
+
+	// A wrong result doesn't satisfy the last row's constraint.
+	raw.PublicInputs[2]++
+	proofWithPis, err = DeserializeStarkProofWithPublicInputs(raw)
+	assert.NoError(err)
+	assert.Error(test.IsSolved(circuit, NewVerifierCircuitAssignment(proofWithPis), ecc.BN254.ScalarField()))
+}
+
+// Returns the degree bits of the trace of a proof: its Merkle proofs go from the LDE's leaves,
+// 2^(degreeBits + rateBits) of them, to the cap of 2^capHeight hashes.
+func proofDegreeBits(raw StarkProofWithPublicInputsRaw, config *StarkConfig) uint64 {
+	siblings := raw.Proof.OpeningProof.QueryRoundProofs[0].InitialTreesProof.EvalsProofs[0].MerkleProof.Hash
+	return uint64(len(siblings)) + config.FriConfig.CapHeight - config.FriConfig.RateBits
+}
@@ -0,0 +1,195 @@
+package trusted_setup
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark/constraint"
+)
+
+// PlonkSrsSize returns the number of points of the canonical and Lagrange KZG SRS gnark PLONK needs
+// to set up ccs.
+func PlonkSrsSize(ccs constraint.ConstraintSystem) (sizeCanonical, sizeLagrange uint64) {
+	sizeSystem := ccs.GetNbConstraints() + ccs.GetNbPublicVariables()
+	sizeLagrange = ecc.NextPowerOfTwo(uint64(sizeSystem))
+	sizeCanonical = sizeLagrange + 3
+	return sizeCanonical, sizeLagrange
+}
+
+// TruncateSrs returns srs with its first size points, which it shares with srs.
+func TruncateSrs(srs *kzg_bn254.SRS, size uint64) (*kzg_bn254.SRS, error) {
+	if uint64(len(srs.Pk.G1)) < size {
+		return nil, fmt.Errorf("the SRS has %d points, %d are needed", len(srs.Pk.G1), size)
+	}
+	return &kzg_bn254.SRS{Pk: kzg_bn254.ProvingKey{G1: srs.Pk.G1[:size]}, Vk: srs.Vk}, nil
+}
+
+// LagrangeSrs returns the Lagrange form of the first size points of srs. size must be a power of
+// two.
+func LagrangeSrs(srs *kzg_bn254.SRS, size uint64) (*kzg_bn254.SRS, error) {
+	if uint64(len(srs.Pk.G1)) < size {
+		return nil, fmt.Errorf("the SRS has %d points, %d are needed", len(srs.Pk.G1), size)
+	}
+	lagrangeG1, err := kzg_bn254.ToLagrangeG1(srs.Pk.G1[:size])
+	if err != nil {
+		return nil, err
+	}
+	return &kzg_bn254.SRS{Pk: kzg_bn254.ProvingKey{G1: lagrangeG1}, Vk: srs.Vk}, nil
+}
+
+// PlonkSrs returns the canonical and Lagrange KZG SRS gnark PLONK needs to set up ccs, from an SRS
+// of at least PlonkSrsSize points, e.g. the whole Ignition SRS.
+func PlonkSrs(ccs constraint.ConstraintSystem, srs *kzg_bn254.SRS) (canonical, lagrange *kzg_bn254.SRS, err error) {
+	sizeCanonical, sizeLagrange := PlonkSrsSize(ccs)
+
+	canonical, err = TruncateSrs(srs, sizeCanonical)
+	if err != nil {
+		return nil, nil, err
+	}
+	lagrange, err = LagrangeSrs(srs, sizeLagrange)
+	if err != nil {
+		return nil, nil, err
+	}
+	return canonical, lagrange, nil
+}
+
+// ReadPlonkSrs reads the KZG SRS at srsPath, e.g. written by DownloadAndSaveAztecIgnitionSrs, and
+// returns the canonical and Lagrange SRS gnark PLONK needs to set up ccs.
+//
+// If cacheDir isn't empty, both are cached there, under a key of srsPath and their size. Constraint
+// systems of the same size then don't reload the whole SRS nor recompute the Lagrange form. The key
+// is the SHA-256 of the content of srsPath, which reads the whole SRS on every call. If keyOnModTime
+// is set, it's the path, size and modification time of srsPath instead, which skips the hash but
+// reuses a stale cache if the SRS is replaced by one with the same size and modification time
+// (e.g. by cp -p or rsync -t). The cache files are dumps of the memory of the SRS, read without any
+// validation: cacheDir must be trusted.
+func ReadPlonkSrs(ccs constraint.ConstraintSystem, srsPath string, cacheDir string, keyOnModTime bool) (canonical, lagrange *kzg_bn254.SRS, err error) {
+	if cacheDir == "" {
+		srs, err := readSrs(srsPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return PlonkSrs(ccs, srs)
+	}
+
+	srsKey := hashFile
+	if keyOnModTime {
+		srsKey = srsFileKey
+	}
+	hash, err := srsKey(srsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	sizeCanonical, _ := PlonkSrsSize(ccs)
+	key := fmt.Sprintf("kzgsrs-bn254-%s-%d", hash, sizeCanonical)
+	canonicalPath := filepath.Join(cacheDir, key)
+	lagrangePath := filepath.Join(cacheDir, key+"-lagrange")
+
+	canonical, lagrange = new(kzg_bn254.SRS), new(kzg_bn254.SRS)
+	if readDump(canonicalPath, canonical) == nil && readDump(lagrangePath, lagrange) == nil {
+		return canonical, lagrange, nil
+	}
+
+	srs, err := readSrs(srsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	canonical, lagrange, err = PlonkSrs(ccs, srs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return nil, nil, err
+	}
+	if err := writeDump(canonicalPath, canonical); err != nil {
+		return nil, nil, err
+	}
+	if err := writeDump(lagrangePath, lagrange); err != nil {
+		return nil, nil, err
+	}
+	return canonical, lagrange, nil
+}
+
+func readSrs(srsPath string) (*kzg_bn254.SRS, error) {
+	file, err := os.Open(srsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var srs kzg_bn254.SRS
+	if _, err := srs.ReadFrom(bufio.NewReader(file)); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", srsPath, err)
+	}
+	return &srs, nil
+}
+
+// Returns the hash of the absolute path, size and modification time of the file at path, which
+// changes whenever the file is rewritten.
+func srsFileKey(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(fmt.Appendf(nil, "%s\x00%d\x00%d", absPath, info.Size(), info.ModTime().UnixNano()))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("hashing %s: %w", path, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func readDump(path string, srs *kzg_bn254.SRS) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return srs.ReadDump(bufio.NewReader(file))
+}
+
+// Writes the dump to a temporary file renamed once complete, so that an interrupted write isn't
+// read back as a truncated SRS.
+func writeDump(path string, srs *kzg_bn254.SRS) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if err := srs.WriteDump(writer); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,140 @@
+package trusted_setup
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+)
+
+type cubeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+func compileCube(assert *test.Assert) constraint.ConstraintSystem {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &cubeCircuit{})
+	assert.NoError(err)
+	return ccs
+}
+
+// Writes an SRS larger than what the cube circuit needs, as the Ignition SRS is.
+func writeTestSrs(assert *test.Assert, srsPath string) *kzg_bn254.SRS {
+	srs, err := kzg_bn254.NewSRS(64, big.NewInt(42))
+	assert.NoError(err)
+	assert.NoError(writeSrs(srs, srsPath))
+	return srs
+}
+
+func assertSameSrs(assert *test.Assert, expected, actual *kzg_bn254.SRS) {
+	assert.Equal(len(expected.Pk.G1), len(actual.Pk.G1))
+	for i := range expected.Pk.G1 {
+		assert.True(expected.Pk.G1[i].Equal(&actual.Pk.G1[i]), "point %d", i)
+	}
+	assert.True(expected.Vk.G1.Equal(&actual.Vk.G1))
+	assert.True(expected.Vk.G2[1].Equal(&actual.Vk.G2[1]))
+}
+
+func TestPlonkSrs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	ccs := compileCube(assert)
+	srs := writeTestSrs(assert, filepath.Join(t.TempDir(), "srs"))
+
+	sizeCanonical, sizeLagrange := PlonkSrsSize(ccs)
+	canonical, lagrange, err := PlonkSrs(ccs, srs)
+	assert.NoError(err)
+	assert.Equal(sizeCanonical, uint64(len(canonical.Pk.G1)))
+	assert.Equal(sizeLagrange, uint64(len(lagrange.Pk.G1)))
+
+	pk, vk, err := plonk.Setup(ccs, canonical, lagrange)
+	assert.NoError(err)
+	witness, err := frontend.NewWitness(&cubeCircuit{X: 3, Y: 27}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	proof, err := plonk.Prove(ccs, pk, witness)
+	assert.NoError(err)
+	publicWitness, err := witness.Public()
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, publicWitness))
+
+	_, err = TruncateSrs(srs, uint64(len(srs.Pk.G1))+1)
+	assert.Error(err)
+}
+
+func TestReadPlonkSrsCache(t *testing.T) {
+	for _, keyOnModTime := range []bool{false, true} {
+		t.Run(fmt.Sprintf("keyOnModTime=%t", keyOnModTime), func(t *testing.T) {
+			testReadPlonkSrsCache(t, keyOnModTime)
+		})
+	}
+}
+
+func testReadPlonkSrsCache(t *testing.T, keyOnModTime bool) {
+	assert := test.NewAssert(t)
+
+	ccs := compileCube(assert)
+	srsPath := filepath.Join(t.TempDir(), "srs")
+	srs := writeTestSrs(assert, srsPath)
+	srsInfo, err := os.Stat(srsPath)
+	assert.NoError(err)
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+
+	expectedCanonical, expectedLagrange, err := PlonkSrs(ccs, srs)
+	assert.NoError(err)
+
+	canonical, lagrange, err := ReadPlonkSrs(ccs, srsPath, cacheDir, keyOnModTime)
+	assert.NoError(err)
+	assertSameSrs(assert, expectedCanonical, canonical)
+	assertSameSrs(assert, expectedLagrange, lagrange)
+
+	cacheFiles := func() []os.FileInfo {
+		entries, err := os.ReadDir(cacheDir)
+		assert.NoError(err)
+		infos := make([]os.FileInfo, len(entries))
+		for i, entry := range entries {
+			infos[i], err = entry.Info()
+			assert.NoError(err)
+		}
+		return infos
+	}
+	cached := cacheFiles()
+	assert.Equal(2, len(cached))
+
+	// Another SRS misses the cache.
+	assert.NoError(os.WriteFile(srsPath, nil, 0o644))
+	_, _, err = ReadPlonkSrs(ccs, srsPath, cacheDir, keyOnModTime)
+	assert.Error(err)
+
+	// The same SRS hits it, without rewriting the cache files. With keyOnModTime, the key is the
+	// modification time rather than the content.
+	writeTestSrs(assert, srsPath)
+	if keyOnModTime {
+		assert.NoError(os.Chtimes(srsPath, srsInfo.ModTime(), srsInfo.ModTime()))
+	}
+	canonical, lagrange, err = ReadPlonkSrs(ccs, srsPath, cacheDir, keyOnModTime)
+	assert.NoError(err)
+	assertSameSrs(assert, expectedCanonical, canonical)
+	assertSameSrs(assert, expectedLagrange, lagrange)
+	for i, info := range cacheFiles() {
+		assert.True(os.SameFile(cached[i], info), "%s was rewritten", info.Name())
+	}
+
+	canonical, lagrange, err = ReadPlonkSrs(ccs, srsPath, "", keyOnModTime)
+	assert.NoError(err)
+	assertSameSrs(assert, expectedCanonical, canonical)
+	assertSameSrs(assert, expectedLagrange, lagrange)
+}
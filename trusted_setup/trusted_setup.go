@@ -1,8 +1,14 @@
 package trusted_setup
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
@@ -10,7 +16,7 @@ import (
 	"github.com/consensys/gnark-ignition-verifier/ignition"
 )
 
-func sanityCheck(srs *kzg_bn254.SRS) {
+func sanityCheck(srs *kzg_bn254.SRS) error {
 	// we can now use the SRS to verify a proof
 	// create a polynomial
 	f := randomPolynomial(60)
@@ -18,7 +24,7 @@ func sanityCheck(srs *kzg_bn254.SRS) {
 	// commit the polynomial
 	digest, err := kzg_bn254.Commit(f, srs.Pk)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// compute opening proof at a random point
@@ -26,20 +32,17 @@ func sanityCheck(srs *kzg_bn254.SRS) {
 	point.SetString("4321")
 	proof, err := kzg_bn254.Open(f, point, srs.Pk)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	// verify the claimed valued
 	expected := eval(f, point)
 	if !proof.ClaimedValue.Equal(&expected) {
-		log.Fatal("inconsistent claimed value")
+		return errors.New("inconsistent claimed value")
 	}
 
 	// verify correct proof
-	err = kzg_bn254.Verify(&digest, &proof, point, srs.Vk)
-	if err != nil {
-		log.Fatal(err)
-	}
+	return kzg_bn254.Verify(&digest, &proof, point, srs.Vk)
 }
 
 func randomPolynomial(size int) []fr.Element {
@@ -62,6 +65,79 @@ func eval(p []fr.Element, point fr.Element) fr.Element {
 	return res
 }
 
+// ProgressFunc is called after each verified contribution of the ceremony, with the number of
+// contributions verified so far and their total.
+type ProgressFunc func(verified, total int)
+
+// Verifies that each contribution of the ceremony from startIdx on is valid and follows the
+// previous one, and returns the last one.
+func verifyContributions(manifest ignition.Manifest, startIdx int, config ignition.Config, progress ProgressFunc) (ignition.Contribution, error) {
+	if startIdx < 0 || startIdx+1 >= len(manifest.Participants) {
+		return ignition.Contribution{}, fmt.Errorf("start index %d out of range, the ceremony has %d contributions", startIdx, len(manifest.Participants))
+	}
+	total := len(manifest.Participants) - startIdx
+
+	current, next := ignition.NewContribution(manifest.NumG1Points), ignition.NewContribution(manifest.NumG1Points)
+
+	if err := current.Get(manifest.Participants[startIdx], config); err != nil {
+		return ignition.Contribution{}, fmt.Errorf("contribution %d: %w", startIdx, err)
+	}
+	progress(1, total)
+
+	for i := startIdx + 1; i < len(manifest.Participants); i++ {
+		if err := next.Get(manifest.Participants[i], config); err != nil {
+			return ignition.Contribution{}, fmt.Errorf("contribution %d: %w", i, err)
+		}
+		if !next.Follows(&current) {
+			return ignition.Contribution{}, fmt.Errorf("contribution %d does not follow contribution %d", i, i-1)
+		}
+		progress(i-startIdx+1, total)
+		current, next = next, current
+	}
+
+	return current, nil
+}
+
+// Builds the KZG SRS of the last contribution of the ceremony, and checks it commits and opens.
+func newSrs(last ignition.Contribution, preComputeLines bool) (*kzg_bn254.SRS, error) {
+	_, _, _, g2gen := bn254.Generators()
+	srs := kzg_bn254.SRS{
+		Pk: kzg_bn254.ProvingKey{
+			G1: last.G1,
+		},
+		Vk: kzg_bn254.VerifyingKey{
+			G1: last.G1[0],
+			G2: [2]bn254.G2Affine{
+				g2gen,
+				last.G2[0],
+			},
+		},
+	}
+
+	if preComputeLines {
+		srs.Vk.Lines[0] = bn254.PrecomputeLines(srs.Vk.G2[0])
+		srs.Vk.Lines[1] = bn254.PrecomputeLines(srs.Vk.G2[1])
+	}
+
+	if err := sanityCheck(&srs); err != nil {
+		return nil, fmt.Errorf("kzg sanity check: %w", err)
+	}
+	return &srs, nil
+}
+
+func writeSrs(srs *kzg_bn254.SRS, fileName string) error {
+	fSRS, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer fSRS.Close()
+
+	if _, err := srs.WriteTo(fSRS); err != nil {
+		return err
+	}
+	return fSRS.Close()
+}
+
 func DownloadAndSaveAztecIgnitionSrs(startIdx int, fileName string, preComputeLines bool) {
 	config := ignition.Config{
 		BaseURL:  "https://aztec-ignition.s3.amazonaws.com/",
@@ -74,7 +150,6 @@ func DownloadAndSaveAztecIgnitionSrs(startIdx int, fileName string, preComputeLi
 
 		if err != nil {
 			log.Fatal("when creating cache dir: ", err)
-			panic(err)
 		}
 	}
 
@@ -86,65 +161,102 @@ func DownloadAndSaveAztecIgnitionSrs(startIdx int, fileName string, preComputeLi
 		log.Fatal("when fetching manifest: ", err)
 	}
 
-	current, next := ignition.NewContribution(manifest.NumG1Points), ignition.NewContribution(manifest.NumG1Points)
+	last, err := verifyContributions(manifest, startIdx, config, func(verified, total int) {
+		log.Println("processing contribution ", startIdx+verified)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	if err := current.Get(manifest.Participants[startIdx], config); err != nil {
-		log.Fatal("when fetching contribution: ", err)
+	log.Println("success ✅: all contributions are valid")
+
+	srs, err := newSrs(last, preComputeLines)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if err := next.Get(manifest.Participants[startIdx+1], config); err != nil {
-		log.Fatal("when fetching contribution: ", err)
+	log.Println("success ✅: kzg sanity check with SRS")
+
+	if err := writeSrs(srs, fileName); err != nil {
+		log.Fatal("error writing srs file: ", err)
 	}
-	if !next.Follows(&current) {
-		log.Fatalf("contribution %d does not follow contribution %d", startIdx+1, startIdx)
+}
+
+// Checks that all the transcripts of a participant are in the local ceremony directory, as the
+// ignition package downloads the files it doesn't find.
+func checkLocalTranscripts(ceremonyDir string, participant ignition.Participant) error {
+	participantDir := filepath.Join(ceremonyDir, fmt.Sprintf("%03d_%s", participant.Position, strings.ToLower(participant.Address)))
+
+	// The transcript manifest starts with the transcript number and the total number of
+	// transcripts, as 32 bit big endian integers.
+	var header [8]byte
+	transcript, err := os.Open(filepath.Join(participantDir, "transcript00.dat"))
+	if err != nil {
+		return err
+	}
+	defer transcript.Close()
+	if _, err := io.ReadFull(transcript, header[:]); err != nil {
+		return fmt.Errorf("reading %s: %w", transcript.Name(), err)
 	}
 
-	for i := startIdx + 2; i < len(manifest.Participants); i++ {
-		log.Println("processing contribution ", i+1)
-		current, next = next, current
-		if err := next.Get(manifest.Participants[i], config); err != nil {
-			log.Fatal("when fetching contribution ", i+1, ": ", err)
-		}
-		if !next.Follows(&current) {
-			log.Fatal("contribution ", i+1, " does not follow contribution ", i, ": ", err)
+	totalTranscripts := binary.BigEndian.Uint32(header[4:8])
+	for i := uint32(1); i < totalTranscripts; i++ {
+		if _, err := os.Stat(filepath.Join(participantDir, fmt.Sprintf("transcript%02d.dat", i))); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	log.Println("success ✅: all contributions are valid")
+// LoadLocalAztecIgnitionSrs is the offline counterpart of DownloadAndSaveAztecIgnitionSrs: it
+// verifies the contributions from startIdx on of an Ignition ceremony mirrored in ceremonyDir, and
+// returns the KZG SRS of the last one.
+//
+// ceremonyDir is laid out as the ceremony in the aztec-ignition bucket, e.g. "MAIN IGNITION": a
+// manifest.json, and a <position>_<address> directory of transcriptNN.dat files per participant.
+// Nothing is downloaded, missing files are errors. progress may be nil.
+func LoadLocalAztecIgnitionSrs(ceremonyDir string, startIdx int, preComputeLines bool, progress ProgressFunc) (*kzg_bn254.SRS, error) {
+	if progress == nil {
+		progress = func(int, int) {}
+	}
 
-	_, _, _, g2gen := bn254.Generators()
-	// we use the last contribution to build a kzg SRS for bn254
-	srs := kzg_bn254.SRS{
-		Pk: kzg_bn254.ProvingKey{
-			G1: next.G1,
-		},
-		Vk: kzg_bn254.VerifyingKey{
-			G1: next.G1[0],
-			G2: [2]bn254.G2Affine{
-				g2gen,
-				next.G2[0],
-			},
-		},
+	ceremonyDir = filepath.Clean(ceremonyDir)
+	if _, err := os.Stat(filepath.Join(ceremonyDir, "manifest.json")); err != nil {
+		return nil, err
 	}
 
-	if preComputeLines {
-		srs.Vk.Lines[0] = bn254.PrecomputeLines(srs.Vk.G2[0])
-		srs.Vk.Lines[1] = bn254.PrecomputeLines(srs.Vk.G2[1])
+	// The ignition package reads the files of the ceremony from CacheDir/Ceremony. Without a base
+	// URL, it can't download the ones which are missing.
+	config := ignition.Config{
+		Ceremony: filepath.Base(ceremonyDir),
+		CacheDir: filepath.Dir(ceremonyDir),
 	}
 
-	// sanity check
-	sanityCheck(&srs)
-	log.Println("success ✅: kzg sanity check with SRS")
+	manifest, err := ignition.NewManifest(config)
+	if err != nil {
+		return nil, fmt.Errorf("reading the manifest: %w", err)
+	}
 
-	fSRS, err := os.Create(fileName)
+	if startIdx >= 0 && startIdx < len(manifest.Participants) {
+		for i, participant := range manifest.Participants[startIdx:] {
+			if err := checkLocalTranscripts(ceremonyDir, participant); err != nil {
+				return nil, fmt.Errorf("contribution %d: %w", startIdx+i, err)
+			}
+		}
+	}
+
+	last, err := verifyContributions(manifest, startIdx, config, progress)
 	if err != nil {
-		log.Fatal("error creating srs file: ", err)
-		panic(err)
+		return nil, err
 	}
-	defer fSRS.Close()
 
-	_, err = srs.WriteTo(fSRS)
+	return newSrs(last, preComputeLines)
+}
+
+// SaveLocalAztecIgnitionSrs writes the SRS returned by LoadLocalAztecIgnitionSrs to fileName.
+func SaveLocalAztecIgnitionSrs(ceremonyDir string, startIdx int, fileName string, preComputeLines bool, progress ProgressFunc) error {
+	srs, err := LoadLocalAztecIgnitionSrs(ceremonyDir, startIdx, preComputeLines, progress)
 	if err != nil {
-		log.Fatal("error writing srs file: ", err)
-		panic(err)
+		return err
 	}
+	return writeSrs(srs, fileName)
 }
@@ -0,0 +1,143 @@
+package trusted_setup
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-ignition-verifier/ignition"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/blake2b"
+)
+
+// The number of G1 points of the test ceremonies. The ignition package draws the randomness of its
+// validity check once per process, for this number of points.
+const nbG1Points = 64
+
+// Appends a field element as an Ignition transcript does: four 64 bit words, least significant
+// first, each in big endian.
+func appendFp(b []byte, x *fp.Element) []byte {
+	for _, word := range x.Bits() {
+		b = binary.BigEndian.AppendUint64(b, word)
+	}
+	return b
+}
+
+// Writes the single transcript of a participant whose contribution is the powers tau¹, ..., tauⁿ in
+// G1, with tau in G2 and its own secret in G2.
+func writeTranscript(assert *test.Assert, ceremonyDir string, participant ignition.Participant, tau, secret *fr.Element) {
+	_, _, g1gen, g2gen := bn254.Generators()
+
+	transcript := make([]byte, 0)
+	for _, field := range []uint32{0, 1, nbG1Points, 1, nbG1Points, 2, 0} {
+		transcript = binary.BigEndian.AppendUint32(transcript, field)
+	}
+
+	var power fr.Element
+	power.Set(tau)
+	for i := 0; i < nbG1Points; i++ {
+		var point bn254.G1Affine
+		point.ScalarMultiplication(&g1gen, power.BigInt(new(big.Int)))
+		transcript = appendFp(transcript, &point.X)
+		transcript = appendFp(transcript, &point.Y)
+		power.Mul(&power, tau)
+	}
+
+	for _, scalar := range []*fr.Element{tau, secret} {
+		var point bn254.G2Affine
+		point.ScalarMultiplication(&g2gen, scalar.BigInt(new(big.Int)))
+		transcript = appendFp(transcript, &point.X.A0)
+		transcript = appendFp(transcript, &point.X.A1)
+		transcript = appendFp(transcript, &point.Y.A0)
+		transcript = appendFp(transcript, &point.Y.A1)
+	}
+
+	checksum := blake2b.Sum512(transcript)
+	transcript = append(transcript, checksum[:]...)
+
+	participantDir := filepath.Join(ceremonyDir, fmt.Sprintf("%03d_%s", participant.Position, participant.Address))
+	assert.NoError(os.MkdirAll(participantDir, os.ModePerm))
+	assert.NoError(os.WriteFile(filepath.Join(participantDir, "transcript00.dat"), transcript, 0o644))
+}
+
+// Writes a ceremony of nbParticipants contributions, each multiplying tau by a fresh secret, and
+// returns the final tau.
+func writeCeremony(assert *test.Assert, ceremonyDir string, nbParticipants int) fr.Element {
+	manifest := ignition.Manifest{Name: "TEST", NumG1Points: nbG1Points, NumG2Points: 1}
+
+	var tau fr.Element
+	tau.SetOne()
+	for i := 0; i < nbParticipants; i++ {
+		participant := ignition.Participant{Address: fmt.Sprintf("0x%040x", i+1), Position: i + 1}
+		manifest.Participants = append(manifest.Participants, participant)
+
+		var secret fr.Element
+		secret.SetRandom()
+		tau.Mul(&tau, &secret)
+		writeTranscript(assert, ceremonyDir, participant, &tau, &secret)
+	}
+
+	b, err := json.Marshal(manifest)
+	assert.NoError(err)
+	assert.NoError(os.WriteFile(filepath.Join(ceremonyDir, "manifest.json"), b, 0o644))
+
+	return tau
+}
+
+func TestLoadLocalAztecIgnitionSrs(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	ceremonyDir := filepath.Join(t.TempDir(), "TEST CEREMONY")
+	tau := writeCeremony(assert, ceremonyDir, 4)
+
+	var progress [][2]int
+	srs, err := LoadLocalAztecIgnitionSrs(ceremonyDir, 1, false, func(verified, total int) {
+		progress = append(progress, [2]int{verified, total})
+	})
+	assert.NoError(err)
+	assert.Equal([][2]int{{1, 3}, {2, 3}, {3, 3}}, progress)
+
+	_, _, g1gen, _ := bn254.Generators()
+	var expected bn254.G1Affine
+	expected.ScalarMultiplication(&g1gen, tau.BigInt(new(big.Int)))
+	assert.True(srs.Vk.G1.Equal(&expected))
+	assert.Equal(nbG1Points, len(srs.Pk.G1))
+
+	srsPath := filepath.Join(t.TempDir(), "srs")
+	assert.NoError(SaveLocalAztecIgnitionSrs(ceremonyDir, 0, srsPath, true, nil))
+	_, err = os.Stat(srsPath)
+	assert.NoError(err)
+}
+
+func TestLoadLocalAztecIgnitionSrsErrors(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	ceremonyDir := filepath.Join(t.TempDir(), "TEST CEREMONY")
+	writeCeremony(assert, ceremonyDir, 3)
+
+	_, err := LoadLocalAztecIgnitionSrs(ceremonyDir, 2, false, nil)
+	assert.ErrorContains(err, "out of range")
+
+	// A contribution which doesn't build on the previous one.
+	var tau, secret fr.Element
+	tau.SetRandom()
+	secret.SetRandom()
+	writeTranscript(assert, ceremonyDir, ignition.Participant{Address: fmt.Sprintf("0x%040x", 3), Position: 3}, &tau, &secret)
+	_, err = LoadLocalAztecIgnitionSrs(ceremonyDir, 0, false, nil)
+	assert.ErrorContains(err, "contribution 2 does not follow contribution 1")
+
+	// Missing transcripts are errors, not downloads.
+	assert.NoError(os.RemoveAll(filepath.Join(ceremonyDir, fmt.Sprintf("002_0x%040x", 2))))
+	_, err = LoadLocalAztecIgnitionSrs(ceremonyDir, 0, false, nil)
+	assert.ErrorIs(err, os.ErrNotExist)
+
+	_, err = LoadLocalAztecIgnitionSrs(t.TempDir(), 0, false, nil)
+	assert.ErrorIs(err, os.ErrNotExist)
+}
@@ -0,0 +1,125 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+)
+
+// Reads the binary encoding of plonky2's util::serialization::Buffer, in which integers are
+// little endian, usizes are written as u64 and field elements as their canonical u64.
+type Buffer struct {
+	data []byte
+	pos  int
+}
+
+func NewBuffer(data []byte) *Buffer {
+	return &Buffer{data: data}
+}
+
+// Returns the number of bytes left to read.
+func (b *Buffer) Len() int {
+	return len(b.data) - b.pos
+}
+
+func (b *Buffer) ReadBytes(n int) ([]byte, error) {
+	if n < 0 || n > b.Len() {
+		return nil, fmt.Errorf("reading %d bytes at offset %d: %w", n, b.pos, io.ErrUnexpectedEOF)
+	}
+	bytes := b.data[b.pos : b.pos+n]
+	b.pos += n
+	return bytes, nil
+}
+
+func (b *Buffer) ReadU8() (uint8, error) {
+	bytes, err := b.ReadBytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return bytes[0], nil
+}
+
+func (b *Buffer) ReadBool() (bool, error) {
+	x, err := b.ReadU8()
+	if err != nil {
+		return false, err
+	}
+	if x > 1 {
+		return false, fmt.Errorf("invalid bool %d at offset %d", x, b.pos-1)
+	}
+	return x == 1, nil
+}
+
+func (b *Buffer) ReadU16() (uint16, error) {
+	bytes, err := b.ReadBytes(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(bytes), nil
+}
+
+func (b *Buffer) ReadU32() (uint32, error) {
+	bytes, err := b.ReadBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(bytes), nil
+}
+
+func (b *Buffer) ReadUsize() (uint64, error) {
+	bytes, err := b.ReadBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(bytes), nil
+}
+
+// Reads a usize length followed by as many usizes.
+func (b *Buffer) ReadUsizeVec() ([]uint64, error) {
+	length, err := b.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(b.Len()/8) {
+		return nil, fmt.Errorf("reading %d usizes at offset %d: %w", length, b.pos, io.ErrUnexpectedEOF)
+	}
+
+	v := make([]uint64, length)
+	for i := range v {
+		if v[i], err = b.ReadUsize(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Reads a Goldilocks field element, rejecting non canonical encodings.
+func (b *Buffer) ReadField() (goldilocks.Element, error) {
+	bytes, err := b.ReadBytes(8)
+	if err != nil {
+		return goldilocks.Element{}, err
+	}
+	x, err := goldilocks.LittleEndian.Element((*[8]byte)(bytes))
+	if err != nil {
+		return goldilocks.Element{}, fmt.Errorf("field element at offset %d: %w", b.pos-8, err)
+	}
+	return x, nil
+}
+
+// Reads length field elements. As in plonky2, their number isn't part of the encoding.
+func (b *Buffer) ReadFieldVec(length uint64) ([]goldilocks.Element, error) {
+	if length > uint64(b.Len()/8) {
+		return nil, fmt.Errorf("reading %d field elements at offset %d: %w", length, b.pos, io.ErrUnexpectedEOF)
+	}
+
+	v := make([]goldilocks.Element, length)
+	for i := range v {
+		var err error
+		if v[i], err = b.ReadField(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 
@@ -19,24 +20,20 @@ type CommonCircuitDataRaw struct {
 		ZeroKnowledge           bool   `json:"zero_knowledge"`
 		MaxQuotientDegreeFactor uint64 `json:"max_quotient_degree_factor"`
 		FriConfig               struct {
-			RateBits          uint64 `json:"rate_bits"`
-			CapHeight         uint64 `json:"cap_height"`
-			ProofOfWorkBits   uint64 `json:"proof_of_work_bits"`
-			ReductionStrategy struct {
-				ConstantArityBits []uint64 `json:"ConstantArityBits"`
-			} `json:"reduction_strategy"`
-			NumQueryRounds uint64 `json:"num_query_rounds"`
+			RateBits          uint64                     `json:"rate_bits"`
+			CapHeight         uint64                     `json:"cap_height"`
+			ProofOfWorkBits   uint64                     `json:"proof_of_work_bits"`
+			ReductionStrategy map[string]json.RawMessage `json:"reduction_strategy"`
+			NumQueryRounds    uint64                     `json:"num_query_rounds"`
 		} `json:"fri_config"`
 	} `json:"config"`
 	FriParams struct {
 		Config struct {
-			RateBits          uint64 `json:"rate_bits"`
-			CapHeight         uint64 `json:"cap_height"`
-			ProofOfWorkBits   uint64 `json:"proof_of_work_bits"`
-			ReductionStrategy struct {
-				ConstantArityBits []uint64 `json:"ConstantArityBits"`
-			} `json:"reduction_strategy"`
-			NumQueryRounds uint64 `json:"num_query_rounds"`
+			RateBits          uint64                     `json:"rate_bits"`
+			CapHeight         uint64                     `json:"cap_height"`
+			ProofOfWorkBits   uint64                     `json:"proof_of_work_bits"`
+			ReductionStrategy map[string]json.RawMessage `json:"reduction_strategy"`
+			NumQueryRounds    uint64                     `json:"num_query_rounds"`
 		} `json:"config"`
 		Hiding             bool     `json:"hiding"`
 		DegreeBits         uint64   `json:"degree_bits"`
@@ -50,12 +47,15 @@ type CommonCircuitDataRaw struct {
 			End   uint64 `json:"end"`
 		} `json:"groups"`
 	} `json:"selectors_info"`
-	QuotientDegreeFactor uint64   `json:"quotient_degree_factor"`
-	NumGateConstraints   uint64   `json:"num_gate_constraints"`
-	NumConstants         uint64   `json:"num_constants"`
-	NumPublicInputs      uint64   `json:"num_public_inputs"`
-	KIs                  []uint64 `json:"k_is"`
-	NumPartialProducts   uint64   `json:"num_partial_products"`
+	QuotientDegreeFactor uint64        `json:"quotient_degree_factor"`
+	NumGateConstraints   uint64        `json:"num_gate_constraints"`
+	NumConstants         uint64        `json:"num_constants"`
+	NumPublicInputs      uint64        `json:"num_public_inputs"`
+	KIs                  []uint64      `json:"k_is"`
+	NumPartialProducts   uint64        `json:"num_partial_products"`
+	NumLookupPolys       uint64        `json:"num_lookup_polys"`
+	NumLookupSelectors   uint64        `json:"num_lookup_selectors"`
+	Luts                 []LookupTable `json:"luts"`
 }
 
 func ReadCommonCircuitData(path string) CommonCircuitData {
@@ -87,19 +87,16 @@ func ReadCommonCircuitData(path string) CommonCircuitData {
 	commonCircuitData.Config.FriConfig.CapHeight = raw.Config.FriConfig.CapHeight
 	commonCircuitData.Config.FriConfig.ProofOfWorkBits = raw.Config.FriConfig.ProofOfWorkBits
 	commonCircuitData.Config.FriConfig.NumQueryRounds = raw.Config.FriConfig.NumQueryRounds
-	commonCircuitData.Config.FriConfig.ReductionStrategy = FriReductionStrategy{
-		ConstantArityBits: raw.Config.FriConfig.ReductionStrategy.ConstantArityBits,
-	}
+	commonCircuitData.Config.FriConfig.ReductionStrategy = deserializeFriReductionStrategy(raw.Config.FriConfig.ReductionStrategy)
 
+	commonCircuitData.FriParams.Hiding = raw.FriParams.Hiding
 	commonCircuitData.FriParams.DegreeBits = raw.FriParams.DegreeBits
 	commonCircuitData.DegreeBits = raw.FriParams.DegreeBits
 	commonCircuitData.FriParams.Config.RateBits = raw.FriParams.Config.RateBits
 	commonCircuitData.FriParams.Config.CapHeight = raw.FriParams.Config.CapHeight
 	commonCircuitData.FriParams.Config.ProofOfWorkBits = raw.FriParams.Config.ProofOfWorkBits
 	commonCircuitData.FriParams.Config.NumQueryRounds = raw.FriParams.Config.NumQueryRounds
-	commonCircuitData.FriParams.Config.ReductionStrategy = FriReductionStrategy{
-		ConstantArityBits: raw.FriParams.Config.ReductionStrategy.ConstantArityBits,
-	}
+	commonCircuitData.FriParams.Config.ReductionStrategy = deserializeFriReductionStrategy(raw.FriParams.Config.ReductionStrategy)
 	commonCircuitData.FriParams.ReductionArityBits = raw.FriParams.ReductionArityBits
 
 	commonCircuitData.GateIds = raw.Gates
@@ -115,6 +112,7 @@ func ReadCommonCircuitData(path string) CommonCircuitData {
 		raw.SelectorsInfo.SelectorIndices,
 		selectorGroupStart,
 		selectorGroupEnd,
+		raw.NumLookupSelectors,
 	)
 
 	commonCircuitData.QuotientDegreeFactor = raw.QuotientDegreeFactor
@@ -123,11 +121,301 @@ func ReadCommonCircuitData(path string) CommonCircuitData {
 	commonCircuitData.NumPublicInputs = raw.NumPublicInputs
 	commonCircuitData.KIs = raw.KIs
 	commonCircuitData.NumPartialProducts = raw.NumPartialProducts
+	commonCircuitData.NumLookupPolys = raw.NumLookupPolys
+	commonCircuitData.Luts = raw.Luts
+
+	return commonCircuitData
+}
 
-	// Don't support circuits that have hiding enabled
-	if raw.FriParams.Hiding {
-		panic("Circuit has hiding enabled, which is not supported")
+// Serde encodes plonky2's FriReductionStrategy enum as an object keyed by the variant name, e.g.
+// {"ConstantArityBits":[4,5]}, {"Fixed":[4,4,4]} or {"MinSize":null}.
+func deserializeFriReductionStrategy(raw map[string]json.RawMessage) FriReductionStrategy {
+	if len(raw) != 1 {
+		panic(fmt.Sprintf("FRI reduction strategy should have exactly one variant, got %d", len(raw)))
 	}
 
-	return commonCircuitData
+	var strategy FriReductionStrategy
+	for variant, value := range raw {
+		var err error
+		switch variant {
+		case "Fixed":
+			strategy.Kind = FixedReductionStrategy
+			err = json.Unmarshal(value, &strategy.Fixed)
+		case "ConstantArityBits":
+			strategy.Kind = ConstantArityBitsReductionStrategy
+			err = json.Unmarshal(value, &strategy.ConstantArityBits)
+		case "MinSize":
+			strategy.Kind = MinSizeReductionStrategy
+			err = json.Unmarshal(value, &strategy.MinSize)
+		default:
+			panic(fmt.Sprintf("Unknown FRI reduction strategy %s", variant))
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return strategy
+}
+
+// Reads common circuit data serialized by plonky2's CommonCircuitData::to_bytes with a gate
+// serializer whose gate types gateSerializer decodes in the same order, e.g. DefaultGateSerializer.
+// Unlike ReadCommonCircuitData, the gates are decoded from their typed parameters rather than from
+// their Debug strings: CommonCircuitData.Gates holds them, and GateIds their IDs. A nil
+// gateSerializer stands for DefaultGateSerializer.
+func DecodeCommonCircuitData(rawBytes []byte, gateSerializer *GateSerializer) (CommonCircuitData, error) {
+	if gateSerializer == nil {
+		gateSerializer = DefaultGateSerializer()
+	}
+
+	buf := NewBuffer(rawBytes)
+	commonCircuitData, err := readCommonCircuitData(buf, gateSerializer)
+	if err != nil {
+		return CommonCircuitData{}, fmt.Errorf("reading the common circuit data: %w", err)
+	}
+	if buf.Len() != 0 {
+		return CommonCircuitData{}, fmt.Errorf("reading the common circuit data: %d trailing bytes", buf.Len())
+	}
+	return commonCircuitData, nil
+}
+
+func ReadCommonCircuitDataBinary(path string, gateSerializer *GateSerializer) (CommonCircuitData, error) {
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		return CommonCircuitData{}, err
+	}
+	return DecodeCommonCircuitData(rawBytes, gateSerializer)
+}
+
+// Mirrors plonky2's Buffer::read_common_circuit_data.
+func readCommonCircuitData(buf *Buffer, gateSerializer *GateSerializer) (CommonCircuitData, error) {
+	var commonCircuitData CommonCircuitData
+	var err error
+
+	if commonCircuitData.Config, err = readCircuitConfig(buf); err != nil {
+		return commonCircuitData, err
+	}
+	if commonCircuitData.FriParams, err = readFriParams(buf); err != nil {
+		return commonCircuitData, err
+	}
+	commonCircuitData.DegreeBits = commonCircuitData.FriParams.DegreeBits
+
+	selectorIndices, err := buf.ReadUsizeVec()
+	if err != nil {
+		return commonCircuitData, err
+	}
+	numSelectorGroups, err := buf.ReadUsize()
+	if err != nil {
+		return commonCircuitData, err
+	}
+	if numSelectorGroups > uint64(buf.Len()/16) {
+		return commonCircuitData, fmt.Errorf("%d selector groups: %w", numSelectorGroups, io.ErrUnexpectedEOF)
+	}
+	selectorGroupStart := make([]uint64, numSelectorGroups)
+	selectorGroupEnd := make([]uint64, numSelectorGroups)
+	for i := range selectorGroupStart {
+		if selectorGroupStart[i], err = buf.ReadUsize(); err != nil {
+			return commonCircuitData, err
+		}
+		if selectorGroupEnd[i], err = buf.ReadUsize(); err != nil {
+			return commonCircuitData, err
+		}
+	}
+
+	for _, field := range []*uint64{
+		&commonCircuitData.QuotientDegreeFactor,
+		&commonCircuitData.NumGateConstraints,
+		&commonCircuitData.NumConstants,
+		&commonCircuitData.NumPublicInputs,
+	} {
+		if *field, err = buf.ReadUsize(); err != nil {
+			return commonCircuitData, err
+		}
+	}
+
+	numKIs, err := buf.ReadUsize()
+	if err != nil {
+		return commonCircuitData, err
+	}
+	kIs, err := buf.ReadFieldVec(numKIs)
+	if err != nil {
+		return commonCircuitData, err
+	}
+	commonCircuitData.KIs = make([]uint64, len(kIs))
+	for i := range kIs {
+		commonCircuitData.KIs[i] = kIs[i].Uint64()
+	}
+
+	if commonCircuitData.NumPartialProducts, err = buf.ReadUsize(); err != nil {
+		return commonCircuitData, err
+	}
+	if commonCircuitData.NumLookupPolys, err = buf.ReadUsize(); err != nil {
+		return commonCircuitData, err
+	}
+	numLookupSelectors, err := buf.ReadUsize()
+	if err != nil {
+		return commonCircuitData, err
+	}
+	commonCircuitData.SelectorsInfo = *gates.NewSelectorsInfo(
+		selectorIndices,
+		selectorGroupStart,
+		selectorGroupEnd,
+		numLookupSelectors,
+	)
+
+	numLuts, err := buf.ReadUsize()
+	if err != nil {
+		return commonCircuitData, err
+	}
+	if numLuts > uint64(buf.Len()/8) {
+		return commonCircuitData, fmt.Errorf("%d lookup tables: %w", numLuts, io.ErrUnexpectedEOF)
+	}
+	commonCircuitData.Luts = make([]LookupTable, numLuts)
+	for i := range commonCircuitData.Luts {
+		if commonCircuitData.Luts[i], err = readLookupTable(buf); err != nil {
+			return commonCircuitData, fmt.Errorf("lookup table %d: %w", i, err)
+		}
+	}
+
+	numGates, err := buf.ReadUsize()
+	if err != nil {
+		return commonCircuitData, err
+	}
+	if numGates > uint64(buf.Len()/4) {
+		return commonCircuitData, fmt.Errorf("%d gates: %w", numGates, io.ErrUnexpectedEOF)
+	}
+	commonCircuitData.Gates = make([]gates.Gate, numGates)
+	commonCircuitData.GateIds = make([]string, numGates)
+	for i := range commonCircuitData.Gates {
+		gate, err := gateSerializer.ReadGate(buf, &commonCircuitData)
+		if err != nil {
+			return commonCircuitData, fmt.Errorf("gate %d: %w", i, err)
+		}
+		commonCircuitData.Gates[i] = gate
+		commonCircuitData.GateIds[i] = gate.Id()
+	}
+
+	return commonCircuitData, nil
+}
+
+func readCircuitConfig(buf *Buffer) (CircuitConfig, error) {
+	var config CircuitConfig
+	var err error
+
+	if config.NumWires, err = buf.ReadUsize(); err != nil {
+		return config, err
+	}
+	if config.NumRoutedWires, err = buf.ReadUsize(); err != nil {
+		return config, err
+	}
+	if config.NumConstants, err = buf.ReadUsize(); err != nil {
+		return config, err
+	}
+	if config.UseBaseArithmeticGate, err = buf.ReadBool(); err != nil {
+		return config, err
+	}
+	if config.SecurityBits, err = buf.ReadUsize(); err != nil {
+		return config, err
+	}
+	if config.NumChallenges, err = buf.ReadUsize(); err != nil {
+		return config, err
+	}
+	if config.ZeroKnowledge, err = buf.ReadBool(); err != nil {
+		return config, err
+	}
+	if config.MaxQuotientDegreeFactor, err = buf.ReadUsize(); err != nil {
+		return config, err
+	}
+	config.FriConfig, err = readFriConfig(buf)
+	return config, err
+}
+
+func readFriConfig(buf *Buffer) (FriConfig, error) {
+	var config FriConfig
+	var err error
+
+	if config.RateBits, err = buf.ReadUsize(); err != nil {
+		return config, err
+	}
+	if config.CapHeight, err = buf.ReadUsize(); err != nil {
+		return config, err
+	}
+	proofOfWorkBits, err := buf.ReadU32()
+	if err != nil {
+		return config, err
+	}
+	config.ProofOfWorkBits = uint64(proofOfWorkBits)
+
+	kind, err := buf.ReadU8()
+	if err != nil {
+		return config, err
+	}
+	config.ReductionStrategy.Kind = FriReductionStrategyKind(kind)
+	switch config.ReductionStrategy.Kind {
+	case FixedReductionStrategy:
+		if config.ReductionStrategy.Fixed, err = buf.ReadUsizeVec(); err != nil {
+			return config, err
+		}
+	case ConstantArityBitsReductionStrategy:
+		config.ReductionStrategy.ConstantArityBits = make([]uint64, 2)
+		for i := range config.ReductionStrategy.ConstantArityBits {
+			if config.ReductionStrategy.ConstantArityBits[i], err = buf.ReadUsize(); err != nil {
+				return config, err
+			}
+		}
+	case MinSizeReductionStrategy:
+		hasMax, err := buf.ReadBool()
+		if err != nil {
+			return config, err
+		}
+		if hasMax {
+			maxArityBits, err := buf.ReadUsize()
+			if err != nil {
+				return config, err
+			}
+			config.ReductionStrategy.MinSize = &maxArityBits
+		}
+	default:
+		return config, fmt.Errorf("unknown FRI reduction strategy %d", kind)
+	}
+
+	config.NumQueryRounds, err = buf.ReadUsize()
+	return config, err
+}
+
+func readFriParams(buf *Buffer) (FriParams, error) {
+	var params FriParams
+	var err error
+
+	if params.Config, err = readFriConfig(buf); err != nil {
+		return params, err
+	}
+	if params.Hiding, err = buf.ReadBool(); err != nil {
+		return params, err
+	}
+	if params.DegreeBits, err = buf.ReadUsize(); err != nil {
+		return params, err
+	}
+	params.ReductionArityBits, err = buf.ReadUsizeVec()
+	return params, err
+}
+
+func readLookupTable(buf *Buffer) (LookupTable, error) {
+	length, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(buf.Len()/4) {
+		return nil, fmt.Errorf("%d entries: %w", length, io.ErrUnexpectedEOF)
+	}
+
+	lut := make(LookupTable, length)
+	for i := range lut {
+		for j := range lut[i] {
+			if lut[i][j], err = buf.ReadU16(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return lut, nil
 }
@@ -1,9 +1,353 @@
 package types
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
 )
 
 func TestReadCommonCircuitData(t *testing.T) {
 	ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
 }
+
+func TestDeserializeFriReductionStrategy(t *testing.T) {
+	testCases := []struct {
+		json       string
+		kind       FriReductionStrategyKind
+		parameters []uint64
+	}{
+		{`{"Fixed":[4,4,3]}`, FixedReductionStrategy, []uint64{4, 4, 3}},
+		{`{"ConstantArityBits":[4,5]}`, ConstantArityBitsReductionStrategy, []uint64{4, 5}},
+		{`{"MinSize":3}`, MinSizeReductionStrategy, []uint64{3}},
+		{`{"MinSize":null}`, MinSizeReductionStrategy, []uint64{}},
+	}
+
+	for _, testCase := range testCases {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(testCase.json), &raw); err != nil {
+			t.Fatal(err)
+		}
+
+		strategy := deserializeFriReductionStrategy(raw)
+		if strategy.Kind != testCase.kind {
+			t.Fatalf("%s: expected kind %d, got %d", testCase.json, testCase.kind, strategy.Kind)
+		}
+
+		parameters := strategy.Parameters()
+		if len(parameters) != len(testCase.parameters) {
+			t.Fatalf("%s: expected parameters %v, got %v", testCase.json, testCase.parameters, parameters)
+		}
+		for i := range parameters {
+			if parameters[i] != testCase.parameters[i] {
+				t.Fatalf("%s: expected parameters %v, got %v", testCase.json, testCase.parameters, parameters)
+			}
+		}
+	}
+}
+
+func TestFriConfigFriParams(t *testing.T) {
+	for _, circuit := range []string{"decode_block", "step"} {
+		common := ReadCommonCircuitData("../testdata/" + circuit + "/common_circuit_data.json")
+		friParams := common.Config.FriConfig.FriParams(common.DegreeBits, common.FriParams.Hiding)
+		if !reflect.DeepEqual(friParams, common.FriParams) {
+			t.Fatalf("%s: expected FRI params %+v, got %+v", circuit, common.FriParams, friParams)
+		}
+	}
+
+	maxArityBits := uint64(3)
+	testCases := []struct {
+		strategy           FriReductionStrategy
+		degreeBits         uint64
+		reductionArityBits []uint64
+	}{
+		{FriReductionStrategy{Kind: FixedReductionStrategy, Fixed: []uint64{3, 2}}, 12, []uint64{3, 2}},
+		{FriReductionStrategy{Kind: ConstantArityBitsReductionStrategy, ConstantArityBits: []uint64{4, 5}}, 5, []uint64{}},
+		{FriReductionStrategy{Kind: ConstantArityBitsReductionStrategy, ConstantArityBits: []uint64{4, 5}}, 13, []uint64{4, 4}},
+		// The reductions stop before the codewords get smaller than the Merkle caps.
+		{FriReductionStrategy{Kind: ConstantArityBitsReductionStrategy, ConstantArityBits: []uint64{4, 0}}, 10, []uint64{4}},
+		// Reducing a polynomial of degree 4 only makes its proofs larger.
+		{FriReductionStrategy{Kind: MinSizeReductionStrategy, MinSize: &maxArityBits}, 2, []uint64{}},
+	}
+
+	for _, testCase := range testCases {
+		config := FriConfig{RateBits: 1, CapHeight: 4, NumQueryRounds: 28, ReductionStrategy: testCase.strategy}
+		friParams := config.FriParams(testCase.degreeBits, false)
+		if !reflect.DeepEqual(friParams.ReductionArityBits, testCase.reductionArityBits) {
+			t.Fatalf("%+v: expected reduction arity bits %v, got %v", testCase.strategy, testCase.reductionArityBits, friParams.ReductionArityBits)
+		}
+	}
+
+	// The min size reductions don't increase and don't exceed the max arity.
+	config := FriConfig{RateBits: 3, CapHeight: 4, NumQueryRounds: 28, ReductionStrategy: FriReductionStrategy{Kind: MinSizeReductionStrategy, MinSize: &maxArityBits}}
+	reductionArityBits := config.FriParams(20, false).ReductionArityBits
+	if len(reductionArityBits) == 0 || !slices.IsSortedFunc(reductionArityBits, func(a, b uint64) int { return int(b) - int(a) }) || reductionArityBits[0] > maxArityBits {
+		t.Fatalf("unexpected min size reduction arity bits %v", reductionArityBits)
+	}
+}
+
+// Writes like plonky2's Buffer, e.g. the common circuit data like write_common_circuit_data with the
+// DefaultGateSerializer, taking the gate parameters from their Debug strings.
+type bufferWriter []byte
+
+func (w *bufferWriter) writeUsize(x uint64) {
+	*w = binary.LittleEndian.AppendUint64(*w, x)
+}
+
+func (w *bufferWriter) writeBool(x bool) {
+	if x {
+		*w = append(*w, 1)
+	} else {
+		*w = append(*w, 0)
+	}
+}
+
+func (w *bufferWriter) writeUsizeVec(v []uint64) {
+	w.writeUsize(uint64(len(v)))
+	for _, x := range v {
+		w.writeUsize(x)
+	}
+}
+
+func (w *bufferWriter) writeFriConfig(config FriConfig) {
+	w.writeUsize(config.RateBits)
+	w.writeUsize(config.CapHeight)
+	*w = binary.LittleEndian.AppendUint32(*w, uint32(config.ProofOfWorkBits))
+	*w = append(*w, uint8(config.ReductionStrategy.Kind))
+	switch config.ReductionStrategy.Kind {
+	case FixedReductionStrategy:
+		w.writeUsizeVec(config.ReductionStrategy.Fixed)
+	case ConstantArityBitsReductionStrategy:
+		w.writeUsize(config.ReductionStrategy.ConstantArityBits[0])
+		w.writeUsize(config.ReductionStrategy.ConstantArityBits[1])
+	case MinSizeReductionStrategy:
+		w.writeBool(config.ReductionStrategy.MinSize != nil)
+		if config.ReductionStrategy.MinSize != nil {
+			w.writeUsize(*config.ReductionStrategy.MinSize)
+		}
+	}
+	w.writeUsize(config.NumQueryRounds)
+}
+
+var gateTags = map[string]uint32{
+	"ArithmeticGate":          0,
+	"ArithmeticExtensionGate": 1,
+	"BaseSumGate":             2,
+	"ConstantGate":            3,
+	"CosetInterpolationGate":  4,
+	"ExponentiationGate":      5,
+	"MulExtensionGate":        8,
+	"NoopGate":                9,
+	"PoseidonMdsGate":         10,
+	"PoseidonGate":            11,
+	"PublicInputGate":         12,
+	"RandomAccessGate":        13,
+	"ReducingExtensionGate":   14,
+	"ReducingGate":            15,
+}
+
+var gateNameRegex = regexp.MustCompile(`^[A-Za-z0-9]+`)
+var gateParameterRegex = regexp.MustCompile(`\b[a-z_]+: (\[[0-9, ]*\]|[0-9]+)`)
+
+func (w *bufferWriter) writeGate(gateId string) {
+	*w = binary.LittleEndian.AppendUint32(*w, gateTags[gateNameRegex.FindString(gateId)])
+	for _, parameter := range gateParameterRegex.FindAllStringSubmatch(gateId, -1) {
+		value := parameter[1]
+		if !strings.HasPrefix(value, "[") {
+			x, _ := strconv.ParseUint(value, 10, 64)
+			w.writeUsize(x)
+			continue
+		}
+
+		elements := strings.Split(strings.Trim(value, "[]"), ",")
+		w.writeUsize(uint64(len(elements)))
+		for _, element := range elements {
+			// Debug strings may hold non canonical field elements, which write_field reduces.
+			x, _ := strconv.ParseUint(strings.TrimSpace(element), 10, 64)
+			w.writeUsize(new(goldilocks.Element).SetUint64(x).Uint64())
+		}
+	}
+}
+
+func writeCommonCircuitData(raw CommonCircuitDataRaw, common CommonCircuitData) []byte {
+	var w bufferWriter
+	w.writeUsize(common.Config.NumWires)
+	w.writeUsize(common.Config.NumRoutedWires)
+	w.writeUsize(common.Config.NumConstants)
+	w.writeBool(common.Config.UseBaseArithmeticGate)
+	w.writeUsize(common.Config.SecurityBits)
+	w.writeUsize(common.Config.NumChallenges)
+	w.writeBool(common.Config.ZeroKnowledge)
+	w.writeUsize(common.Config.MaxQuotientDegreeFactor)
+	w.writeFriConfig(common.Config.FriConfig)
+
+	w.writeFriConfig(common.FriParams.Config)
+	w.writeBool(common.FriParams.Hiding)
+	w.writeUsize(common.FriParams.DegreeBits)
+	w.writeUsizeVec(common.FriParams.ReductionArityBits)
+
+	w.writeUsizeVec(raw.SelectorsInfo.SelectorIndices)
+	w.writeUsize(uint64(len(raw.SelectorsInfo.Groups)))
+	for _, group := range raw.SelectorsInfo.Groups {
+		w.writeUsize(group.Start)
+		w.writeUsize(group.End)
+	}
+	w.writeUsize(common.QuotientDegreeFactor)
+	w.writeUsize(common.NumGateConstraints)
+	w.writeUsize(common.NumConstants)
+	w.writeUsize(common.NumPublicInputs)
+	w.writeUsizeVec(common.KIs)
+	w.writeUsize(common.NumPartialProducts)
+	w.writeUsize(common.NumLookupPolys)
+	w.writeUsize(raw.NumLookupSelectors)
+	w.writeUsize(0)
+
+	w.writeUsize(uint64(len(common.GateIds)))
+	for _, gateId := range common.GateIds {
+		w.writeGate(gateId)
+	}
+	return w
+}
+
+func TestDecodeCommonCircuitData(t *testing.T) {
+	path := "../testdata/decode_block/common_circuit_data.json"
+	expected := ReadCommonCircuitData(path)
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw CommonCircuitDataRaw
+	if err := json.Unmarshal(rawBytes, &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	serialized := writeCommonCircuitData(raw, expected)
+	common, err := DecodeCommonCircuitData(serialized, DefaultGateSerializer())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedGates, err := gates.DefaultGateRegistry.GatesFromIds(expected.GateIds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedGates, common.Gates) {
+		t.Fatalf("expected gates %v, got %v", expectedGates, common.Gates)
+	}
+	createdGates, err := common.CreateGates(nil)
+	if err != nil || !reflect.DeepEqual(common.Gates, createdGates) {
+		t.Fatalf("CreateGates should return the decoded gates, got %v, %v", createdGates, err)
+	}
+
+	common.Gates = nil
+	common.GateIds = expected.GateIds
+	common.Luts = nil
+	if !reflect.DeepEqual(expected, common) {
+		t.Fatalf("expected %+v, got %+v", expected, common)
+	}
+
+	if _, err := DecodeCommonCircuitData(serialized[:len(serialized)-1], DefaultGateSerializer()); err == nil {
+		t.Fatal("expected an error reading truncated common circuit data")
+	}
+	if _, err := DecodeCommonCircuitData(append(serialized, 0), DefaultGateSerializer()); err == nil {
+		t.Fatal("expected an error reading trailing bytes")
+	}
+}
+
+// Returns the path of a file written by one of plonky2's to_bytes methods for decode_block, next to
+// its JSON serialization, skipping the test if it isn't committed.
+func plonky2BytesFile(t *testing.T, name string) string {
+	path := filepath.Join("../testdata/decode_block", name)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		t.Skipf("no plonky2 bytes at %s", path)
+	}
+	return path
+}
+
+// Reads decode_block's common circuit data serialized by plonky2's CommonCircuitData::to_bytes with
+// its DefaultGateSerializer, whose gates DefaultGateDecoders must decode into the gates of the JSON
+// serialization.
+func TestReadPlonky2BytesCommonCircuitData(t *testing.T) {
+	path := plonky2BytesFile(t, "common_circuit_data.bin")
+	expected := ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	common, err := ReadCommonCircuitDataBinary(path, DefaultGateSerializer())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedGates, err := gates.DefaultGateRegistry.GatesFromIds(expected.GateIds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedGates, common.Gates) {
+		t.Fatalf("expected gates %v, got %v", expectedGates, common.Gates)
+	}
+
+	common.Gates = nil
+	common.GateIds = expected.GateIds
+	// Circuits without lookups have no lookup tables in JSON.
+	if len(expected.Luts) == 0 && len(common.Luts) == 0 {
+		common.Luts = expected.Luts
+	}
+	if !reflect.DeepEqual(expected, common) {
+		t.Fatalf("expected %+v, got %+v", expected, common)
+	}
+}
+
+func TestGateSerializerLookupGates(t *testing.T) {
+	var lutHash [32]uint8
+	for i := range lutHash {
+		lutHash[i] = uint8(i)
+	}
+	common := CommonCircuitData{Luts: []LookupTable{{{0, 1}, {1, 2}}}}
+
+	var w bufferWriter
+	w = binary.LittleEndian.AppendUint32(w, 6)
+	w.writeUsize(40)
+	w.writeUsize(0)
+	w = append(w, lutHash[:]...)
+	w = binary.LittleEndian.AppendUint32(w, 7)
+	w.writeUsize(26)
+	w.writeUsize(11)
+	w.writeUsize(0)
+	w = append(w, lutHash[:]...)
+	// Out of range lookup table.
+	w = binary.LittleEndian.AppendUint32(w, 6)
+	w.writeUsize(40)
+	w.writeUsize(1)
+	w = append(w, lutHash[:]...)
+
+	serializer := DefaultGateSerializer()
+	buf := NewBuffer(w)
+	expectedGates := []gates.Gate{
+		gates.NewLookupGate(40, lutHash),
+		gates.NewLookupTableGate(26, lutHash, 11),
+	}
+	for _, expected := range expectedGates {
+		gate, err := serializer.ReadGate(buf, &common)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, gate) {
+			t.Fatalf("expected %v, got %v", expected, gate)
+		}
+	}
+
+	if _, err := serializer.ReadGate(buf, &common); err == nil || !strings.Contains(err.Error(), "lookup table 1") {
+		t.Fatalf("expected an out of range lookup table error, got %v", err)
+	}
+	unknownTag := binary.LittleEndian.AppendUint32(nil, 16)
+	if _, err := serializer.ReadGate(NewBuffer(unknownTag), &common); err == nil || !strings.Contains(err.Error(), "tag 16") {
+		t.Fatalf("expected an unsupported tag error, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"slices"
+)
+
+// A proof serialized by plonky2's CompressedProofWithPublicInputs::to_bytes. Its query rounds leave
+// out the Merkle siblings and FRI evaluations a verifier can compute from the rest of the proof,
+// which native.DecompressProofWithPublicInputs restores.
+type CompressedProofWithPublicInputsRaw struct {
+	// The proof without its query rounds: Proof.OpeningProof.QueryRoundProofs is empty.
+	ProofWithPublicInputsRaw
+	QueryRounds CompressedFriQueryRoundsRaw
+}
+
+// Mirrors plonky2's CompressedFriQueryRounds.
+type CompressedFriQueryRoundsRaw struct {
+	// The index queried by each round.
+	Indices []uint64
+	// The initial trees proofs of each distinct index. Their Merkle proofs only hold the siblings
+	// the proofs of the previous indices, in the order of Indices, don't determine.
+	InitialTreesProofs map[uint64][]EvalProofRaw
+	// For each reduction step, the query steps of each distinct coset index.
+	Steps []map[uint64]CompressedFriQueryStepRaw
+}
+
+// A query step without the evaluation at the queried index within the coset, which is the
+// evaluation computed by the previous step. Its Merkle proof is compressed like the initial ones.
+type CompressedFriQueryStepRaw struct {
+	Evals    [][]uint64
+	Siblings []HashOutRaw
+}
+
+// Reads a proof serialized by plonky2's CompressedProofWithPublicInputs::to_bytes, see
+// DecodeProofWithPublicInputs.
+func DecodeCompressedProofWithPublicInputs(rawBytes []byte, common *CommonCircuitData) (CompressedProofWithPublicInputsRaw, error) {
+	var raw CompressedProofWithPublicInputsRaw
+	var err error
+
+	buf := NewBuffer(rawBytes)
+	raw.ProofWithPublicInputsRaw, err = readProofWithPublicInputs(buf, common, &raw.QueryRounds)
+	if err != nil {
+		return CompressedProofWithPublicInputsRaw{}, fmt.Errorf("reading the compressed proof: %w", err)
+	}
+	if buf.Len() != 0 {
+		return CompressedProofWithPublicInputsRaw{}, fmt.Errorf("reading the compressed proof: %d trailing bytes", buf.Len())
+	}
+	return raw, nil
+}
+
+func ReadCompressedProofWithPublicInputsBinary(path string, common *CommonCircuitData) (CompressedProofWithPublicInputsRaw, error) {
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		return CompressedProofWithPublicInputsRaw{}, err
+	}
+	return DecodeCompressedProofWithPublicInputs(rawBytes, common)
+}
+
+// Mirrors plonky2's Buffer::read_compressed_fri_query_rounds. The indices are written as u32s,
+// followed by the initial trees proofs of the sorted distinct indices and, for each reduction step,
+// the query steps of the sorted distinct coset indices.
+func readCompressedFriQueryRounds(buf *Buffer, common *CommonCircuitData) (CompressedFriQueryRoundsRaw, error) {
+	var rounds CompressedFriQueryRoundsRaw
+
+	rounds.Indices = make([]uint64, common.Config.FriConfig.NumQueryRounds)
+	for i := range rounds.Indices {
+		index, err := buf.ReadU32()
+		if err != nil {
+			return rounds, err
+		}
+		rounds.Indices[i] = uint64(index)
+	}
+
+	indices := slices.Clone(rounds.Indices)
+	slices.Sort(indices)
+	indices = slices.Compact(indices)
+
+	rounds.InitialTreesProofs = make(map[uint64][]EvalProofRaw, len(indices))
+	for _, index := range indices {
+		evalsProofs, err := readInitialTreesProof(buf, common)
+		if err != nil {
+			return rounds, err
+		}
+		rounds.InitialTreesProofs[index] = evalsProofs
+	}
+
+	rounds.Steps = make([]map[uint64]CompressedFriQueryStepRaw, len(common.FriParams.ReductionArityBits))
+	for i, arityBits := range common.FriParams.ReductionArityBits {
+		for j := range indices {
+			indices[j] >>= arityBits
+		}
+		indices = slices.Compact(indices)
+
+		rounds.Steps[i] = make(map[uint64]CompressedFriQueryStepRaw, len(indices))
+		for _, index := range indices {
+			var step CompressedFriQueryStepRaw
+			var err error
+			if step.Evals, err = readFieldExtVec(buf, 1<<arityBits-1); err != nil {
+				return rounds, err
+			}
+			if step.Siblings, err = readMerkleProof(buf, common.Hasher); err != nil {
+				return rounds, err
+			}
+			rounds.Steps[i][index] = step
+		}
+	}
+
+	return rounds, nil
+}
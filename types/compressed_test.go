@@ -0,0 +1,75 @@
+package types
+
+import (
+	"encoding/binary"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// Writes the query rounds like plonky2's Buffer::write_compressed_fri_query_rounds.
+func (w *bufferWriter) writeCompressedFriQueryRounds(rounds CompressedFriQueryRoundsRaw, hasher HasherType) {
+	for _, index := range rounds.Indices {
+		*w = binary.LittleEndian.AppendUint32(*w, uint32(index))
+	}
+	for _, index := range slices.Sorted(maps.Keys(rounds.InitialTreesProofs)) {
+		for _, evalsProof := range rounds.InitialTreesProofs[index] {
+			w.writeFieldVec(evalsProof.LeafElements)
+			w.writeMerkleProof(evalsProof.MerkleProof.Hash, hasher)
+		}
+	}
+	for _, steps := range rounds.Steps {
+		for _, index := range slices.Sorted(maps.Keys(steps)) {
+			w.writeFieldExtVec(steps[index].Evals)
+			w.writeMerkleProof(steps[index].Siblings, hasher)
+		}
+	}
+}
+
+func TestDecodeCompressedProofWithPublicInputs(t *testing.T) {
+	common := ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	proof := ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json")
+	proof.Proof.Openings.LookupZs = [][]uint64{}
+	proof.Proof.Openings.LookupZsNext = [][]uint64{}
+
+	// Query rounds with repeated indices and cosets, whose Merkle proofs don't need to be valid
+	// for the encoding.
+	var expected CompressedProofWithPublicInputsRaw
+	expected.ProofWithPublicInputsRaw = proof
+	expected.Proof.OpeningProof.QueryRoundProofs = nil
+	rounds := &expected.QueryRounds
+	rounds.InitialTreesProofs = map[uint64][]EvalProofRaw{}
+	rounds.Steps = make([]map[uint64]CompressedFriQueryStepRaw, len(common.FriParams.ReductionArityBits))
+	for i := range rounds.Steps {
+		rounds.Steps[i] = map[uint64]CompressedFriQueryStepRaw{}
+	}
+	for i, round := range proof.Proof.OpeningProof.QueryRoundProofs {
+		index := uint64(i%5) * 1000003
+		rounds.Indices = append(rounds.Indices, index)
+		rounds.InitialTreesProofs[index] = round.InitialTreesProof.EvalsProofs
+		for j, arityBits := range common.FriParams.ReductionArityBits {
+			index >>= arityBits
+			rounds.Steps[j][index] = CompressedFriQueryStepRaw{
+				Evals:    round.Steps[j].Evals[1:],
+				Siblings: round.Steps[j].MerkleProof.Siblings[i%2:],
+			}
+		}
+	}
+
+	serialized := writeProofWithPublicInputs(expected.ProofWithPublicInputsRaw, common.Hasher, rounds)
+	raw, err := DecodeCompressedProofWithPublicInputs(serialized, &common)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, raw) {
+		t.Fatal("the decoded compressed proof differs from the encoded one")
+	}
+
+	if _, err := DecodeCompressedProofWithPublicInputs(serialized[:len(serialized)-1], &common); err == nil {
+		t.Fatal("expected an error reading a truncated compressed proof")
+	}
+	if _, err := DecodeProofWithPublicInputs(serialized, &common); err == nil {
+		t.Fatal("expected an error reading a compressed proof as an uncompressed one")
+	}
+}
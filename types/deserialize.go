@@ -2,15 +2,20 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"math/big"
 	"os"
+	"slices"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
 
 type ProofWithPublicInputsRaw struct {
 	Proof struct {
-		WiresCap                  []string `json:"wires_cap"`
-		PlonkZsPartialProductsCap []string `json:"plonk_zs_partial_products_cap"`
-		QuotientPolysCap          []string `json:"quotient_polys_cap"`
+		WiresCap                  []HashOutRaw `json:"wires_cap"`
+		PlonkZsPartialProductsCap []HashOutRaw `json:"plonk_zs_partial_products_cap"`
+		QuotientPolysCap          []HashOutRaw `json:"quotient_polys_cap"`
 		Openings                  struct {
 			Constants       [][]uint64 `json:"constants"`
 			PlonkSigmas     [][]uint64 `json:"plonk_sigmas"`
@@ -19,9 +24,11 @@ type ProofWithPublicInputsRaw struct {
 			PlonkZsNext     [][]uint64 `json:"plonk_zs_next"`
 			PartialProducts [][]uint64 `json:"partial_products"`
 			QuotientPolys   [][]uint64 `json:"quotient_polys"`
+			LookupZs        [][]uint64 `json:"lookup_zs"`
+			LookupZsNext    [][]uint64 `json:"lookup_zs_next"`
 		} `json:"openings"`
 		OpeningProof struct {
-			CommitPhaseMerkleCaps [][]string `json:"commit_phase_merkle_caps"`
+			CommitPhaseMerkleCaps [][]HashOutRaw `json:"commit_phase_merkle_caps"`
 			QueryRoundProofs      []struct {
 				InitialTreesProof struct {
 					EvalsProofs []EvalProofRaw `json:"evals_proofs"`
@@ -29,7 +36,7 @@ type ProofWithPublicInputsRaw struct {
 				Steps []struct {
 					Evals       [][]uint64 `json:"evals"`
 					MerkleProof struct {
-						Siblings []string `json:"siblings"`
+						Siblings []HashOutRaw `json:"siblings"`
 					} `json:"merkle_proof"`
 				} `json:"steps"`
 			} `json:"query_round_proofs"`
@@ -51,13 +58,54 @@ func (e *EvalProofRaw) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &[]interface{}{&e.LeafElements, &e.MerkleProof})
 }
 
+// A serialized Merkle digest. PoseidonBN254 digests are serialized as a decimal string,
+// PoseidonGoldilocks digests as an object holding their four field elements and Keccak digests as
+// an array of bytes.
+type HashOutRaw struct {
+	Elements []*big.Int
+}
+
+func (h *HashOutRaw) UnmarshalJSON(data []byte) error {
+	var bn254Hash string
+	if err := json.Unmarshal(data, &bn254Hash); err == nil {
+		element, ok := new(big.Int).SetString(bn254Hash, 10)
+		if !ok {
+			return fmt.Errorf("invalid BN254 hash %q", bn254Hash)
+		}
+		h.Elements = []*big.Int{element}
+		return nil
+	}
+
+	var keccakHash []uint8
+	if err := json.Unmarshal(data, &keccakHash); err == nil {
+		h.Elements = make([]*big.Int, len(keccakHash))
+		for i, element := range keccakHash {
+			h.Elements[i] = new(big.Int).SetUint64(uint64(element))
+		}
+		return nil
+	}
+
+	var goldilocksHash struct {
+		Elements []uint64 `json:"elements"`
+	}
+	if err := json.Unmarshal(data, &goldilocksHash); err != nil {
+		return err
+	}
+
+	h.Elements = make([]*big.Int, len(goldilocksHash.Elements))
+	for i, element := range goldilocksHash.Elements {
+		h.Elements[i] = new(big.Int).SetUint64(element)
+	}
+	return nil
+}
+
 type MerkleProofRaw struct {
-	Hash []string
+	Hash []HashOutRaw
 }
 
 func (m *MerkleProofRaw) UnmarshalJSON(data []byte) error {
 	type SiblingObject struct {
-		Siblings []string // "siblings"
+		Siblings []HashOutRaw // "siblings"
 	}
 
 	var siblings SiblingObject
@@ -65,7 +113,7 @@ func (m *MerkleProofRaw) UnmarshalJSON(data []byte) error {
 		panic(err)
 	}
 
-	m.Hash = make([]string, len(siblings.Siblings))
+	m.Hash = make([]HashOutRaw, len(siblings.Siblings))
 	copy(m.Hash[:], siblings.Siblings)
 
 	return nil
@@ -75,6 +123,7 @@ type ProofChallengesRaw struct {
 	PlonkBetas    []uint64 `json:"plonk_betas"`
 	PlonkGammas   []uint64 `json:"plonk_gammas"`
 	PlonkAlphas   []uint64 `json:"plonk_alphas"`
+	PlonkDeltas   []uint64 `json:"plonk_deltas"`
 	PlonkZeta     []uint64 `json:"plonk_zeta"`
 	FriChallenges struct {
 		FriAlpha        []uint64   `json:"fri_alpha"`
@@ -85,8 +134,8 @@ type ProofChallengesRaw struct {
 }
 
 type VerifierOnlyCircuitDataRaw struct {
-	ConstantsSigmasCap []string `json:"constants_sigmas_cap"`
-	CircuitDigest      string   `json:"circuit_digest"`
+	ConstantsSigmasCap []HashOutRaw `json:"constants_sigmas_cap"`
+	CircuitDigest      HashOutRaw   `json:"circuit_digest"`
 }
 
 func ReadProofWithPublicInputs(path string) ProofWithPublicInputsRaw {
@@ -144,3 +193,317 @@ func ReadVerifierOnlyCircuitDataBytes(rawBytes []byte) VerifierOnlyCircuitDataRa
 
 	return raw
 }
+
+func ReadProofChallenges(path string) ProofChallengesRaw {
+	jsonFile, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+
+	defer jsonFile.Close()
+	rawBytes, _ := io.ReadAll(jsonFile)
+
+	var raw ProofChallengesRaw
+	err = json.Unmarshal(rawBytes, &raw)
+	if err != nil {
+		panic(err)
+	}
+
+	return raw
+}
+
+// Reads a proof serialized by plonky2's ProofWithPublicInputs::to_bytes. The encoding doesn't hold
+// the lengths of most vectors, which are taken from common, as well as the digest size of
+// common.Hasher.
+func DecodeProofWithPublicInputs(rawBytes []byte, common *CommonCircuitData) (ProofWithPublicInputsRaw, error) {
+	buf := NewBuffer(rawBytes)
+	raw, err := readProofWithPublicInputs(buf, common, nil)
+	if err != nil {
+		return ProofWithPublicInputsRaw{}, fmt.Errorf("reading the proof: %w", err)
+	}
+	if buf.Len() != 0 {
+		return ProofWithPublicInputsRaw{}, fmt.Errorf("reading the proof: %d trailing bytes", buf.Len())
+	}
+	return raw, nil
+}
+
+func ReadProofWithPublicInputsBinary(path string, common *CommonCircuitData) (ProofWithPublicInputsRaw, error) {
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		return ProofWithPublicInputsRaw{}, err
+	}
+	return DecodeProofWithPublicInputs(rawBytes, common)
+}
+
+// Reads verifier data serialized by plonky2's VerifierOnlyCircuitData::to_bytes, whose digests
+// have the size of common.Hasher.
+func DecodeVerifierOnlyCircuitData(rawBytes []byte, common *CommonCircuitData) (VerifierOnlyCircuitDataRaw, error) {
+	buf := NewBuffer(rawBytes)
+	raw, err := readVerifierOnlyCircuitData(buf, common)
+	if err != nil {
+		return VerifierOnlyCircuitDataRaw{}, fmt.Errorf("reading the verifier only circuit data: %w", err)
+	}
+	if buf.Len() != 0 {
+		return VerifierOnlyCircuitDataRaw{}, fmt.Errorf("reading the verifier only circuit data: %d trailing bytes", buf.Len())
+	}
+	return raw, nil
+}
+
+func ReadVerifierOnlyCircuitDataBinary(path string, common *CommonCircuitData) (VerifierOnlyCircuitDataRaw, error) {
+	rawBytes, err := os.ReadFile(path)
+	if err != nil {
+		return VerifierOnlyCircuitDataRaw{}, err
+	}
+	return DecodeVerifierOnlyCircuitData(rawBytes, common)
+}
+
+// Reads a digest, laid out like the JSON ones: PoseidonBN254 digests are a little endian BN254
+// element, PoseidonGoldilocks digests four Goldilocks elements and Keccak digests 25 bytes.
+func readHash(buf *Buffer, hasher HasherType) (HashOutRaw, error) {
+	switch hasher {
+	case PoseidonBN254Hash, PoseidonBN254EmulatedHash:
+		bytes, err := buf.ReadBytes(32)
+		if err != nil {
+			return HashOutRaw{}, err
+		}
+		element := new(big.Int).SetBytes(reverse(bytes))
+		if element.Cmp(fr.Modulus()) >= 0 {
+			return HashOutRaw{}, fmt.Errorf("BN254 digest %v is not canonical", element)
+		}
+		return HashOutRaw{Elements: []*big.Int{element}}, nil
+	case PoseidonGoldilocksHash:
+		elements, err := buf.ReadFieldVec(hasher.HashOutLen())
+		if err != nil {
+			return HashOutRaw{}, err
+		}
+		hash := HashOutRaw{Elements: make([]*big.Int, len(elements))}
+		for i := range elements {
+			hash.Elements[i] = new(big.Int).SetUint64(elements[i].Uint64())
+		}
+		return hash, nil
+	case KeccakHash:
+		bytes, err := buf.ReadBytes(int(hasher.HashOutLen()))
+		if err != nil {
+			return HashOutRaw{}, err
+		}
+		hash := HashOutRaw{Elements: make([]*big.Int, len(bytes))}
+		for i := range bytes {
+			hash.Elements[i] = new(big.Int).SetUint64(uint64(bytes[i]))
+		}
+		return hash, nil
+	default:
+		return HashOutRaw{}, fmt.Errorf("unknown hasher type %d", hasher)
+	}
+}
+
+func reverse(bytes []byte) []byte {
+	reversed := make([]byte, len(bytes))
+	for i := range bytes {
+		reversed[len(bytes)-1-i] = bytes[i]
+	}
+	return reversed
+}
+
+// Reads the 2^capHeight digests of a Merkle cap.
+func readMerkleCap(buf *Buffer, hasher HasherType, capHeight uint64) ([]HashOutRaw, error) {
+	if capHeight >= 32 {
+		return nil, fmt.Errorf("cap height %d is too large", capHeight)
+	}
+
+	cap := make([]HashOutRaw, 1<<capHeight)
+	for i := range cap {
+		var err error
+		if cap[i], err = readHash(buf, hasher); err != nil {
+			return nil, err
+		}
+	}
+	return cap, nil
+}
+
+// Reads the siblings of a Merkle proof, prefixed by their number as a u8.
+func readMerkleProof(buf *Buffer, hasher HasherType) ([]HashOutRaw, error) {
+	length, err := buf.ReadU8()
+	if err != nil {
+		return nil, err
+	}
+
+	siblings := make([]HashOutRaw, length)
+	for i := range siblings {
+		if siblings[i], err = readHash(buf, hasher); err != nil {
+			return nil, err
+		}
+	}
+	return siblings, nil
+}
+
+func readFieldVec(buf *Buffer, length uint64) ([]uint64, error) {
+	elements, err := buf.ReadFieldVec(length)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]uint64, len(elements))
+	for i := range elements {
+		v[i] = elements[i].Uint64()
+	}
+	return v, nil
+}
+
+// Reads length quadratic extension elements, as pairs of Goldilocks elements.
+func readFieldExtVec(buf *Buffer, length uint64) ([][]uint64, error) {
+	elements, err := readFieldVec(buf, 2*length)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([][]uint64, length)
+	for i := range v {
+		v[i] = elements[2*i : 2*i+2 : 2*i+2]
+	}
+	return v, nil
+}
+
+// Mirrors plonky2's Buffer::read_proof_with_public_inputs, or read_compressed_proof_with_public_inputs
+// if compressedQueryRounds isn't nil, in which case the query rounds are read into it and the proof
+// has none.
+func readProofWithPublicInputs(
+	buf *Buffer,
+	common *CommonCircuitData,
+	compressedQueryRounds *CompressedFriQueryRoundsRaw,
+) (ProofWithPublicInputsRaw, error) {
+	var raw ProofWithPublicInputsRaw
+	var err error
+	proof := &raw.Proof
+	hasher := common.Hasher
+	config := common.Config
+	capHeight := config.FriConfig.CapHeight
+
+	for _, cap := range []*[]HashOutRaw{&proof.WiresCap, &proof.PlonkZsPartialProductsCap, &proof.QuotientPolysCap} {
+		if *cap, err = readMerkleCap(buf, hasher, capHeight); err != nil {
+			return raw, err
+		}
+	}
+
+	openings := &proof.Openings
+	numChallenges := config.NumChallenges
+	for _, opening := range []struct {
+		evals  *[][]uint64
+		length uint64
+	}{
+		{&openings.Constants, common.NumConstants},
+		{&openings.PlonkSigmas, config.NumRoutedWires},
+		{&openings.Wires, config.NumWires},
+		{&openings.PlonkZs, numChallenges},
+		{&openings.PlonkZsNext, numChallenges},
+		{&openings.LookupZs, numChallenges * common.NumLookupPolys},
+		{&openings.LookupZsNext, numChallenges * common.NumLookupPolys},
+		{&openings.PartialProducts, numChallenges * common.NumPartialProducts},
+		{&openings.QuotientPolys, numChallenges * common.QuotientDegreeFactor},
+	} {
+		if *opening.evals, err = readFieldExtVec(buf, opening.length); err != nil {
+			return raw, err
+		}
+	}
+
+	friParams := common.FriParams
+	openingProof := &proof.OpeningProof
+	openingProof.CommitPhaseMerkleCaps = make([][]HashOutRaw, len(friParams.ReductionArityBits))
+	for i := range openingProof.CommitPhaseMerkleCaps {
+		if openingProof.CommitPhaseMerkleCaps[i], err = readMerkleCap(buf, hasher, capHeight); err != nil {
+			return raw, err
+		}
+	}
+
+	if compressedQueryRounds != nil {
+		if *compressedQueryRounds, err = readCompressedFriQueryRounds(buf, common); err != nil {
+			return raw, err
+		}
+	} else {
+		// The query rounds and their steps are anonymous structs, allocated with slices.Grow.
+		numQueryRounds := int(friParams.Config.NumQueryRounds)
+		openingProof.QueryRoundProofs = slices.Grow(openingProof.QueryRoundProofs, numQueryRounds)[:numQueryRounds]
+		for i := range openingProof.QueryRoundProofs {
+			round := &openingProof.QueryRoundProofs[i]
+			if round.InitialTreesProof.EvalsProofs, err = readInitialTreesProof(buf, common); err != nil {
+				return raw, err
+			}
+
+			numSteps := len(friParams.ReductionArityBits)
+			round.Steps = slices.Grow(round.Steps, numSteps)[:numSteps]
+			for j, arityBits := range friParams.ReductionArityBits {
+				step := &round.Steps[j]
+				if step.Evals, err = readFieldExtVec(buf, 1<<arityBits); err != nil {
+					return raw, err
+				}
+				if step.MerkleProof.Siblings, err = readMerkleProof(buf, hasher); err != nil {
+					return raw, err
+				}
+			}
+		}
+	}
+
+	if openingProof.FinalPoly.Coeffs, err = readFieldExtVec(buf, uint64(friParams.FinalPolyLen())); err != nil {
+		return raw, err
+	}
+	powWitness, err := buf.ReadField()
+	if err != nil {
+		return raw, err
+	}
+	openingProof.PowWitness = powWitness.Uint64()
+
+	numPublicInputs, err := buf.ReadUsize()
+	if err != nil {
+		return raw, err
+	}
+	if numPublicInputs != common.NumPublicInputs {
+		return raw, fmt.Errorf("%d public inputs, the circuit has %d", numPublicInputs, common.NumPublicInputs)
+	}
+	raw.PublicInputs, err = readFieldVec(buf, numPublicInputs)
+	return raw, err
+}
+
+// Reads the evaluations and Merkle proofs of a query in the constants_sigmas, wires,
+// zs_partial_products and quotient oracles.
+func readInitialTreesProof(buf *Buffer, common *CommonCircuitData) ([]EvalProofRaw, error) {
+	config := common.Config
+	numChallenges := config.NumChallenges
+	saltSize := common.FriParams.SaltSize(true)
+
+	// The leaf sizes of the oracles, see variables.NewFriProof.
+	oracleLeafSizes := []uint64{
+		common.NumConstants + config.NumRoutedWires,
+		config.NumWires + saltSize,
+		numChallenges*(1+common.NumPartialProducts+common.NumLookupPolys) + saltSize,
+		numChallenges*common.QuotientDegreeFactor + saltSize,
+	}
+
+	evalsProofs := make([]EvalProofRaw, len(oracleLeafSizes))
+	for i, leafSize := range oracleLeafSizes {
+		var err error
+		if evalsProofs[i].LeafElements, err = readFieldVec(buf, leafSize); err != nil {
+			return nil, err
+		}
+		if evalsProofs[i].MerkleProof.Hash, err = readMerkleProof(buf, common.Hasher); err != nil {
+			return nil, err
+		}
+	}
+	return evalsProofs, nil
+}
+
+// Mirrors plonky2's Buffer::read_verifier_only_circuit_data.
+func readVerifierOnlyCircuitData(buf *Buffer, common *CommonCircuitData) (VerifierOnlyCircuitDataRaw, error) {
+	var raw VerifierOnlyCircuitDataRaw
+
+	capHeight, err := buf.ReadUsize()
+	if err != nil {
+		return raw, err
+	}
+	if capHeight != common.Config.FriConfig.CapHeight {
+		return raw, fmt.Errorf("cap height %d, the circuit has %d", capHeight, common.Config.FriConfig.CapHeight)
+	}
+	if raw.ConstantsSigmasCap, err = readMerkleCap(buf, common.Hasher, capHeight); err != nil {
+		return raw, err
+	}
+	raw.CircuitDigest, err = readHash(buf, common.Hasher)
+	return raw, err
+}
@@ -1,7 +1,13 @@
 package types
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"reflect"
 	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 )
 
 func TestReadProofWithPublicInputs(t *testing.T) {
@@ -11,3 +17,233 @@ func TestReadProofWithPublicInputs(t *testing.T) {
 func TestReadVerifierOnlyCircuitData(t *testing.T) {
 	ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json")
 }
+
+func TestHashOutRawUnmarshalJSON(t *testing.T) {
+	var bn254Hash HashOutRaw
+	if err := json.Unmarshal([]byte(`"12345678901234567890123456789"`), &bn254Hash); err != nil {
+		t.Fatal(err)
+	}
+	if len(bn254Hash.Elements) != 1 || bn254Hash.Elements[0].String() != "12345678901234567890123456789" {
+		t.Fatalf("unexpected BN254 hash %v", bn254Hash.Elements)
+	}
+
+	var goldilocksHash HashOutRaw
+	if err := json.Unmarshal([]byte(`{"elements":[1,2,3,18446744069414584320]}`), &goldilocksHash); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"1", "2", "3", "18446744069414584320"}
+	if len(goldilocksHash.Elements) != len(expected) {
+		t.Fatalf("unexpected Goldilocks hash %v", goldilocksHash.Elements)
+	}
+	for i := range expected {
+		if goldilocksHash.Elements[i].String() != expected[i] {
+			t.Fatalf("unexpected Goldilocks hash %v", goldilocksHash.Elements)
+		}
+	}
+
+	var keccakHash HashOutRaw
+	if err := json.Unmarshal([]byte(`[0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22,23,255]`), &keccakHash); err != nil {
+		t.Fatal(err)
+	}
+	if len(keccakHash.Elements) != 25 || keccakHash.Elements[1].Uint64() != 1 || keccakHash.Elements[24].Uint64() != 255 {
+		t.Fatalf("unexpected Keccak hash %v", keccakHash.Elements)
+	}
+
+	var invalidHash HashOutRaw
+	if err := json.Unmarshal([]byte(`"not a number"`), &invalidHash); err == nil {
+		t.Fatal("expected an error for an invalid BN254 hash")
+	}
+}
+
+func (w *bufferWriter) writeHash(hash HashOutRaw, hasher HasherType) {
+	switch hasher {
+	case PoseidonBN254Hash:
+		var bytes [32]byte
+		hash.Elements[0].FillBytes(bytes[:])
+		*w = append(*w, reverse(bytes[:])...)
+	case PoseidonGoldilocksHash:
+		for _, element := range hash.Elements {
+			w.writeUsize(element.Uint64())
+		}
+	case KeccakHash:
+		for _, element := range hash.Elements {
+			*w = append(*w, uint8(element.Uint64()))
+		}
+	}
+}
+
+func (w *bufferWriter) writeHashes(hashes []HashOutRaw, hasher HasherType) {
+	for _, hash := range hashes {
+		w.writeHash(hash, hasher)
+	}
+}
+
+func (w *bufferWriter) writeMerkleProof(siblings []HashOutRaw, hasher HasherType) {
+	*w = append(*w, uint8(len(siblings)))
+	w.writeHashes(siblings, hasher)
+}
+
+func (w *bufferWriter) writeFieldVec(v []uint64) {
+	for _, x := range v {
+		w.writeUsize(x)
+	}
+}
+
+func (w *bufferWriter) writeFieldExtVec(v [][]uint64) {
+	for _, x := range v {
+		w.writeFieldVec(x)
+	}
+}
+
+// Writes the proof like plonky2's Buffer::write_proof_with_public_inputs, or like
+// write_compressed_proof_with_public_inputs with the given query rounds if they aren't nil.
+func writeProofWithPublicInputs(raw ProofWithPublicInputsRaw, hasher HasherType, compressed *CompressedFriQueryRoundsRaw) []byte {
+	var w bufferWriter
+	proof := raw.Proof
+	w.writeHashes(proof.WiresCap, hasher)
+	w.writeHashes(proof.PlonkZsPartialProductsCap, hasher)
+	w.writeHashes(proof.QuotientPolysCap, hasher)
+
+	openings := proof.Openings
+	for _, evals := range [][][]uint64{
+		openings.Constants,
+		openings.PlonkSigmas,
+		openings.Wires,
+		openings.PlonkZs,
+		openings.PlonkZsNext,
+		openings.LookupZs,
+		openings.LookupZsNext,
+		openings.PartialProducts,
+		openings.QuotientPolys,
+	} {
+		w.writeFieldExtVec(evals)
+	}
+
+	for _, cap := range proof.OpeningProof.CommitPhaseMerkleCaps {
+		w.writeHashes(cap, hasher)
+	}
+	if compressed != nil {
+		w.writeCompressedFriQueryRounds(*compressed, hasher)
+	}
+	for _, round := range proof.OpeningProof.QueryRoundProofs {
+		for _, evalsProof := range round.InitialTreesProof.EvalsProofs {
+			w.writeFieldVec(evalsProof.LeafElements)
+			w.writeMerkleProof(evalsProof.MerkleProof.Hash, hasher)
+		}
+		for _, step := range round.Steps {
+			w.writeFieldExtVec(step.Evals)
+			w.writeMerkleProof(step.MerkleProof.Siblings, hasher)
+		}
+	}
+	w.writeFieldExtVec(proof.OpeningProof.FinalPoly.Coeffs)
+	w.writeUsize(proof.OpeningProof.PowWitness)
+
+	w.writeUsize(uint64(len(raw.PublicInputs)))
+	w.writeFieldVec(raw.PublicInputs)
+	return w
+}
+
+func TestDecodeProofWithPublicInputs(t *testing.T) {
+	common := ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	expected := ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json")
+	// The JSON proof has no lookup openings, which are empty in the binary one.
+	expected.Proof.Openings.LookupZs = [][]uint64{}
+	expected.Proof.Openings.LookupZsNext = [][]uint64{}
+
+	serialized := writeProofWithPublicInputs(expected, common.Hasher, nil)
+	raw, err := DecodeProofWithPublicInputs(serialized, &common)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, raw) {
+		t.Fatal("the binary proof differs from the JSON one")
+	}
+
+	if _, err := DecodeProofWithPublicInputs(serialized[:len(serialized)-1], &common); err == nil {
+		t.Fatal("expected an error reading a truncated proof")
+	}
+	common.NumPublicInputs++
+	if _, err := DecodeProofWithPublicInputs(serialized, &common); err == nil {
+		t.Fatal("expected an error reading a proof with too few public inputs")
+	}
+}
+
+func TestDecodeVerifierOnlyCircuitData(t *testing.T) {
+	common := ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	expected := ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json")
+
+	var w bufferWriter
+	w.writeUsize(common.Config.FriConfig.CapHeight)
+	w.writeHashes(expected.ConstantsSigmasCap, common.Hasher)
+	w.writeHash(expected.CircuitDigest, common.Hasher)
+
+	raw, err := DecodeVerifierOnlyCircuitData(w, &common)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, raw) {
+		t.Fatal("the binary verifier only circuit data differs from the JSON one")
+	}
+}
+
+func TestReadHash(t *testing.T) {
+	var w bufferWriter
+	w.writeUsize(1)
+	w.writeUsize(2)
+	w.writeUsize(3)
+	w.writeUsize(18446744069414584320)
+	w = append(w, make([]byte, 24)...)
+	w = append(w, 7)
+	// A BN254 digest equal to the modulus.
+	w = append(w, reverse(fr.Modulus().FillBytes(make([]byte, 32)))...)
+
+	buf := NewBuffer(w)
+	goldilocksHash, err := readHash(buf, PoseidonGoldilocksHash)
+	if err != nil || !reflect.DeepEqual(goldilocksHash.Elements, []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), new(big.Int).SetUint64(18446744069414584320)}) {
+		t.Fatalf("unexpected Goldilocks hash %v, %v", goldilocksHash.Elements, err)
+	}
+	keccakHash, err := readHash(buf, KeccakHash)
+	if err != nil || len(keccakHash.Elements) != 25 || keccakHash.Elements[24].Uint64() != 7 {
+		t.Fatalf("unexpected Keccak hash %v, %v", keccakHash.Elements, err)
+	}
+	if _, err := readHash(buf, PoseidonBN254Hash); err == nil {
+		t.Fatal("expected an error reading a non canonical BN254 hash")
+	}
+
+	// Non canonical Goldilocks elements are rejected too.
+	if _, err := readHash(NewBuffer(binary.LittleEndian.AppendUint64(nil, 18446744069414584321)), PoseidonGoldilocksHash); err == nil {
+		t.Fatal("expected an error reading a non canonical Goldilocks element")
+	}
+}
+
+// Reads decode_block's proof and verifier only circuit data serialized by plonky2's
+// ProofWithPublicInputs::to_bytes and VerifierOnlyCircuitData::to_bytes, which must match their JSON
+// serialization.
+func TestReadPlonky2BytesProof(t *testing.T) {
+	proofPath := plonky2BytesFile(t, "proof_with_public_inputs.bin")
+	verifierDataPath := plonky2BytesFile(t, "verifier_only_circuit_data.bin")
+	common := ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+
+	expectedProof := ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json")
+	// Proofs serialized before the lookups have no lookup openings in JSON.
+	if expectedProof.Proof.Openings.LookupZs == nil {
+		expectedProof.Proof.Openings.LookupZs = [][]uint64{}
+		expectedProof.Proof.Openings.LookupZsNext = [][]uint64{}
+	}
+	proof, err := ReadProofWithPublicInputsBinary(proofPath, &common)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedProof, proof) {
+		t.Fatal("the binary proof differs from the JSON one")
+	}
+
+	expectedVerifierData := ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json")
+	verifierData, err := ReadVerifierOnlyCircuitDataBinary(verifierDataPath, &common)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expectedVerifierData, verifierData) {
+		t.Fatal("the binary verifier only circuit data differs from the JSON one")
+	}
+}
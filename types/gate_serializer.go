@@ -0,0 +1,240 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+)
+
+// Reads the parameters a gate wrote with plonky2's Gate::serialize and creates it. common holds the
+// common circuit data read so far, including its lookup tables, which lookup gates refer to.
+type GateDecoder func(buf *Buffer, common *CommonCircuitData) (gates.Gate, error)
+
+// The counterpart of a plonky2 GateSerializer, which writes each gate as a u32 tag, the index of
+// its type in the serializer's list of gate types, followed by the gate's parameters.
+//
+// Only gates are part of the serialized common circuit data: the ids of a plonky2
+// WitnessGeneratorSerializer tag the witness generators of the prover only data, which a verifier
+// never reads.
+type GateSerializer struct {
+	decoders []GateDecoder
+}
+
+// Creates a serializer whose tag i is read with decoders[i], in the order of the gate types given to
+// plonky2's impl_gate_serializer! macro.
+func NewGateSerializer(decoders ...GateDecoder) *GateSerializer {
+	return &GateSerializer{decoders: decoders}
+}
+
+// Returns the decoders of plonky2's DefaultGateSerializer, in its order of gate types. Custom
+// serializers usually extend them, e.g. with a decoder for the Poseidon2Gate of plonky2 forks.
+func DefaultGateDecoders() []GateDecoder {
+	return []GateDecoder{
+		decodeArithmeticGate,
+		decodeArithmeticExtensionGate,
+		decodeBaseSumGate,
+		decodeConstantGate,
+		decodeCosetInterpolationGate,
+		decodeExponentiationGate,
+		decodeLookupGate,
+		decodeLookupTableGate,
+		decodeMulExtensionGate,
+		decodeNoopGate,
+		decodePoseidonMdsGate,
+		decodePoseidonGate,
+		decodePublicInputGate,
+		decodeRandomAccessGate,
+		decodeReducingExtensionGate,
+		decodeReducingGate,
+	}
+}
+
+// The counterpart of plonky2's DefaultGateSerializer.
+func DefaultGateSerializer() *GateSerializer {
+	return NewGateSerializer(DefaultGateDecoders()...)
+}
+
+func (s *GateSerializer) ReadGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	tag, err := buf.ReadU32()
+	if err != nil {
+		return nil, err
+	}
+	if int(tag) >= len(s.decoders) {
+		return nil, fmt.Errorf("unsupported gate tag %d, the serializer has %d gate types", tag, len(s.decoders))
+	}
+
+	gate, err := s.decoders[tag](buf, common)
+	if err != nil {
+		return nil, fmt.Errorf("gate tag %d: %w", tag, err)
+	}
+	return gate, nil
+}
+
+func decodeArithmeticGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numOps, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewArithmeticGate(numOps), nil
+}
+
+func decodeArithmeticExtensionGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numOps, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewArithmeticExtensionGate(numOps), nil
+}
+
+// The DefaultGateSerializer only supports BaseSumGate<2>, the base being a type parameter.
+func decodeBaseSumGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numLimbs, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewBaseSumGate(numLimbs, 2), nil
+}
+
+func decodeConstantGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numConsts, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewConstantGate(numConsts), nil
+}
+
+func decodeCosetInterpolationGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	subgroupBits, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	degree, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	if degree < 2 {
+		return nil, fmt.Errorf("CosetInterpolationGate degree %d should be at least 2", degree)
+	}
+	numWeights, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	barycentricWeights, err := buf.ReadFieldVec(numWeights)
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewCosetInterpolationGate(subgroupBits, degree, barycentricWeights), nil
+}
+
+func decodeExponentiationGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numPowerBits, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewExponentiationGate(numPowerBits), nil
+}
+
+// Lookup gates are serialized with the index of their table in the common data's lookup tables,
+// followed by the table's hash.
+func readLut(buf *Buffer, common *CommonCircuitData) ([32]uint8, error) {
+	var lutHash [32]uint8
+
+	lutIndex, err := buf.ReadUsize()
+	if err != nil {
+		return lutHash, err
+	}
+	if lutIndex >= uint64(len(common.Luts)) {
+		return lutHash, fmt.Errorf("lookup table %d out of range, the circuit has %d", lutIndex, len(common.Luts))
+	}
+
+	hash, err := buf.ReadBytes(len(lutHash))
+	if err != nil {
+		return lutHash, err
+	}
+	copy(lutHash[:], hash)
+	return lutHash, nil
+}
+
+func decodeLookupGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numSlots, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	lutHash, err := readLut(buf, common)
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewLookupGate(numSlots, lutHash), nil
+}
+
+func decodeLookupTableGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numSlots, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	lastLutRow, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	lutHash, err := readLut(buf, common)
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewLookupTableGate(numSlots, lutHash, lastLutRow), nil
+}
+
+func decodeMulExtensionGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numOps, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewMultiplicationExtensionGate(numOps), nil
+}
+
+func decodeNoopGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	return gates.NewNoopGate(), nil
+}
+
+func decodePoseidonMdsGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	return gates.NewPoseidonMdsGate(), nil
+}
+
+func decodePoseidonGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	return gates.NewPoseidonGate(), nil
+}
+
+func decodePublicInputGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	return gates.NewPublicInputGate(), nil
+}
+
+func decodeRandomAccessGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	bits, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	numCopies, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	numExtraConstants, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewRandomAccessGate(bits, numCopies, numExtraConstants), nil
+}
+
+func decodeReducingExtensionGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numCoeffs, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewReducingExtensionGate(numCoeffs), nil
+}
+
+func decodeReducingGate(buf *Buffer, common *CommonCircuitData) (gates.Gate, error) {
+	numCoeffs, err := buf.ReadUsize()
+	if err != nil {
+		return nil, err
+	}
+	return gates.NewReducingGate(numCoeffs), nil
+}
@@ -1,11 +1,148 @@
 package types
 
 import (
+	"fmt"
+
 	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
 )
 
+// The plonky2 FRI reduction strategies. The value of each kind is the tag observed by the
+// challenger before the strategy's parameters.
+type FriReductionStrategyKind uint64
+
+const (
+	FixedReductionStrategy FriReductionStrategyKind = iota
+	ConstantArityBitsReductionStrategy
+	MinSizeReductionStrategy
+)
+
 type FriReductionStrategy struct {
+	Kind FriReductionStrategyKind
+
+	// Fixed(reduction_arity_bits): the exact sequence of reduction arity bits.
+	Fixed []uint64
+	// ConstantArityBits(arity_bits, final_poly_bits)
 	ConstantArityBits []uint64
+	// MinSize(opt_max_arity_bits): nil when no max arity bits is set.
+	MinSize *uint64
+}
+
+// Returns the parameters of the strategy, in the order plonky2 observes them.
+func (s *FriReductionStrategy) Parameters() []uint64 {
+	switch s.Kind {
+	case FixedReductionStrategy:
+		return s.Fixed
+	case ConstantArityBitsReductionStrategy:
+		return s.ConstantArityBits
+	case MinSizeReductionStrategy:
+		if s.MinSize == nil {
+			return []uint64{}
+		}
+		return []uint64{*s.MinSize}
+	default:
+		panic(fmt.Sprintf("unknown FRI reduction strategy kind %d", s.Kind))
+	}
+}
+
+// Returns the arity bits of the FRI reductions of polynomials of degree 2^degreeBits, like plonky2's
+// FriReductionStrategy::reduction_arity_bits.
+func (s *FriReductionStrategy) ReductionArityBits(degreeBits, rateBits, capHeight, numQueryRounds uint64) []uint64 {
+	switch s.Kind {
+	case FixedReductionStrategy:
+		return s.Fixed
+	case ConstantArityBitsReductionStrategy:
+		arityBits, finalPolyBits := s.ConstantArityBits[0], s.ConstantArityBits[1]
+		result := []uint64{}
+		for degreeBits > finalPolyBits && degreeBits+rateBits >= capHeight+arityBits {
+			result = append(result, arityBits)
+			degreeBits -= arityBits
+		}
+		return result
+	case MinSizeReductionStrategy:
+		// 2^4 is the largest arity plonky2 sees in optimal reduction sequences in practice.
+		maxArityBits := uint64(4)
+		if s.MinSize != nil {
+			maxArityBits = *s.MinSize
+		}
+		result, _ := minSizeArityBits(degreeBits, rateBits, numQueryRounds, maxArityBits, []uint64{})
+		return result
+	default:
+		panic(fmt.Sprintf("unknown FRI reduction strategy kind %d", s.Kind))
+	}
+}
+
+// Searches the reduction arity bits starting with prefix which minimize the proof size, as
+// plonky2's min_size_arity_bits_helper. Optimal sequences are non increasing, as larger arities
+// shrink more Merkle proofs when they come first.
+func minSizeArityBits(degreeBits, rateBits, numQueryRounds, maxArityBits uint64, prefix []uint64) ([]uint64, uint64) {
+	currentLayerBits := degreeBits + rateBits
+	for _, arityBits := range prefix {
+		currentLayerBits -= arityBits
+	}
+	if len(prefix) > 0 {
+		maxArityBits = prefix[len(prefix)-1]
+	}
+	maxArityBits = min(maxArityBits, currentLayerBits-rateBits)
+
+	bestArityBits := prefix
+	bestSize := relativeProofSize(degreeBits, rateBits, numQueryRounds, prefix)
+	for nextArityBits := uint64(1); nextArityBits <= maxArityBits; nextArityBits++ {
+		extendedPrefix := append(append([]uint64{}, prefix...), nextArityBits)
+		arityBits, size := minSizeArityBits(degreeBits, rateBits, numQueryRounds, maxArityBits, extendedPrefix)
+		if size < bestSize {
+			bestArityBits, bestSize = arityBits, size
+		}
+	}
+	return bestArityBits, bestSize
+}
+
+// The number of field elements of a FRI proof with the given reduction arity bits, up to the terms
+// that don't depend on them, as plonky2's relative_proof_size.
+func relativeProofSize(degreeBits, rateBits, numQueryRounds uint64, reductionArityBits []uint64) uint64 {
+	const d = 4
+	currentLayerBits := degreeBits + rateBits
+	totalElements := uint64(0)
+	for _, arityBits := range reductionArityBits {
+		// The neighbouring evaluations, which are extension field elements, and the Merkle siblings.
+		totalElements += ((1<<arityBits)-1)*d*numQueryRounds + currentLayerBits*4*numQueryRounds
+		currentLayerBits -= arityBits
+	}
+	// The final polynomial's coefficients.
+	return totalElements + d*(1<<(currentLayerBits-rateBits))
+}
+
+// The hasher of the plonky2 GenericConfig a circuit was built with, which is used to build the
+// proof's Merkle trees and to run the challenger's sponge.
+type HasherType uint64
+
+const (
+	// PoseidonBN254Hash, used by plonky2's PoseidonBN254GoldilocksConfig.
+	PoseidonBN254Hash HasherType = iota
+	// PoseidonHash over the Goldilocks field, used by plonky2's PoseidonGoldilocksConfig.
+	PoseidonGoldilocksHash
+	// KeccakHash<25>, used by plonky2's KeccakGoldilocksConfig.
+	KeccakHash
+	// PoseidonBN254Hash verified with emulated BN254 arithmetic, for circuits compiled over other
+	// scalar fields than BN254's (e.g. BLS12-377 or BLS12-381). Its proofs and serialized digests
+	// are those of PoseidonBN254Hash, but its in-circuit digests are the four 64 bit limbs of the
+	// BN254 element, see variables.EmulateBN254HashOut.
+	PoseidonBN254EmulatedHash
+)
+
+// Returns the number of variables of a digest of the hasher.
+func (h HasherType) HashOutLen() uint64 {
+	switch h {
+	case PoseidonBN254Hash:
+		return 1
+	case PoseidonGoldilocksHash:
+		return 4
+	case KeccakHash:
+		return 25
+	case PoseidonBN254EmulatedHash:
+		return 4
+	default:
+		panic(fmt.Sprintf("unknown hasher type %d", h))
+	}
 }
 
 type FriConfig struct {
@@ -20,6 +157,20 @@ func (fc *FriConfig) Rate() float64 {
 	return 1.0 / float64((uint64(1) << fc.RateBits))
 }
 
+// Returns the parameters of FRI proofs of polynomials of degree 2^degreeBits, like plonky2's
+// FriConfig::fri_params.
+func (fc *FriConfig) FriParams(degreeBits uint64, hiding bool) FriParams {
+	return FriParams{
+		Config:             *fc,
+		Hiding:             hiding,
+		DegreeBits:         degreeBits,
+		ReductionArityBits: fc.ReductionStrategy.ReductionArityBits(degreeBits, fc.RateBits, fc.CapHeight, fc.NumQueryRounds),
+	}
+}
+
+// The number of random elements appended to the Merkle leaves of blinding oracles in hiding proofs.
+const SALT_SIZE = 4
+
 type FriParams struct {
 	Config             FriConfig
 	Hiding             bool
@@ -27,6 +178,15 @@ type FriParams struct {
 	ReductionArityBits []uint64
 }
 
+// Returns the number of salt elements at the end of an oracle's Merkle leaves, which is non zero
+// only for blinding oracles of hiding proofs.
+func (p *FriParams) SaltSize(blinding bool) uint64 {
+	if p.Hiding && blinding {
+		return SALT_SIZE
+	}
+	return 0
+}
+
 func (p *FriParams) TotalArities() int {
 	res := 0
 	for _, b := range p.ReductionArityBits {
@@ -73,10 +233,23 @@ type CircuitConfig struct {
 	FriConfig               FriConfig
 }
 
+// A plonky2 lookup table, as a list of (input, output) pairs.
+type LookupTable [][2]uint16
+
 type CommonCircuitData struct {
-	Config CircuitConfig
+	// Not part of plonky2's serialized common circuit data, so it is left to its default of
+	// PoseidonBN254Hash by ReadCommonCircuitData and must be set for other configs (see the
+	// command line's -hasher).
+	Hasher HasherType
+	// Whether the challenger doesn't observe the FRI config and parameters before the circuit
+	// digest, like plonky2's transcript before they were added to it, e.g. for the proofs of
+	// testdata. Not part of the serialized common circuit data either.
+	LegacyTranscript bool
+	Config           CircuitConfig
 	FriParams
-	GateIds              []string
+	GateIds []string
+	// The gates decoded by DecodeCommonCircuitData, nil if they must be parsed from GateIds.
+	Gates                []gates.Gate `gnark:"-"`
 	SelectorsInfo        gates.SelectorsInfo
 	DegreeBits           uint64
 	QuotientDegreeFactor uint64
@@ -85,4 +258,20 @@ type CommonCircuitData struct {
 	NumPublicInputs      uint64
 	KIs                  []uint64
 	NumPartialProducts   uint64
+	// The number of lookup polynomials per challenge (one RE polynomial followed by the
+	// Sum/LDC polynomials), which is zero for circuits without lookups.
+	NumLookupPolys uint64
+	Luts           []LookupTable
+}
+
+// Returns the gates decoded by DecodeCommonCircuitData, or parses them from GateIds with
+// gateRegistry, gates.DefaultGateRegistry if nil.
+func (c *CommonCircuitData) CreateGates(gateRegistry *gates.GateRegistry) ([]gates.Gate, error) {
+	if c.Gates != nil {
+		return c.Gates, nil
+	}
+	if gateRegistry == nil {
+		gateRegistry = gates.DefaultGateRegistry
+	}
+	return gateRegistry.GatesFromIds(c.GateIds)
 }
@@ -2,7 +2,7 @@ package variables
 
 import (
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
-	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
 )
 
 type Proof struct {
@@ -20,5 +20,32 @@ type ProofWithPublicInputs struct {
 
 type VerifierOnlyCircuitData struct {
 	ConstantSigmasCap FriMerkleCap
-	CircuitDigest     poseidon.BN254HashOut
+	CircuitDigest     HashOut
+}
+
+// Allocates a Proof whose shape matches the proofs generated for commonCircuitData.
+func NewProof(commonCircuitData *types.CommonCircuitData) Proof {
+	hasher := commonCircuitData.Hasher
+	capHeight := commonCircuitData.Config.FriConfig.CapHeight
+	return Proof{
+		WiresCap:                  NewFriMerkleCap(hasher, capHeight),
+		PlonkZsPartialProductsCap: NewFriMerkleCap(hasher, capHeight),
+		QuotientPolysCap:          NewFriMerkleCap(hasher, capHeight),
+		Openings:                  NewOpeningSet(commonCircuitData),
+		OpeningProof:              NewFriProof(commonCircuitData),
+	}
+}
+
+func NewProofWithPublicInputs(commonCircuitData *types.CommonCircuitData) ProofWithPublicInputs {
+	return ProofWithPublicInputs{
+		Proof:        NewProof(commonCircuitData),
+		PublicInputs: make([]gl.Variable, commonCircuitData.NumPublicInputs),
+	}
+}
+
+func NewVerifierOnlyCircuitData(commonCircuitData *types.CommonCircuitData) VerifierOnlyCircuitData {
+	return VerifierOnlyCircuitData{
+		ConstantSigmasCap: NewFriMerkleCap(commonCircuitData.Hasher, commonCircuitData.Config.FriConfig.CapHeight),
+		CircuitDigest:     NewHashOut(commonCircuitData.Hasher),
+	}
 }
@@ -1,33 +1,36 @@
 package variables
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/consensys/gnark/frontend"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
-	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
 	"github.com/elliottech/gnark-plonky2-verifier/types"
 )
 
-func DeserializeMerkleCap(merkleCapRaw []string) FriMerkleCap {
-	n := len(merkleCapRaw)
-	merkleCap := make([]poseidon.BN254HashOut, n)
-	for i := 0; i < n; i++ {
-		capBigInt, _ := new(big.Int).SetString(merkleCapRaw[i], 10)
-		merkleCap[i] = frontend.Variable(capBigInt)
+func DeserializeHashOut(hashOutRaw types.HashOutRaw) HashOut {
+	hashOut := make(HashOut, len(hashOutRaw.Elements))
+	for i, element := range hashOutRaw.Elements {
+		hashOut[i] = frontend.Variable(element)
 	}
-	return merkleCap
+	return hashOut
 }
 
-func DeserializeMerkleProof(merkleProofRaw struct{ Siblings []interface{} }) FriMerkleProof {
-	n := len(merkleProofRaw.Siblings)
-	var mp FriMerkleProof
-	mp.Siblings = make([]poseidon.BN254HashOut, n)
-	for i := 0; i < n; i++ {
-		element := merkleProofRaw.Siblings[i].(struct{ Elements []uint64 })
-		mp.Siblings[i] = gl.Uint64ArrayToVariableArray(element.Elements)
+func DeserializeHashOuts(hashOutsRaw []types.HashOutRaw) []HashOut {
+	hashOuts := make([]HashOut, len(hashOutsRaw))
+	for i, hashOutRaw := range hashOutsRaw {
+		hashOuts[i] = DeserializeHashOut(hashOutRaw)
 	}
-	return mp
+	return hashOuts
+}
+
+func DeserializeMerkleCap(merkleCapRaw []types.HashOutRaw) FriMerkleCap {
+	return DeserializeHashOuts(merkleCapRaw)
+}
+
+func DeserializeMerkleProof(merkleProofRaw types.MerkleProofRaw) FriMerkleProof {
+	return FriMerkleProof{Siblings: DeserializeHashOuts(merkleProofRaw.Hash)}
 }
 
 func DeserializeOpeningSet(openingSetRaw struct {
@@ -38,6 +41,8 @@ func DeserializeOpeningSet(openingSetRaw struct {
 	PlonkZsNext     [][]uint64
 	PartialProducts [][]uint64
 	QuotientPolys   [][]uint64
+	LookupZs        [][]uint64
+	LookupZsNext    [][]uint64
 }) OpeningSet {
 	return OpeningSet{
 		Constants:       gl.Uint64ArrayToQuadraticExtensionArray(openingSetRaw.Constants),
@@ -47,23 +52,13 @@ func DeserializeOpeningSet(openingSetRaw struct {
 		PlonkZsNext:     gl.Uint64ArrayToQuadraticExtensionArray(openingSetRaw.PlonkZsNext),
 		PartialProducts: gl.Uint64ArrayToQuadraticExtensionArray(openingSetRaw.PartialProducts),
 		QuotientPolys:   gl.Uint64ArrayToQuadraticExtensionArray(openingSetRaw.QuotientPolys),
+		LookupZs:        gl.Uint64ArrayToQuadraticExtensionArray(openingSetRaw.LookupZs),
+		LookupZsNext:    gl.Uint64ArrayToQuadraticExtensionArray(openingSetRaw.LookupZsNext),
 	}
 }
 
-func StringArrayToHashBN254Array(rawHashes []string) []poseidon.BN254HashOut {
-	hashes := []poseidon.BN254HashOut{}
-
-	for i := 0; i < len(rawHashes); i++ {
-		hashBigInt, _ := new(big.Int).SetString(rawHashes[i], 10)
-		hashVar := frontend.Variable(hashBigInt)
-		hashes = append(hashes, poseidon.BN254HashOut(hashVar))
-	}
-
-	return hashes
-}
-
 func DeserializeFriProof(openingProofRaw struct {
-	CommitPhaseMerkleCaps [][]string
+	CommitPhaseMerkleCaps [][]types.HashOutRaw
 	QueryRoundProofs      []struct {
 		InitialTreesProof struct {
 			EvalsProofs []types.EvalProofRaw
@@ -71,7 +66,7 @@ func DeserializeFriProof(openingProofRaw struct {
 		Steps []struct {
 			Evals       [][]uint64
 			MerkleProof struct {
-				Siblings []string
+				Siblings []types.HashOutRaw
 			}
 		}
 	}
@@ -86,7 +81,7 @@ func DeserializeFriProof(openingProofRaw struct {
 
 	openingProof.CommitPhaseMerkleCaps = make([]FriMerkleCap, len(openingProofRaw.CommitPhaseMerkleCaps))
 	for i := 0; i < len(openingProofRaw.CommitPhaseMerkleCaps); i++ {
-		openingProof.CommitPhaseMerkleCaps[i] = StringArrayToHashBN254Array(openingProofRaw.CommitPhaseMerkleCaps[i])
+		openingProof.CommitPhaseMerkleCaps[i] = DeserializeMerkleCap(openingProofRaw.CommitPhaseMerkleCaps[i])
 	}
 
 	numQueryRoundProofs := len(openingProofRaw.QueryRoundProofs)
@@ -97,14 +92,14 @@ func DeserializeFriProof(openingProofRaw struct {
 		openingProof.QueryRoundProofs[i].InitialTreesProof.EvalsProofs = make([]FriEvalProof, numEvalProofs)
 		for j := 0; j < numEvalProofs; j++ {
 			openingProof.QueryRoundProofs[i].InitialTreesProof.EvalsProofs[j].Elements = gl.Uint64ArrayToVariableArray(openingProofRaw.QueryRoundProofs[i].InitialTreesProof.EvalsProofs[j].LeafElements)
-			openingProof.QueryRoundProofs[i].InitialTreesProof.EvalsProofs[j].MerkleProof.Siblings = StringArrayToHashBN254Array(openingProofRaw.QueryRoundProofs[i].InitialTreesProof.EvalsProofs[j].MerkleProof.Hash)
+			openingProof.QueryRoundProofs[i].InitialTreesProof.EvalsProofs[j].MerkleProof = DeserializeMerkleProof(openingProofRaw.QueryRoundProofs[i].InitialTreesProof.EvalsProofs[j].MerkleProof)
 		}
 
 		numSteps := len(openingProofRaw.QueryRoundProofs[i].Steps)
 		openingProof.QueryRoundProofs[i].Steps = make([]FriQueryStep, numSteps)
 		for j := 0; j < numSteps; j++ {
 			openingProof.QueryRoundProofs[i].Steps[j].Evals = gl.Uint64ArrayToQuadraticExtensionArray(openingProofRaw.QueryRoundProofs[i].Steps[j].Evals)
-			openingProof.QueryRoundProofs[i].Steps[j].MerkleProof.Siblings = StringArrayToHashBN254Array(openingProofRaw.QueryRoundProofs[i].Steps[j].MerkleProof.Siblings)
+			openingProof.QueryRoundProofs[i].Steps[j].MerkleProof.Siblings = DeserializeHashOuts(openingProofRaw.QueryRoundProofs[i].Steps[j].MerkleProof.Siblings)
 		}
 	}
 
@@ -124,9 +119,11 @@ func DeserializeProofWithPublicInputs(raw types.ProofWithPublicInputsRaw) ProofW
 		PlonkZsNext     [][]uint64
 		PartialProducts [][]uint64
 		QuotientPolys   [][]uint64
+		LookupZs        [][]uint64
+		LookupZsNext    [][]uint64
 	}(raw.Proof.Openings))
 	proofWithPis.Proof.OpeningProof = DeserializeFriProof(struct {
-		CommitPhaseMerkleCaps [][]string
+		CommitPhaseMerkleCaps [][]types.HashOutRaw
 		QueryRoundProofs      []struct {
 			InitialTreesProof struct {
 				EvalsProofs []types.EvalProofRaw
@@ -134,7 +131,7 @@ func DeserializeProofWithPublicInputs(raw types.ProofWithPublicInputsRaw) ProofW
 			Steps []struct {
 				Evals       [][]uint64
 				MerkleProof struct {
-					Siblings []string
+					Siblings []types.HashOutRaw
 				}
 			}
 		}
@@ -149,8 +146,71 @@ func DeserializeProofWithPublicInputs(raw types.ProofWithPublicInputsRaw) ProofW
 func DeserializeVerifierOnlyCircuitData(raw types.VerifierOnlyCircuitDataRaw) VerifierOnlyCircuitData {
 	var verifierOnlyCircuitData VerifierOnlyCircuitData
 	verifierOnlyCircuitData.ConstantSigmasCap = DeserializeMerkleCap(raw.ConstantsSigmasCap)
-	circuitDigestBigInt, _ := new(big.Int).SetString(raw.CircuitDigest, 10)
-	circuitDigestVar := frontend.Variable(circuitDigestBigInt)
-	verifierOnlyCircuitData.CircuitDigest = poseidon.BN254HashOut(circuitDigestVar)
+	verifierOnlyCircuitData.CircuitDigest = DeserializeHashOut(raw.CircuitDigest)
 	return verifierOnlyCircuitData
 }
+
+// Splits a deserialized PoseidonBN254Hash digest into the four 64 bit limbs of its
+// PoseidonBN254EmulatedHash counterpart, least significant first.
+func EmulateBN254HashOut(hash HashOut) HashOut {
+	if len(hash) != 1 {
+		panic(fmt.Sprintf("BN254 digest has %d elements, expected 1", len(hash)))
+	}
+	value, ok := hash[0].(*big.Int)
+	if !ok {
+		panic(fmt.Sprintf("BN254 digest %v is not a deserialized value", hash[0]))
+	}
+
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+	limbs := make(HashOut, types.PoseidonBN254EmulatedHash.HashOutLen())
+	for i := range limbs {
+		limbs[i] = new(big.Int).And(new(big.Int).Rsh(value, uint(64*i)), mask)
+	}
+	return limbs
+}
+
+func emulateBN254HashOuts(hashes []HashOut) []HashOut {
+	emulated := make([]HashOut, len(hashes))
+	for i := range hashes {
+		emulated[i] = EmulateBN254HashOut(hashes[i])
+	}
+	return emulated
+}
+
+// Returns the proof with its digests split by EmulateBN254HashOut, to assign the proof of a
+// PoseidonBN254Hash circuit to a verifier using PoseidonBN254EmulatedHash.
+func EmulateBN254ProofWithPublicInputs(proofWithPis ProofWithPublicInputs) ProofWithPublicInputs {
+	proof := proofWithPis.Proof
+	proof.WiresCap = emulateBN254HashOuts(proof.WiresCap)
+	proof.PlonkZsPartialProductsCap = emulateBN254HashOuts(proof.PlonkZsPartialProductsCap)
+	proof.QuotientPolysCap = emulateBN254HashOuts(proof.QuotientPolysCap)
+
+	openingProof := proof.OpeningProof
+	openingProof.CommitPhaseMerkleCaps = make([]FriMerkleCap, len(proof.OpeningProof.CommitPhaseMerkleCaps))
+	for i, merkleCap := range proof.OpeningProof.CommitPhaseMerkleCaps {
+		openingProof.CommitPhaseMerkleCaps[i] = emulateBN254HashOuts(merkleCap)
+	}
+	openingProof.QueryRoundProofs = make([]FriQueryRound, len(proof.OpeningProof.QueryRoundProofs))
+	for i, queryRound := range proof.OpeningProof.QueryRoundProofs {
+		evalsProofs := make([]FriEvalProof, len(queryRound.InitialTreesProof.EvalsProofs))
+		for j, evalsProof := range queryRound.InitialTreesProof.EvalsProofs {
+			evalsProofs[j] = NewFriEvalProof(evalsProof.Elements, FriMerkleProof{Siblings: emulateBN254HashOuts(evalsProof.MerkleProof.Siblings)})
+		}
+		steps := make([]FriQueryStep, len(queryRound.Steps))
+		for j, step := range queryRound.Steps {
+			steps[j] = FriQueryStep{Evals: step.Evals, MerkleProof: FriMerkleProof{Siblings: emulateBN254HashOuts(step.MerkleProof.Siblings)}}
+		}
+		openingProof.QueryRoundProofs[i] = NewFriQueryRound(steps, NewFriInitialTreeProof(evalsProofs))
+	}
+	proof.OpeningProof = openingProof
+
+	return ProofWithPublicInputs{Proof: proof, PublicInputs: proofWithPis.PublicInputs}
+}
+
+// Returns the verifier data with its digests split by EmulateBN254HashOut.
+func EmulateBN254VerifierOnlyCircuitData(verifierOnlyCircuitData VerifierOnlyCircuitData) VerifierOnlyCircuitData {
+	return VerifierOnlyCircuitData{
+		ConstantSigmasCap: emulateBN254HashOuts(verifierOnlyCircuitData.ConstantSigmasCap),
+		CircuitDigest:     EmulateBN254HashOut(verifierOnlyCircuitData.CircuitDigest),
+	}
+}
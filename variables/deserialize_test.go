@@ -1,6 +1,8 @@
 package variables
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/elliottech/gnark-plonky2-verifier/types"
@@ -15,3 +17,79 @@ func TestDeserializeVerifierOnlyCircuitData(t *testing.T) {
 	verifierOnlyCircuitData := DeserializeVerifierOnlyCircuitData(types.ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json"))
 	t.Logf("%+v\n", verifierOnlyCircuitData)
 }
+
+func TestNewProofWithPublicInputsShape(t *testing.T) {
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	proofWithPis := DeserializeProofWithPublicInputs(types.ReadProofWithPublicInputs("../testdata/decode_block/proof_with_public_inputs.json"))
+	placeholder := NewProofWithPublicInputs(&commonCircuitData)
+
+	assertSameShape(t, "", reflect.ValueOf(proofWithPis), reflect.ValueOf(placeholder))
+}
+
+func TestNewVerifierOnlyCircuitDataShape(t *testing.T) {
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	verifierOnlyCircuitData := DeserializeVerifierOnlyCircuitData(types.ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json"))
+	placeholder := NewVerifierOnlyCircuitData(&commonCircuitData)
+
+	assertSameShape(t, "", reflect.ValueOf(verifierOnlyCircuitData), reflect.ValueOf(placeholder))
+}
+
+func TestNewFriProofHidingLeaves(t *testing.T) {
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	unsalted := NewFriProof(&commonCircuitData)
+
+	commonCircuitData.FriParams.Hiding = true
+	salted := NewFriProof(&commonCircuitData)
+
+	// Only the blinding oracles (all but constants_sigmas) have salted leaves.
+	expectedSaltSizes := []int{0, types.SALT_SIZE, types.SALT_SIZE, types.SALT_SIZE}
+	for i, queryRound := range salted.QueryRoundProofs {
+		for j, evalsProof := range queryRound.InitialTreesProof.EvalsProofs {
+			unsaltedLen := len(unsalted.QueryRoundProofs[i].InitialTreesProof.EvalsProofs[j].Elements)
+			if len(evalsProof.Elements) != unsaltedLen+expectedSaltSizes[j] {
+				t.Fatalf("oracle %d: expected a leaf of %d elements, got %d", j, unsaltedLen+expectedSaltSizes[j], len(evalsProof.Elements))
+			}
+		}
+	}
+}
+
+func TestNewProofLookupPolys(t *testing.T) {
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	withoutLookups := NewProof(&commonCircuitData)
+
+	commonCircuitData.NumLookupPolys = 5
+	withLookups := NewProof(&commonCircuitData)
+
+	numLookupZs := int(commonCircuitData.Config.NumChallenges * commonCircuitData.NumLookupPolys)
+	if len(withLookups.Openings.LookupZs) != numLookupZs || len(withLookups.Openings.LookupZsNext) != numLookupZs {
+		t.Fatalf("expected %d lookup zs openings, got %d and %d", numLookupZs, len(withLookups.Openings.LookupZs), len(withLookups.Openings.LookupZsNext))
+	}
+
+	// The lookup polynomials are committed to in the zs_partial_products oracle only.
+	expectedExtraLeafSizes := []int{0, 0, numLookupZs, 0}
+	for i, queryRound := range withLookups.OpeningProof.QueryRoundProofs {
+		for j, evalsProof := range queryRound.InitialTreesProof.EvalsProofs {
+			baseLen := len(withoutLookups.OpeningProof.QueryRoundProofs[i].InitialTreesProof.EvalsProofs[j].Elements)
+			if len(evalsProof.Elements) != baseLen+expectedExtraLeafSizes[j] {
+				t.Fatalf("oracle %d: expected a leaf of %d elements, got %d", j, baseLen+expectedExtraLeafSizes[j], len(evalsProof.Elements))
+			}
+		}
+	}
+}
+
+// Recursively checks that all the slices within expected and actual have the same lengths.
+func assertSameShape(t *testing.T, path string, expected reflect.Value, actual reflect.Value) {
+	switch expected.Kind() {
+	case reflect.Struct:
+		for i := 0; i < expected.NumField(); i++ {
+			assertSameShape(t, path+"."+expected.Type().Field(i).Name, expected.Field(i), actual.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		if expected.Len() != actual.Len() {
+			t.Fatalf("%s: expected length %d, got %d", path, expected.Len(), actual.Len())
+		}
+		for i := 0; i < expected.Len(); i++ {
+			assertSameShape(t, fmt.Sprintf("%s[%d]", path, i), expected.Index(i), actual.Index(i))
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package variables
 
 import (
+	"github.com/consensys/gnark/frontend"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
-	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
 )
 
 type PolynomialCoeffs struct {
@@ -13,22 +14,39 @@ func NewPolynomialCoeffs(numCoeffs uint64) PolynomialCoeffs {
 	return PolynomialCoeffs{Coeffs: make([]gl.QuadraticExtensionVariable, numCoeffs)}
 }
 
-type FriMerkleCap = []poseidon.BN254HashOut
+// A Merkle tree digest. Its length depends on the hasher of the circuit's config: a single BN254
+// element for PoseidonBN254Hash, four Goldilocks elements for PoseidonGoldilocksHash, 25 bytes
+// (one variable per byte) for KeccakHash and four 64 bit limbs for PoseidonBN254EmulatedHash.
+type HashOut = []frontend.Variable
 
-func NewFriMerkleCap(capHeight uint64) FriMerkleCap {
-	return make([]poseidon.BN254HashOut, 1<<capHeight)
+func NewHashOut(hasher types.HasherType) HashOut {
+	return make(HashOut, hasher.HashOutLen())
+}
+
+type FriMerkleCap = []HashOut
+
+func NewFriMerkleCap(hasher types.HasherType, capHeight uint64) FriMerkleCap {
+	return newHashOuts(hasher, 1<<capHeight)
 }
 
 type FriMerkleProof struct {
-	Siblings []poseidon.BN254HashOut // Length = CircuitConfig.FriConfig.DegreeBits + CircuitConfig.FriConfig.RateBits - CircuitConfig.FriConfig.CapHeight
+	Siblings []HashOut // Length = CircuitConfig.FriConfig.DegreeBits + CircuitConfig.FriConfig.RateBits - CircuitConfig.FriConfig.CapHeight
 }
 
-func NewFriMerkleProof(merkleProofLen uint64) FriMerkleProof {
-	return FriMerkleProof{Siblings: make([]poseidon.BN254HashOut, merkleProofLen)}
+func NewFriMerkleProof(hasher types.HasherType, merkleProofLen uint64) FriMerkleProof {
+	return FriMerkleProof{Siblings: newHashOuts(hasher, merkleProofLen)}
+}
+
+func newHashOuts(hasher types.HasherType, n uint64) []HashOut {
+	hashes := make([]HashOut, n)
+	for i := range hashes {
+		hashes[i] = NewHashOut(hasher)
+	}
+	return hashes
 }
 
 type FriEvalProof struct {
-	Elements    []gl.Variable // Length = [CommonCircuitData.Constants + CommonCircuitData.NumRoutedWires, CommonCircuitData.NumWires + CommonCircuitData.FriParams.Hiding ? 4 : 0, CommonCircuitData.NumChallenges * (1 + CommonCircuitData.NumPartialProducts) + salt, CommonCircuitData.NumChallenges * CommonCircuitData.QuotientDegreeFactor + salt]
+	Elements    []gl.Variable // Length = [CommonCircuitData.Constants + CommonCircuitData.NumRoutedWires, CommonCircuitData.NumWires + salt, CommonCircuitData.NumChallenges * (1 + CommonCircuitData.NumPartialProducts) + salt, CommonCircuitData.NumChallenges * CommonCircuitData.QuotientDegreeFactor + salt] where salt = CommonCircuitData.FriParams.Hiding ? 4 : 0
 	MerkleProof FriMerkleProof
 }
 
@@ -49,10 +67,10 @@ type FriQueryStep struct {
 	MerkleProof FriMerkleProof                  // Length = [regularSize - arityBit for arityBit in CommonCircuitData.FriParams.ReductionArityBits]
 }
 
-func NewFriQueryStep(arityBit uint64, merkleProofLen uint64) FriQueryStep {
+func NewFriQueryStep(hasher types.HasherType, arityBit uint64, merkleProofLen uint64) FriQueryStep {
 	return FriQueryStep{
 		Evals:       make([]gl.QuadraticExtensionVariable, 1<<arityBit),
-		MerkleProof: NewFriMerkleProof(merkleProofLen),
+		MerkleProof: NewFriMerkleProof(hasher, merkleProofLen),
 	}
 }
 
@@ -72,6 +90,62 @@ type FriProof struct {
 	PowWitness            gl.Variable
 }
 
+// Allocates a FriProof whose shape matches the proofs generated for commonCircuitData. All of its
+// variables are left unassigned, so it can be used as the placeholder when compiling a circuit.
+func NewFriProof(commonCircuitData *types.CommonCircuitData) FriProof {
+	friParams := commonCircuitData.FriParams
+
+	// The leaf sizes of the constants_sigmas, wires, zs_partial_products and quotient oracles. All
+	// but the constants_sigmas oracle are blinding, so their leaves are salted in hiding proofs.
+	numChallenges := commonCircuitData.Config.NumChallenges
+	saltSize := friParams.SaltSize(true)
+	oracleLeafSizes := []uint64{
+		commonCircuitData.NumConstants + commonCircuitData.Config.NumRoutedWires,
+		commonCircuitData.Config.NumWires + saltSize,
+		numChallenges*(1+commonCircuitData.NumPartialProducts+commonCircuitData.NumLookupPolys) + saltSize,
+		numChallenges*commonCircuitData.QuotientDegreeFactor + saltSize,
+	}
+	return NewFriProofWithOracles(commonCircuitData.Hasher, &friParams, oracleLeafSizes)
+}
+
+// Allocates a FriProof with friParams opening oracles whose Merkle leaves hold oracleLeafSizes
+// elements, salt included. Like NewFriProof, its variables are left unassigned.
+func NewFriProofWithOracles(hasher types.HasherType, friParams *types.FriParams, oracleLeafSizes []uint64) FriProof {
+	capHeight := friParams.Config.CapHeight
+	ldeBits := uint64(friParams.LdeBits())
+
+	commitPhaseMerkleCaps := make([]FriMerkleCap, len(friParams.ReductionArityBits))
+	for i := range commitPhaseMerkleCaps {
+		commitPhaseMerkleCaps[i] = NewFriMerkleCap(hasher, capHeight)
+	}
+
+	queryRoundProofs := make([]FriQueryRound, friParams.Config.NumQueryRounds)
+	for i := range queryRoundProofs {
+		evalsProofs := make([]FriEvalProof, len(oracleLeafSizes))
+		for j, leafSize := range oracleLeafSizes {
+			evalsProofs[j] = NewFriEvalProof(
+				make([]gl.Variable, leafSize),
+				NewFriMerkleProof(hasher, ldeBits-capHeight),
+			)
+		}
+
+		steps := make([]FriQueryStep, len(friParams.ReductionArityBits))
+		codewordLenBits := ldeBits
+		for j, arityBits := range friParams.ReductionArityBits {
+			codewordLenBits -= arityBits
+			steps[j] = NewFriQueryStep(hasher, arityBits, codewordLenBits-capHeight)
+		}
+
+		queryRoundProofs[i] = NewFriQueryRound(steps, NewFriInitialTreeProof(evalsProofs))
+	}
+
+	return FriProof{
+		CommitPhaseMerkleCaps: commitPhaseMerkleCaps,
+		QueryRoundProofs:      queryRoundProofs,
+		FinalPoly:             NewPolynomialCoeffs(uint64(friParams.FinalPolyLen())),
+	}
+}
+
 type FriChallenges struct {
 	FriAlpha        gl.QuadraticExtensionVariable
 	FriBetas        []gl.QuadraticExtensionVariable
@@ -1,6 +1,9 @@
 package variables
 
-import gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+import (
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+)
 
 type OpeningSet struct {
 	Constants       []gl.QuadraticExtensionVariable // Length = CommonCircuitData.Constants
@@ -10,12 +13,30 @@ type OpeningSet struct {
 	PlonkZsNext     []gl.QuadraticExtensionVariable // Length = CommonCircuitData.NumChallenges
 	PartialProducts []gl.QuadraticExtensionVariable // Length = CommonCircuitData.NumChallenges * CommonCircuitData.NumPartialProducts
 	QuotientPolys   []gl.QuadraticExtensionVariable // Length = CommonCircuitData.NumChallenges * CommonCircuitData.QuotientDegreeFactor
+	LookupZs        []gl.QuadraticExtensionVariable // Length = CommonCircuitData.NumChallenges * CommonCircuitData.NumLookupPolys
+	LookupZsNext    []gl.QuadraticExtensionVariable // Length = CommonCircuitData.NumChallenges * CommonCircuitData.NumLookupPolys
 }
 
 type ProofChallenges struct {
 	PlonkBetas    []gl.Variable
 	PlonkGammas   []gl.Variable
 	PlonkAlphas   []gl.Variable
+	PlonkDeltas   []gl.Variable // Empty for circuits without lookups
 	PlonkZeta     gl.QuadraticExtensionVariable
 	FriChallenges FriChallenges
 }
+
+func NewOpeningSet(commonCircuitData *types.CommonCircuitData) OpeningSet {
+	numChallenges := commonCircuitData.Config.NumChallenges
+	return OpeningSet{
+		Constants:       make([]gl.QuadraticExtensionVariable, commonCircuitData.NumConstants),
+		PlonkSigmas:     make([]gl.QuadraticExtensionVariable, commonCircuitData.Config.NumRoutedWires),
+		Wires:           make([]gl.QuadraticExtensionVariable, commonCircuitData.Config.NumWires),
+		PlonkZs:         make([]gl.QuadraticExtensionVariable, numChallenges),
+		PlonkZsNext:     make([]gl.QuadraticExtensionVariable, numChallenges),
+		PartialProducts: make([]gl.QuadraticExtensionVariable, numChallenges*commonCircuitData.NumPartialProducts),
+		QuotientPolys:   make([]gl.QuadraticExtensionVariable, numChallenges*commonCircuitData.QuotientDegreeFactor),
+		LookupZs:        make([]gl.QuadraticExtensionVariable, numChallenges*commonCircuitData.NumLookupPolys),
+		LookupZsNext:    make([]gl.QuadraticExtensionVariable, numChallenges*commonCircuitData.NumLookupPolys),
+	}
+}
@@ -0,0 +1,129 @@
+package verifier
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// A circuit verifying a batch of plonky2 proofs, each against its own CommonCircuitData, whose
+// only public input is a commitment to the public inputs of all the proofs (see
+// GetPublicInputsCommitmentNative).
+//
+// The proofs share the circuit's Goldilocks range checker and lookup tables, since the gadgets are
+// cached per api. As in VerifierCircuit, the verifier data of each proof is a constant, so the
+// circuit only accepts proofs of the plonky2 circuits it's compiled for.
+type AggregationCircuit struct {
+	PublicInputsCommitment poseidon.GoldilocksHashOut `gnark:",public"`
+	PublicInputs           [][]gl.Variable
+	Proofs                 []variables.Proof
+
+	// This is configuration for the circuit, it is a constant not a variable
+	VerifierOnlyCircuitData []variables.VerifierOnlyCircuitData `gnark:"-"`
+	CommonCircuitData       []types.CommonCircuitData           `gnark:"-"`
+	// Deserializes the gates of every CommonCircuitData, see VerifierCircuit.
+	GateRegistry *gates.GateRegistry `gnark:"-"`
+}
+
+// Creates the placeholder AggregationCircuit to compile, verifying one proof for each plonky2
+// circuit of commonCircuitData and verifierOnlyCircuitData.
+func NewAggregationCircuit(
+	commonCircuitData []types.CommonCircuitData,
+	verifierOnlyCircuitData []variables.VerifierOnlyCircuitData,
+) *AggregationCircuit {
+	if len(commonCircuitData) != len(verifierOnlyCircuitData) {
+		panic("number of common and verifier data mismatch")
+	}
+
+	circuit := &AggregationCircuit{
+		PublicInputs:            make([][]gl.Variable, len(commonCircuitData)),
+		Proofs:                  make([]variables.Proof, len(commonCircuitData)),
+		VerifierOnlyCircuitData: verifierOnlyCircuitData,
+		CommonCircuitData:       commonCircuitData,
+	}
+	for i := range commonCircuitData {
+		circuit.PublicInputs[i] = make([]gl.Variable, commonCircuitData[i].NumPublicInputs)
+		circuit.Proofs[i] = variables.NewProof(&commonCircuitData[i])
+	}
+	return circuit
+}
+
+// Creates an AggregationCircuit witness assignment from deserialized proofs, in the order of the
+// plonky2 circuits the circuit was compiled for.
+func NewAggregationCircuitAssignment(proofsWithPis []types.ProofWithPublicInputsRaw) *AggregationCircuit {
+	circuit := &AggregationCircuit{
+		PublicInputs: make([][]gl.Variable, len(proofsWithPis)),
+		Proofs:       make([]variables.Proof, len(proofsWithPis)),
+	}
+	publicInputs := make([][]uint64, len(proofsWithPis))
+	for i, raw := range proofsWithPis {
+		proofWithPis := variables.DeserializeProofWithPublicInputs(raw)
+		circuit.PublicInputs[i] = proofWithPis.PublicInputs
+		circuit.Proofs[i] = proofWithPis.Proof
+		publicInputs[i] = raw.PublicInputs
+	}
+
+	commitment := GetPublicInputsCommitmentNative(publicInputs)
+	for i := range commitment {
+		circuit.PublicInputsCommitment[i] = gl.NewVariable(commitment[i].Uint64())
+	}
+	return circuit
+}
+
+// Computes the public input of the AggregationCircuit: the Poseidon hash of the concatenated
+// public inputs hashes of the proofs, each being the hash plonky2 uses for its challenges.
+func GetPublicInputsCommitmentNative(publicInputs [][]uint64) poseidon.GoldilocksHashOutNative {
+	hashes := make([]goldilocks.Element, 0, poseidon.POSEIDON_GL_HASH_SIZE*len(publicInputs))
+	for _, proofPublicInputs := range publicInputs {
+		elements := make([]goldilocks.Element, len(proofPublicInputs))
+		for i, input := range proofPublicInputs {
+			elements[i] = goldilocks.NewElement(input)
+		}
+		hash := poseidon.HashNoPadNative(elements)
+		hashes = append(hashes, hash[:]...)
+	}
+	return poseidon.HashNoPadNative(hashes)
+}
+
+// Computes the commitment of GetPublicInputsCommitmentNative in the circuit, from the public inputs
+// hashes of the proofs (see VerifierChip.GetPublicInputsHash).
+func GetPublicInputsCommitment(api frontend.API, publicInputsHashes []poseidon.GoldilocksHashOut) poseidon.GoldilocksHashOut {
+	hashes := make([]gl.Variable, 0, poseidon.POSEIDON_GL_HASH_SIZE*len(publicInputsHashes))
+	for _, hash := range publicInputsHashes {
+		hashes = append(hashes, hash[:]...)
+	}
+	return poseidon.NewGoldilocksChip(api).HashNoPad(hashes)
+}
+
+func (c *AggregationCircuit) Define(api frontend.API) error {
+	numProofs := len(c.CommonCircuitData)
+	if len(c.PublicInputs) != numProofs || len(c.Proofs) != numProofs || len(c.VerifierOnlyCircuitData) != numProofs {
+		return fmt.Errorf("expected %d proofs with their public inputs and verifier data", numProofs)
+	}
+
+	hashes := make([]poseidon.GoldilocksHashOut, numProofs)
+	for i := range c.CommonCircuitData {
+		verifierChip, err := NewVerifierChip(api, c.CommonCircuitData[i], c.GateRegistry)
+		if err != nil {
+			return fmt.Errorf("proof %d: %w", i, err)
+		}
+
+		publicInputsHash := verifierChip.GetPublicInputsHash(c.PublicInputs[i])
+		verifierChip.verify(c.Proofs[i], publicInputsHash, c.VerifierOnlyCircuitData[i])
+		hashes[i] = publicInputsHash
+	}
+
+	glChip := gl.New(api)
+	commitment := GetPublicInputsCommitment(api, hashes)
+	for i := range commitment {
+		glChip.AssertIsEqual(commitment[i], c.PublicInputsCommitment[i])
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package verifier_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+	"github.com/elliottech/gnark-plonky2-verifier/verifier"
+)
+
+type publicInputsCommitmentCircuit struct {
+	PublicInputsCommitment poseidon.GoldilocksHashOut `gnark:",public"`
+	PublicInputs           [][]gl.Variable
+}
+
+func (c *publicInputsCommitmentCircuit) Define(api frontend.API) error {
+	glChip := gl.New(api)
+	poseidonGlChip := poseidon.NewGoldilocksChip(api)
+
+	hashes := make([]poseidon.GoldilocksHashOut, len(c.PublicInputs))
+	for i, publicInputs := range c.PublicInputs {
+		hashes[i] = poseidonGlChip.HashNoPad(publicInputs)
+	}
+
+	commitment := verifier.GetPublicInputsCommitment(api, hashes)
+	for i := range commitment {
+		glChip.AssertIsEqual(commitment[i], c.PublicInputsCommitment[i])
+	}
+	return nil
+}
+
+func TestPublicInputsCommitment(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var proofsWithPis []types.ProofWithPublicInputsRaw
+	for _, plonky2Circuit := range []string{"decode_block", "step", "decode_block"} {
+		proofsWithPis = append(proofsWithPis, types.ReadProofWithPublicInputs("../testdata/"+plonky2Circuit+"/proof_with_public_inputs.json"))
+	}
+	assignment := verifier.NewAggregationCircuitAssignment(proofsWithPis)
+	assert.Len(assignment.Proofs, 3)
+
+	circuit := publicInputsCommitmentCircuit{PublicInputs: make([][]gl.Variable, len(proofsWithPis))}
+	for i := range proofsWithPis {
+		circuit.PublicInputs[i] = make([]gl.Variable, len(proofsWithPis[i].PublicInputs))
+	}
+
+	witness := publicInputsCommitmentCircuit{
+		PublicInputsCommitment: assignment.PublicInputsCommitment,
+		PublicInputs:           assignment.PublicInputs,
+	}
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// The commitment binds the order of the proofs.
+	witness.PublicInputs = [][]gl.Variable{assignment.PublicInputs[1], assignment.PublicInputs[0], assignment.PublicInputs[2]}
+	circuit.PublicInputs[0], circuit.PublicInputs[1] = circuit.PublicInputs[1], circuit.PublicInputs[0]
+	err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+func TestAggregationCircuitShape(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonCircuitData, verifierOnlyCircuitData, _ := readAggregationTestData()
+	circuit := verifier.NewAggregationCircuit(commonCircuitData, verifierOnlyCircuitData)
+	for i := range commonCircuitData {
+		assert.Len(circuit.PublicInputs[i], int(commonCircuitData[i].NumPublicInputs))
+		assert.Len(circuit.Proofs[i].OpeningProof.QueryRoundProofs, int(commonCircuitData[i].FriParams.Config.NumQueryRounds))
+	}
+
+	// Define rejects witnesses that don't match the common circuit data.
+	circuit.Proofs = circuit.Proofs[:1]
+	assert.Error(circuit.Define(nil))
+}
+
+// Reads the decode_block and step plonky2 circuits and their proofs.
+func readAggregationTestData() ([]types.CommonCircuitData, []variables.VerifierOnlyCircuitData, []types.ProofWithPublicInputsRaw) {
+	var commonCircuitData []types.CommonCircuitData
+	var verifierOnlyCircuitData []variables.VerifierOnlyCircuitData
+	var proofsWithPis []types.ProofWithPublicInputsRaw
+	for _, plonky2Circuit := range []string{"decode_block", "step"} {
+		common := types.ReadCommonCircuitData("../testdata/" + plonky2Circuit + "/common_circuit_data.json")
+		// The proofs' transcript predates the observation of the FRI parameters.
+		common.LegacyTranscript = true
+		commonCircuitData = append(commonCircuitData, common)
+		verifierOnlyCircuitData = append(verifierOnlyCircuitData, variables.DeserializeVerifierOnlyCircuitData(types.ReadVerifierOnlyCircuitData("../testdata/"+plonky2Circuit+"/verifier_only_circuit_data.json")))
+		proofsWithPis = append(proofsWithPis, types.ReadProofWithPublicInputs("../testdata/"+plonky2Circuit+"/proof_with_public_inputs.json"))
+	}
+	return commonCircuitData, verifierOnlyCircuitData, proofsWithPis
+}
+
+func TestAggregationCircuit(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonCircuitData, verifierOnlyCircuitData, proofsWithPis := readAggregationTestData()
+	circuit := verifier.NewAggregationCircuit(commonCircuitData, verifierOnlyCircuitData)
+	err := test.IsSolved(circuit, verifier.NewAggregationCircuitAssignment(proofsWithPis), ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// A wrong wire opening of the second proof breaks its vanishing polynomial identity.
+	proofsWithPis[1].Proof.Openings.Wires[0][0]++
+	err = test.IsSolved(circuit, verifier.NewAggregationCircuitAssignment(proofsWithPis), ecc.BN254.ScalarField())
+	assert.Error(err)
+}
@@ -0,0 +1,70 @@
+package verifier
+
+import (
+	"github.com/consensys/gnark/frontend"
+	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
+	"github.com/elliottech/gnark-plonky2-verifier/types"
+	"github.com/elliottech/gnark-plonky2-verifier/variables"
+)
+
+// A verifier circuit accepting proofs of any plonky2 circuit with the same CommonCircuitData shape.
+// The constants and sigmas cap is a witness from which the circuit digest is recomputed, and the
+// digest is a public input after the plonky2 public inputs, so that whoever verifies the Gnark
+// proof (e.g. a contract) decides which plonky2 circuits it accepts.
+type UniversalVerifierCircuit struct {
+	PublicInputs      []gl.Variable     `gnark:",public"`
+	CircuitDigest     variables.HashOut `gnark:",public"`
+	Proof             variables.Proof
+	ConstantSigmasCap variables.FriMerkleCap
+
+	// This is configuration for the circuit, it is a constant not a variable
+	CommonCircuitData types.CommonCircuitData `gnark:"-"`
+	// Deserializes the gates of CommonCircuitData, see VerifierCircuit.
+	GateRegistry *gates.GateRegistry `gnark:"-"`
+}
+
+// Creates the placeholder UniversalVerifierCircuit to compile for commonCircuitData.
+func NewUniversalVerifierCircuit(commonCircuitData types.CommonCircuitData) *UniversalVerifierCircuit {
+	verifierOnlyCircuitData := variables.NewVerifierOnlyCircuitData(&commonCircuitData)
+	return &UniversalVerifierCircuit{
+		PublicInputs:      make([]gl.Variable, commonCircuitData.NumPublicInputs),
+		CircuitDigest:     verifierOnlyCircuitData.CircuitDigest,
+		Proof:             variables.NewProof(&commonCircuitData),
+		ConstantSigmasCap: verifierOnlyCircuitData.ConstantSigmasCap,
+		CommonCircuitData: commonCircuitData,
+	}
+}
+
+// Creates a UniversalVerifierCircuit witness assignment from a deserialized proof and its verifier
+// data.
+func NewUniversalVerifierCircuitAssignment(
+	proofWithPis variables.ProofWithPublicInputs,
+	verifierOnlyCircuitData variables.VerifierOnlyCircuitData,
+) *UniversalVerifierCircuit {
+	return &UniversalVerifierCircuit{
+		PublicInputs:      proofWithPis.PublicInputs,
+		CircuitDigest:     verifierOnlyCircuitData.CircuitDigest,
+		Proof:             proofWithPis.Proof,
+		ConstantSigmasCap: verifierOnlyCircuitData.ConstantSigmasCap,
+	}
+}
+
+func (c *UniversalVerifierCircuit) Define(api frontend.API) error {
+	verifierChip, err := NewVerifierChip(api, c.CommonCircuitData, c.GateRegistry)
+	if err != nil {
+		return err
+	}
+
+	circuitDigest := verifierChip.GetCircuitDigest(c.ConstantSigmasCap)
+	for i := range circuitDigest {
+		api.AssertIsEqual(circuitDigest[i], c.CircuitDigest[i])
+	}
+
+	verifierChip.Verify(c.Proof, c.PublicInputs, variables.VerifierOnlyCircuitData{
+		ConstantSigmasCap: c.ConstantSigmasCap,
+		CircuitDigest:     c.CircuitDigest,
+	})
+
+	return nil
+}
@@ -3,6 +3,7 @@ package verifier
 import (
 	"github.com/consensys/gnark/frontend"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
 	"github.com/elliottech/gnark-plonky2-verifier/types"
 	"github.com/elliottech/gnark-plonky2-verifier/variables"
 )
@@ -14,10 +15,63 @@ type ExampleVerifierCircuit struct {
 
 	// This is configuration for the circuit, it is a constant not a variable
 	CommonCircuitData types.CommonCircuitData
+	GateRegistry      *gates.GateRegistry `gnark:"-"`
 }
 
 func (c *ExampleVerifierCircuit) Define(api frontend.API) error {
-	verifierChip := NewVerifierChip(api, c.CommonCircuitData)
+	verifierChip, err := NewVerifierChip(api, c.CommonCircuitData, c.GateRegistry)
+	if err != nil {
+		return err
+	}
+	verifierChip.Verify(c.Proof, c.PublicInputs, c.VerifierOnlyCircuitData)
+
+	return nil
+}
+
+// A verifier circuit whose proof is a secret witness, so the same compiled circuit (and setup) can
+// be reused to verify any proof of one plonky2 circuit.
+//
+// The plonky2 circuit is bound by VerifierOnlyCircuitData, a constant of the compiled circuit, so
+// a proof of another plonky2 circuit with the same CommonCircuitData isn't accepted. To reuse one
+// compiled circuit across the plonky2 circuits sharing a CommonCircuitData, use
+// UniversalVerifierCircuit, which takes the verifier data as a witness and exposes its circuit
+// digest as public inputs.
+type VerifierCircuit struct {
+	PublicInputs []gl.Variable `gnark:",public"`
+	Proof        variables.Proof
+
+	// This is configuration for the circuit, it is a constant not a variable
+	VerifierOnlyCircuitData variables.VerifierOnlyCircuitData `gnark:"-"`
+	CommonCircuitData       types.CommonCircuitData           `gnark:"-"`
+	// Deserializes the gates of CommonCircuitData, gates.DefaultGateRegistry if nil (see
+	// NewVerifierChip), e.g. to verify proofs of plonky2 circuits with custom gates.
+	GateRegistry *gates.GateRegistry `gnark:"-"`
+}
+
+// Creates the placeholder VerifierCircuit to compile for the plonky2 circuit of
+// verifierOnlyCircuitData and commonCircuitData.
+func NewVerifierCircuit(commonCircuitData types.CommonCircuitData, verifierOnlyCircuitData variables.VerifierOnlyCircuitData) *VerifierCircuit {
+	return &VerifierCircuit{
+		PublicInputs:            make([]gl.Variable, commonCircuitData.NumPublicInputs),
+		Proof:                   variables.NewProof(&commonCircuitData),
+		VerifierOnlyCircuitData: verifierOnlyCircuitData,
+		CommonCircuitData:       commonCircuitData,
+	}
+}
+
+// Creates a VerifierCircuit witness assignment from a deserialized proof.
+func NewVerifierCircuitAssignment(proofWithPis variables.ProofWithPublicInputs) *VerifierCircuit {
+	return &VerifierCircuit{
+		PublicInputs: proofWithPis.PublicInputs,
+		Proof:        proofWithPis.Proof,
+	}
+}
+
+func (c *VerifierCircuit) Define(api frontend.API) error {
+	verifierChip, err := NewVerifierChip(api, c.CommonCircuitData, c.GateRegistry)
+	if err != nil {
+		return err
+	}
 	verifierChip.Verify(c.Proof, c.PublicInputs, c.VerifierOnlyCircuitData)
 
 	return nil
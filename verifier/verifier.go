@@ -5,43 +5,74 @@ import (
 	"github.com/elliottech/gnark-plonky2-verifier/challenger"
 	"github.com/elliottech/gnark-plonky2-verifier/fri"
 	gl "github.com/elliottech/gnark-plonky2-verifier/goldilocks"
+	"github.com/elliottech/gnark-plonky2-verifier/hasher"
 	"github.com/elliottech/gnark-plonky2-verifier/plonk"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
 	"github.com/elliottech/gnark-plonky2-verifier/poseidon"
+	"github.com/elliottech/gnark-plonky2-verifier/profiler"
 	"github.com/elliottech/gnark-plonky2-verifier/types"
 	"github.com/elliottech/gnark-plonky2-verifier/variables"
 )
 
 type VerifierChip struct {
-	api               frontend.API             `gnark:"-"`
-	glChip            *gl.Chip                 `gnark:"-"`
-	poseidonGlChip    *poseidon.GoldilocksChip `gnark:"-"`
-	poseidonBN254Chip *poseidon.BN254Chip      `gnark:"-"`
-	plonkChip         *plonk.PlonkChip         `gnark:"-"`
-	friChip           *fri.Chip                `gnark:"-"`
-	commonData        types.CommonCircuitData  `gnark:"-"`
+	api            frontend.API             `gnark:"-"`
+	glChip         *gl.Chip                 `gnark:"-"`
+	poseidonGlChip *poseidon.GoldilocksChip `gnark:"-"`
+	hasher         hasher.Hasher            `gnark:"-"`
+	plonkChip      *plonk.PlonkChip         `gnark:"-"`
+	friChip        *fri.Chip                `gnark:"-"`
+	commonData     types.CommonCircuitData  `gnark:"-"`
 }
 
-func NewVerifierChip(api frontend.API, commonCircuitData types.CommonCircuitData) *VerifierChip {
+// Creates the chip verifying proofs of commonCircuitData. gateRegistry deserializes its gates, see
+// plonk.NewPlonkChip.
+func NewVerifierChip(api frontend.API, commonCircuitData types.CommonCircuitData, gateRegistry *gates.GateRegistry) (*VerifierChip, error) {
 	glChip := gl.New(api)
 	friChip := fri.NewChip(api, &commonCircuitData, &commonCircuitData.FriParams)
-	plonkChip := plonk.NewPlonkChip(api, commonCircuitData)
+	plonkChip, err := plonk.NewPlonkChip(api, commonCircuitData, gateRegistry)
+	if err != nil {
+		return nil, err
+	}
 	poseidonGlChip := poseidon.NewGoldilocksChip(api)
-	poseidonBN254Chip := poseidon.NewBN254Chip(api)
+	hasher := hasher.New(api, commonCircuitData.Hasher)
 	return &VerifierChip{
-		api:               api,
-		glChip:            glChip,
-		poseidonGlChip:    poseidonGlChip,
-		poseidonBN254Chip: poseidonBN254Chip,
-		plonkChip:         plonkChip,
-		friChip:           friChip,
-		commonData:        commonCircuitData,
-	}
+		api:            api,
+		glChip:         glChip,
+		poseidonGlChip: poseidonGlChip,
+		hasher:         hasher,
+		plonkChip:      plonkChip,
+		friChip:        friChip,
+		commonData:     commonCircuitData,
+	}, nil
 }
 
 func (c *VerifierChip) GetPublicInputsHash(publicInputs []gl.Variable) poseidon.GoldilocksHashOut {
 	return c.poseidonGlChip.HashNoPad(publicInputs)
 }
 
+// Computes the circuit digest of plonky2's CircuitBuilder::build from the constants and sigmas cap:
+// the hash of the flattened cap, the digest of the default (empty) domain separator and the degree
+// bits. Circuits built with a custom domain separator have other digests.
+func (c *VerifierChip) GetCircuitDigest(constantSigmasCap variables.FriMerkleCap) variables.HashOut {
+	var input []gl.Variable
+	for _, hash := range constantSigmasCap {
+		c.hasher.RangeCheck(hash)
+		input = append(input, c.hasher.ToVec(hash)...)
+	}
+
+	// hash_pad of the empty domain separator: a one, zeros and a final one filling the sponge rate.
+	padding := make([]gl.Variable, poseidon.SPONGE_RATE)
+	for i := range padding {
+		padding[i] = gl.Zero()
+	}
+	padding[0] = gl.One()
+	padding[len(padding)-1] = gl.One()
+	input = append(input, c.hasher.ToVec(c.hasher.HashNoPad(padding))...)
+
+	input = append(input, gl.NewVariable(c.commonData.DegreeBits))
+	return c.hasher.HashNoPad(input)
+}
+
 func (c *VerifierChip) GetChallenges(
 	proof variables.Proof,
 	publicInputsHash poseidon.GoldilocksHashOut,
@@ -49,42 +80,45 @@ func (c *VerifierChip) GetChallenges(
 ) variables.ProofChallenges {
 	config := c.commonData.Config
 	numChallenges := config.NumChallenges
-	challenger := challenger.NewChip(c.api)
-
-	challenger.ObserveElement(gl.NewVariable(config.FriConfig.RateBits))
-	challenger.ObserveElement(gl.NewVariable(config.FriConfig.CapHeight))
-	challenger.ObserveElement(gl.NewVariable(config.FriConfig.ProofOfWorkBits))
-	/*
-		Observe FRI reduction strategy.
-		There are 3 types of reduction strategies: Fixed, ConstantArityBits, and MinSize. We only
-		support ConstantArityBits for now. This code must be updated, as well as common circuit data
-		deserialization; should other strategies are used for the wrapper circuit.
-		Prepend F::ONE to arity bits like plonky2, representing ConstantArityBits.
-	*/
-	challenger.ObserveElement(gl.One())
-	for _, bit := range config.FriConfig.ReductionStrategy.ConstantArityBits {
-		challenger.ObserveElement(gl.NewVariable(bit))
-	}
-	challenger.ObserveElement(gl.NewVariable(config.FriConfig.NumQueryRounds))
-
-	if c.friChip.FriParams.Hiding {
-		challenger.ObserveElement(gl.One())
-	} else {
-		challenger.ObserveElement(gl.Zero())
-	}
-	challenger.ObserveElement(gl.NewVariable(c.friChip.FriParams.DegreeBits))
-	for _, bit := range c.friChip.FriParams.ReductionArityBits {
-		challenger.ObserveElement(gl.NewVariable(bit))
+	challenger := challenger.NewChip(c.api, c.hasher)
+
+	if !c.commonData.LegacyTranscript {
+		challenger.ObserveElement(gl.NewVariable(config.FriConfig.RateBits))
+		challenger.ObserveElement(gl.NewVariable(config.FriConfig.CapHeight))
+		challenger.ObserveElement(gl.NewVariable(config.FriConfig.ProofOfWorkBits))
+		challenger.ObserveFriReductionStrategy(config.FriConfig.ReductionStrategy)
+		challenger.ObserveElement(gl.NewVariable(config.FriConfig.NumQueryRounds))
+
+		if c.friChip.FriParams.Hiding {
+			challenger.ObserveElement(gl.One())
+		} else {
+			challenger.ObserveElement(gl.Zero())
+		}
+		challenger.ObserveElement(gl.NewVariable(c.friChip.FriParams.DegreeBits))
+		for _, bit := range c.friChip.FriParams.ReductionArityBits {
+			challenger.ObserveElement(gl.NewVariable(bit))
+		}
 	}
 
 	var circuitDigest = verifierData.CircuitDigest
 
-	challenger.ObserveBN254Hash(circuitDigest)
+	challenger.ObserveDigest(circuitDigest)
 	challenger.ObserveHash(publicInputsHash)
 	challenger.ObserveCap(proof.WiresCap)
 	plonkBetas := challenger.GetNChallenges(numChallenges)
 	plonkGammas := challenger.GetNChallenges(numChallenges)
 
+	// If there are lookups in the circuit, we need delta challenges as well. The already generated
+	// betas and gammas are reused as the first deltas.
+	plonkDeltas := []gl.Variable{}
+	if c.commonData.NumLookupPolys != 0 {
+		numLookupChallenges := plonk.NUM_COINS_LOOKUP * numChallenges
+		numAdditionalChallenges := numLookupChallenges - 2*numChallenges
+		plonkDeltas = append(plonkDeltas, plonkBetas...)
+		plonkDeltas = append(plonkDeltas, plonkGammas...)
+		plonkDeltas = append(plonkDeltas, challenger.GetNChallenges(numAdditionalChallenges)...)
+	}
+
 	challenger.ObserveCap(proof.PlonkZsPartialProductsCap)
 	plonkAlphas := challenger.GetNChallenges(numChallenges)
 
@@ -97,6 +131,7 @@ func (c *VerifierChip) GetChallenges(
 		PlonkBetas:  plonkBetas,
 		PlonkGammas: plonkGammas,
 		PlonkAlphas: plonkAlphas,
+		PlonkDeltas: plonkDeltas,
 		PlonkZeta:   plonkZeta,
 		FriChallenges: challenger.GetFriChallenges(
 			proof.OpeningProof.CommitPhaseMerkleCaps,
@@ -108,7 +143,7 @@ func (c *VerifierChip) GetChallenges(
 }
 
 func (c *VerifierChip) rangeCheckProof(proof variables.Proof) {
-	// Need to verify the plonky2 proof's openings, openings proof (other than the sibling elements), fri's final poly, pow witness.
+	// Need to verify the plonky2 proof's openings, openings proof (including the Merkle siblings, whose check depends on the hasher), fri's final poly, pow witness.
 
 	// Note that this is NOT range checking the public inputs (first 32 elements should be no more than 8 bits and the last 4 elements should be no more than 64 bits).  Since this is currently being inputted via the smart contract,
 	// we will assume that caller is doing that check.
@@ -142,28 +177,16 @@ func (c *VerifierChip) rangeCheckProof(proof variables.Proof) {
 		c.glChip.RangeCheckQE(quotientPoly)
 	}
 
-	// Range check the openings proof.
-	for _, queryRound := range proof.OpeningProof.QueryRoundProofs {
-		for _, evalsProof := range queryRound.InitialTreesProof.EvalsProofs {
-			for _, evalsProofElement := range evalsProof.Elements {
-				c.glChip.RangeCheck(evalsProofElement)
-			}
-		}
-
-		for _, queryStep := range queryRound.Steps {
-			for _, eval := range queryStep.Evals {
-				c.glChip.RangeCheckQE(eval)
-			}
-		}
+	for _, lookupZ := range proof.Openings.LookupZs {
+		c.glChip.RangeCheckQE(lookupZ)
 	}
 
-	// Range check the fri's final poly.
-	for _, coeff := range proof.OpeningProof.FinalPoly.Coeffs {
-		c.glChip.RangeCheckQE(coeff)
+	for _, lookupZNext := range proof.Openings.LookupZsNext {
+		c.glChip.RangeCheckQE(lookupZNext)
 	}
 
-	// Range check the pow witness.
-	c.glChip.RangeCheck(proof.OpeningProof.PowWitness)
+	// Range check the openings proof, fri's final poly and the pow witness.
+	c.friChip.RangeCheckFriProof(&proof.OpeningProof)
 }
 
 func (c *VerifierChip) Verify(
@@ -171,13 +194,31 @@ func (c *VerifierChip) Verify(
 	publicInputs []gl.Variable,
 	verifierData variables.VerifierOnlyCircuitData,
 ) {
-	c.rangeCheckProof(proof)
-
 	// Generate the parts of the witness that is for the plonky2 proof input
+	stop := profiler.Start("public inputs hash")
 	publicInputsHash := c.GetPublicInputsHash(publicInputs)
+	stop()
+
+	c.verify(proof, publicInputsHash, verifierData)
+}
+
+// Verifies proof against the hash of its public inputs, which the aggregation circuit reuses.
+func (c *VerifierChip) verify(
+	proof variables.Proof,
+	publicInputsHash poseidon.GoldilocksHashOut,
+	verifierData variables.VerifierOnlyCircuitData,
+) {
+	stop := profiler.Start("range checks")
+	c.rangeCheckProof(proof)
+	stop()
+
+	stop = profiler.Start("challenger")
 	proofChallenges := c.GetChallenges(proof, publicInputsHash, verifierData)
+	stop()
 
+	stop = profiler.Start("plonk")
 	c.plonkChip.Verify(proofChallenges, proof.Openings, publicInputsHash)
+	stop()
 
 	initialMerkleCaps := []variables.FriMerkleCap{
 		verifierData.ConstantSigmasCap,
@@ -186,6 +227,7 @@ func (c *VerifierChip) Verify(
 		proof.QuotientPolysCap,
 	}
 
+	stop = profiler.Start("fri")
 	c.friChip.VerifyFriProof(
 		c.friChip.GetInstance(proofChallenges.PlonkZeta),
 		c.friChip.ToOpenings(proof.Openings),
@@ -193,4 +235,5 @@ func (c *VerifierChip) Verify(
 		initialMerkleCaps,
 		&proof.OpeningProof,
 	)
+	stop()
 }
@@ -1,10 +1,17 @@
 package verifier_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/test"
+	"github.com/elliottech/gnark-plonky2-verifier/native"
+	"github.com/elliottech/gnark-plonky2-verifier/plonk/gates"
 	"github.com/elliottech/gnark-plonky2-verifier/types"
 	"github.com/elliottech/gnark-plonky2-verifier/variables"
 	"github.com/elliottech/gnark-plonky2-verifier/verifier"
@@ -16,6 +23,8 @@ func TestStepVerifier(t *testing.T) {
 	testCase := func() {
 		plonky2Circuit := "step"
 		commonCircuitData := types.ReadCommonCircuitData("../testdata/" + plonky2Circuit + "/common_circuit_data.json")
+		// The proof's transcript predates the observation of the FRI parameters.
+		commonCircuitData.LegacyTranscript = true
 
 		proofWithPis := variables.DeserializeProofWithPublicInputs(types.ReadProofWithPublicInputs("../testdata/" + plonky2Circuit + "/proof_with_public_inputs.json"))
 		verifierOnlyCircuitData := variables.DeserializeVerifierOnlyCircuitData(types.ReadVerifierOnlyCircuitData("../testdata/" + plonky2Circuit + "/verifier_only_circuit_data.json"))
@@ -39,3 +48,85 @@ func TestStepVerifier(t *testing.T) {
 	}
 	testCase()
 }
+
+// The circuits deserialize the gates of the common circuit data with their GateRegistry, which
+// lacks decode_block's gates when empty.
+func TestCircuitsGateRegistry(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData("../testdata/decode_block/common_circuit_data.json")
+	verifierOnlyCircuitData := variables.DeserializeVerifierOnlyCircuitData(types.ReadVerifierOnlyCircuitData("../testdata/decode_block/verifier_only_circuit_data.json"))
+
+	registry := &gates.GateRegistry{}
+	circuit := verifier.NewVerifierCircuit(commonCircuitData, verifierOnlyCircuitData)
+	circuit.GateRegistry = registry
+	universalCircuit := verifier.NewUniversalVerifierCircuit(commonCircuitData)
+	universalCircuit.GateRegistry = registry
+	aggregationCircuit := verifier.NewAggregationCircuit(
+		[]types.CommonCircuitData{commonCircuitData},
+		[]variables.VerifierOnlyCircuitData{verifierOnlyCircuitData},
+	)
+	aggregationCircuit.GateRegistry = registry
+
+	for _, circuit := range []frontend.Circuit{circuit, universalCircuit, aggregationCircuit} {
+		_, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+		assert.ErrorContains(err, "unsupported gates")
+	}
+}
+
+// Verifies the plonky2 proof of testdata/<plonky2Circuit>, generated by plonky2 with the current
+// transcript for a config using hasherType, natively and in circuit. Skips the test if the proof
+// isn't committed, and returns its common circuit data otherwise.
+func verifyPlonky2Proof(t *testing.T, plonky2Circuit string, hasherType types.HasherType) types.CommonCircuitData {
+	dir := filepath.Join("../testdata", plonky2Circuit)
+	if _, err := os.Stat(filepath.Join(dir, "proof_with_public_inputs.json")); errors.Is(err, os.ErrNotExist) {
+		t.Skipf("no plonky2 proof in %s", dir)
+	}
+	assert := test.NewAssert(t)
+
+	commonCircuitData := types.ReadCommonCircuitData(filepath.Join(dir, "common_circuit_data.json"))
+	commonCircuitData.Hasher = hasherType
+	proofWithPisRaw := types.ReadProofWithPublicInputs(filepath.Join(dir, "proof_with_public_inputs.json"))
+	verifierOnlyRaw := types.ReadVerifierOnlyCircuitData(filepath.Join(dir, "verifier_only_circuit_data.json"))
+	assert.NoError(native.Verify(proofWithPisRaw, verifierOnlyRaw, commonCircuitData))
+
+	circuit := verifier.NewVerifierCircuit(commonCircuitData, variables.DeserializeVerifierOnlyCircuitData(verifierOnlyRaw))
+	assignment := verifier.NewVerifierCircuitAssignment(variables.DeserializeProofWithPublicInputs(proofWithPisRaw))
+	assert.NoError(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// A wrong wire opening breaks the vanishing polynomial identity.
+	proofWithPisRaw.Proof.Openings.Wires[0][0]++
+	assert.Error(native.Verify(proofWithPisRaw, verifierOnlyRaw, commonCircuitData))
+	assignment = verifier.NewVerifierCircuitAssignment(variables.DeserializeProofWithPublicInputs(proofWithPisRaw))
+	assert.Error(test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	return commonCircuitData
+}
+
+// A proof of a plonky2 circuit with PoseidonGoldilocksConfig, e.g. plonky2's fibonacci example.
+func TestPoseidonGoldilocksVerifier(t *testing.T) {
+	verifyPlonky2Proof(t, "poseidon_goldilocks", types.PoseidonGoldilocksHash)
+}
+
+// A proof of a plonky2 circuit with KeccakGoldilocksConfig.
+func TestKeccakVerifier(t *testing.T) {
+	verifyPlonky2Proof(t, "keccak", types.KeccakHash)
+}
+
+// A proof of a plonky2 circuit with PoseidonGoldilocksConfig and zero_knowledge set, whose FRI
+// openings are salted.
+func TestZeroKnowledgeVerifier(t *testing.T) {
+	commonCircuitData := verifyPlonky2Proof(t, "zero_knowledge", types.PoseidonGoldilocksHash)
+	if !commonCircuitData.Config.ZeroKnowledge {
+		t.Fatal("expected a proof with zero knowledge")
+	}
+}
+
+// A proof of a plonky2 circuit with PoseidonGoldilocksConfig and lookup tables, built with
+// add_lookup_table.
+func TestLookupVerifier(t *testing.T) {
+	commonCircuitData := verifyPlonky2Proof(t, "lookup", types.PoseidonGoldilocksHash)
+	if len(commonCircuitData.Luts) == 0 {
+		t.Fatal("expected a proof with lookup tables")
+	}
+}